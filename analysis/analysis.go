@@ -0,0 +1,94 @@
+// Package analysis はマップの構造（関節点・行き止まり・アイテムの集まり・距離）を解析する
+//
+// 解析は chasermap.Map（マップファイルから読み込んだ盤面）に対して行う。
+// ブロック以外のマスを上下左右の隣接でつないだグラフとして扱い、盤面外は壁とみなす。
+// 対戦前のマップ研究や、ボットが事前に行動計画を立てる用途を想定している。
+package analysis
+
+import (
+	"github.com/kqnade/CHaserGo/chasermap"
+)
+
+// ClusterRadius は同じ集まりとみなすアイテム同士の最大距離（歩数）
+const ClusterRadius = 2
+
+// Analysis は1つのマップの解析結果
+type Analysis struct {
+	Width    int `json:"width"`
+	Height   int `json:"height"`
+	Walkable int `json:"walkable"` // ブロック以外のマスの数
+	Items    int `json:"items"`
+	// StartDistance は Hot と Cool の間の最短距離（到達できない場合は -1）
+	StartDistance int `json:"startDistance"`
+
+	Chokepoints  []Chokepoint `json:"chokepoints"`
+	DeadEnds     []DeadEnd    `json:"deadEnds"`
+	ItemClusters []Cluster    `json:"itemClusters"`
+
+	// HotDistance, CoolDistance は各スタート位置からの最短距離（[y][x]、到達できないマスは -1）
+	HotDistance  [][]int `json:"hotDistance"`
+	CoolDistance [][]int `json:"coolDistance"`
+}
+
+// Chokepoint は盤面の関節点（そのマスがブロックになると通れるマスが分断されるマス）
+type Chokepoint struct {
+	chasermap.Position
+	// Separated は分断後に最も大きい領域以外に取り残されるマスの数
+	Separated int `json:"separated"`
+	// SeparatedItems はそのうちアイテムのあるマスの数
+	SeparatedItems int `json:"separatedItems"`
+}
+
+// DeadEnd は行き止まりの通路（幅1で、奥から入口まで一本道のマスの並び）
+//
+// 中にいるプレイヤーは Mouth（入口側の端）にブロックを置かれると閉じ込められる。
+type DeadEnd struct {
+	Tip   chasermap.Position `json:"tip"`   // 行き止まりの奥
+	Mouth chasermap.Position `json:"mouth"` // 分岐に接する入口側の端
+	// Cells は Tip から Mouth までの通路のマス
+	Cells []chasermap.Position `json:"cells"`
+	Items int                  `json:"items"`
+}
+
+// Cluster は互いに ClusterRadius 歩以内でつながるアイテムの集まり
+type Cluster struct {
+	Items []chasermap.Position `json:"items"`
+	// Center は集まりの他のアイテムへの距離の合計が最小のアイテム
+	Center chasermap.Position `json:"center"`
+	// HotDistance, CoolDistance は各スタート位置から最も近いアイテムまでの距離（到達できない場合は -1）
+	HotDistance  int `json:"hotDistance"`
+	CoolDistance int `json:"coolDistance"`
+}
+
+// Analyze は m を解析する。m は chasermap.Map.Validate を満たしている必要がある
+func Analyze(m *chasermap.Map) *Analysis {
+	g := newGrid(m)
+	a := &Analysis{
+		Width:        m.Width,
+		Height:       m.Height,
+		HotDistance:  Distances(m, m.Hot),
+		CoolDistance: Distances(m, m.Cool),
+	}
+	a.StartDistance = a.HotDistance[m.Cool.Y][m.Cool.X]
+	for y := 0; y < m.Height; y++ {
+		for x := 0; x < m.Width; x++ {
+			switch m.Cells[y][x] {
+			case chasermap.Block:
+				continue
+			case chasermap.Item:
+				a.Items++
+			}
+			a.Walkable++
+		}
+	}
+
+	a.Chokepoints = g.chokepoints()
+	a.DeadEnds = g.deadEnds()
+	a.ItemClusters = g.clusters(a.HotDistance, a.CoolDistance)
+	return a
+}
+
+// Distances は from からブロック以外のマスを通る最短距離を返す（[y][x]、到達不可は -1）
+func Distances(m *chasermap.Map, from chasermap.Position) [][]int {
+	return newGrid(m).distances(from)
+}
@@ -0,0 +1,111 @@
+package analysis
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kqnade/CHaserGo/chasermap"
+)
+
+// parseMap は ASCII アート書式のマップを読み込む
+func parseMap(t *testing.T, rows ...string) *chasermap.Map {
+	t.Helper()
+	m, err := chasermap.Parse(strings.NewReader("turns: 10\n" + strings.Join(rows, "\n")))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	return m
+}
+
+func pos(x, y int) chasermap.Position {
+	return chasermap.Position{X: x, Y: y}
+}
+
+// 左の部屋と右の部屋が (3,1) の1マスでつながり、左の部屋の下に行き止まりがある
+//
+//	#######
+//	#H.*.C#
+//	##.####
+//	##*####
+var testRows = []string{
+	"#######",
+	"#H.*.C#",
+	"##.####",
+	"##*####",
+}
+
+func TestAnalyze(t *testing.T) {
+	a := Analyze(parseMap(t, testRows...))
+
+	if a.Walkable != 7 || a.Items != 2 || a.StartDistance != 4 {
+		t.Errorf("walkable %d items %d start distance %d, want 7, 2, 4", a.Walkable, a.Items, a.StartDistance)
+	}
+	if a.HotDistance[3][2] != 3 || a.CoolDistance[1][1] != 4 || a.HotDistance[0][0] != -1 {
+		t.Errorf("distance fields wrong: hot %v cool %v", a.HotDistance, a.CoolDistance)
+	}
+
+	want := []Chokepoint{
+		{Position: pos(2, 1), Separated: 3, SeparatedItems: 1}, // 右側の3マスが残り、H と下の通路が切り離される
+		{Position: pos(3, 1), Separated: 2, SeparatedItems: 0},
+		{Position: pos(2, 2), Separated: 1, SeparatedItems: 1},
+		{Position: pos(4, 1), Separated: 1, SeparatedItems: 0},
+	}
+	if len(a.Chokepoints) != len(want) {
+		t.Fatalf("chokepoints = %+v, want %+v", a.Chokepoints, want)
+	}
+	for i, w := range want {
+		if a.Chokepoints[i] != w {
+			t.Errorf("chokepoints[%d] = %+v, want %+v", i, a.Chokepoints[i], w)
+		}
+	}
+}
+
+func TestDeadEnds(t *testing.T) {
+	a := Analyze(parseMap(t, testRows...))
+	// 行き止まりは H のマス、C のマス、下の通路の3本
+	if len(a.DeadEnds) != 3 {
+		t.Fatalf("dead ends = %+v, want 3", a.DeadEnds)
+	}
+	var corridor DeadEnd
+	for _, d := range a.DeadEnds {
+		if d.Tip == pos(2, 3) {
+			corridor = d
+		}
+	}
+	if corridor.Mouth != pos(2, 2) || len(corridor.Cells) != 2 || corridor.Items != 1 {
+		t.Errorf("corridor = %+v, want tip (2,3) mouth (2,2) with 1 item", corridor)
+	}
+
+	// 盤面全体が一本道の場合は1本として数える
+	line := Analyze(parseMap(t, "H.*.C"))
+	if len(line.DeadEnds) != 1 || len(line.DeadEnds[0].Cells) != 5 || line.DeadEnds[0].Items != 1 {
+		t.Errorf("line dead ends = %+v, want one corridor of 5 cells", line.DeadEnds)
+	}
+
+	// 閉路には行き止まりがない
+	loop := Analyze(parseMap(t, "H.", "C."))
+	if len(loop.DeadEnds) != 0 || len(loop.Chokepoints) != 0 {
+		t.Errorf("loop: dead ends %+v chokepoints %+v, want none", loop.DeadEnds, loop.Chokepoints)
+	}
+}
+
+func TestItemClusters(t *testing.T) {
+	a := Analyze(parseMap(t,
+		"H**.#...",
+		"..*.#..*",
+		"........",
+		"*......C",
+	))
+	if len(a.ItemClusters) != 3 {
+		t.Fatalf("clusters = %+v, want 3", a.ItemClusters)
+	}
+	big := a.ItemClusters[0]
+	if len(big.Items) != 3 || big.Center != pos(2, 0) || big.HotDistance != 1 || big.CoolDistance != 7 {
+		t.Errorf("largest cluster = %+v, want 3 items around (2,0), hot 1, cool 7", big)
+	}
+	for _, c := range a.ItemClusters[1:] {
+		if len(c.Items) != 1 || c.Center != c.Items[0] {
+			t.Errorf("cluster = %+v, want a single item", c)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package analysis
+
+import (
+	"sort"
+
+	"github.com/kqnade/CHaserGo/chasermap"
+)
+
+// clusters はアイテムを ClusterRadius 歩以内のつながりでまとめ、アイテムの多い順に返す
+func (g grid) clusters(hotDist, coolDist [][]int) []Cluster {
+	var items []chasermap.Position
+	for y := 0; y < g.m.Height; y++ {
+		for x := 0; x < g.m.Width; x++ {
+			if p := (chasermap.Position{X: x, Y: y}); g.item(p) {
+				items = append(items, p)
+			}
+		}
+	}
+
+	// 各アイテムからの距離を求め、近いもの同士を union-find でまとめる
+	dists := make([][][]int, len(items))
+	parent := make([]int, len(items))
+	var find func(i int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+	for i, p := range items {
+		parent[i] = i
+		dists[i] = g.distances(p)
+	}
+	for i := range items {
+		for j := i + 1; j < len(items); j++ {
+			if d := dists[i][items[j].Y][items[j].X]; d >= 0 && d <= ClusterRadius {
+				parent[find(j)] = find(i)
+			}
+		}
+	}
+
+	groups := make(map[int][]int)
+	var roots []int
+	for i := range items {
+		r := find(i)
+		if _, ok := groups[r]; !ok {
+			roots = append(roots, r)
+		}
+		groups[r] = append(groups[r], i)
+	}
+
+	result := make([]Cluster, 0, len(roots))
+	for _, r := range roots {
+		members := groups[r]
+		c := Cluster{HotDistance: -1, CoolDistance: -1}
+		bestSum := -1
+		for _, i := range members {
+			p := items[i]
+			c.Items = append(c.Items, p)
+			c.HotDistance = nearer(c.HotDistance, hotDist[p.Y][p.X])
+			c.CoolDistance = nearer(c.CoolDistance, coolDist[p.Y][p.X])
+
+			sum := 0
+			for _, j := range members {
+				sum += dists[i][items[j].Y][items[j].X]
+			}
+			if bestSum < 0 || sum < bestSum {
+				bestSum, c.Center = sum, p
+			}
+		}
+		result = append(result, c)
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return len(result[i].Items) > len(result[j].Items)
+	})
+	return result
+}
+
+// nearer は距離 a と b のうち近い方を返す（-1 は到達不可として扱う）
+func nearer(a, b int) int {
+	if a < 0 || (b >= 0 && b < a) {
+		return b
+	}
+	return a
+}
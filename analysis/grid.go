@@ -0,0 +1,221 @@
+package analysis
+
+import (
+	"sort"
+
+	"github.com/kqnade/CHaserGo/chasermap"
+)
+
+var neighborOffsets = []chasermap.Position{{X: 0, Y: -1}, {X: 0, Y: 1}, {X: -1, Y: 0}, {X: 1, Y: 0}}
+
+// grid はマップをブロック以外のマスのグラフとして扱うためのヘルパー
+type grid struct {
+	m *chasermap.Map
+}
+
+func newGrid(m *chasermap.Map) grid {
+	return grid{m: m}
+}
+
+// open は p が盤面内でブロックでないかを返す
+func (g grid) open(p chasermap.Position) bool {
+	return g.m.InBounds(p) && g.m.Cells[p.Y][p.X] != chasermap.Block
+}
+
+func (g grid) item(p chasermap.Position) bool {
+	return g.m.Cells[p.Y][p.X] == chasermap.Item
+}
+
+// neighbors は p の上下左右のうち通れるマスを返す
+func (g grid) neighbors(p chasermap.Position) []chasermap.Position {
+	ns := make([]chasermap.Position, 0, 4)
+	for _, d := range neighborOffsets {
+		n := chasermap.Position{X: p.X + d.X, Y: p.Y + d.Y}
+		if g.open(n) {
+			ns = append(ns, n)
+		}
+	}
+	return ns
+}
+
+// distances は from からの最短距離を返す（到達不可は -1）
+func (g grid) distances(from chasermap.Position) [][]int {
+	dist := make([][]int, g.m.Height)
+	for y := range dist {
+		dist[y] = make([]int, g.m.Width)
+		for x := range dist[y] {
+			dist[y][x] = -1
+		}
+	}
+	if !g.open(from) {
+		return dist
+	}
+
+	dist[from.Y][from.X] = 0
+	queue := []chasermap.Position{from}
+	for len(queue) > 0 {
+		p := queue[0]
+		queue = queue[1:]
+		for _, n := range g.neighbors(p) {
+			if dist[n.Y][n.X] < 0 {
+				dist[n.Y][n.X] = dist[p.Y][p.X] + 1
+				queue = append(queue, n)
+			}
+		}
+	}
+	return dist
+}
+
+// chokepoints は関節点を Tarjan の方法で求め、取り残されるマス・アイテムの多い順に返す
+func (g grid) chokepoints() []Chokepoint {
+	w, h := g.m.Width, g.m.Height
+	index := func(p chasermap.Position) int { return p.Y*w + p.X }
+	disc := make([]int, w*h) // 訪問順（1始まり、0 は未訪問）
+	low := make([]int, w*h)
+	size := make([]int, w*h)  // DFS 木の部分木のマス数
+	items := make([]int, w*h) // 部分木のアイテム数
+	counter := 0
+
+	// part は関節点で分断された1つの領域
+	type part struct{ cells, items int }
+	type candidate struct {
+		p     chasermap.Position
+		parts []part // p を除くと切り離される子の部分木
+	}
+	var cands []candidate
+
+	var visit func(p chasermap.Position, root bool)
+	visit = func(p chasermap.Position, root bool) {
+		i := index(p)
+		counter++
+		disc[i], low[i] = counter, counter
+		size[i] = 1
+		if g.item(p) {
+			items[i] = 1
+		}
+
+		var parts []part
+		for _, n := range g.neighbors(p) {
+			j := index(n)
+			if disc[j] != 0 {
+				low[i] = min(low[i], disc[j])
+				continue
+			}
+			visit(n, false)
+			low[i] = min(low[i], low[j])
+			size[i] += size[j]
+			items[i] += items[j]
+			if low[j] >= disc[i] {
+				parts = append(parts, part{cells: size[j], items: items[j]})
+			}
+		}
+		// 根は子が2つ以上、それ以外は切り離される子が1つ以上あれば関節点
+		if (root && len(parts) >= 2) || (!root && len(parts) > 0) {
+			cands = append(cands, candidate{p: p, parts: parts})
+		}
+	}
+
+	result := []Chokepoint{}
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			root := chasermap.Position{X: x, Y: y}
+			if !g.open(root) || disc[index(root)] != 0 {
+				continue
+			}
+			cands = cands[:0]
+			visit(root, true)
+
+			// 連結成分全体の大きさが分かってから、切り離されない残りの領域を加えて評価する
+			compCells, compItems := size[index(root)], items[index(root)]
+			for _, c := range cands {
+				restCells, restItems := compCells-1, compItems
+				if g.item(c.p) {
+					restItems--
+				}
+				largest := part{}
+				for _, pt := range c.parts {
+					restCells -= pt.cells
+					restItems -= pt.items
+					if pt.cells > largest.cells {
+						largest = pt
+					}
+				}
+				if restCells > largest.cells {
+					largest = part{cells: restCells, items: restItems}
+				}
+				cp := Chokepoint{Position: c.p, Separated: compCells - 1 - largest.cells}
+				cp.SeparatedItems = compItems - largest.items
+				if g.item(c.p) {
+					cp.SeparatedItems--
+				}
+				result = append(result, cp)
+			}
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		a, b := result[i], result[j]
+		if a.Separated != b.Separated {
+			return a.Separated > b.Separated
+		}
+		if a.SeparatedItems != b.SeparatedItems {
+			return a.SeparatedItems > b.SeparatedItems
+		}
+		return less(a.Position, b.Position)
+	})
+	return result
+}
+
+// deadEnds は行き止まりの通路を返す
+//
+// 通れる隣接マスが1つのマスを奥とし、隣接マスが2つのマスをたどって
+// 分岐（隣接マスが3つ以上）の手前までを1本の通路とする。両端とも行き止まりの
+// 一本道（連結成分全体が通路）は、奥の位置が小さい側から1度だけ数える。
+func (g grid) deadEnds() []DeadEnd {
+	result := []DeadEnd{}
+	for y := 0; y < g.m.Height; y++ {
+		for x := 0; x < g.m.Width; x++ {
+			tip := chasermap.Position{X: x, Y: y}
+			if !g.open(tip) || len(g.neighbors(tip)) != 1 {
+				continue
+			}
+
+			d := DeadEnd{Tip: tip, Cells: []chasermap.Position{tip}}
+			prev, cur := tip, g.neighbors(tip)[0]
+			closed := false // 反対側も行き止まりの一本道
+			for {
+				ns := g.neighbors(cur)
+				if len(ns) >= 3 {
+					break
+				}
+				d.Cells = append(d.Cells, cur)
+				if len(ns) == 1 {
+					closed = true
+					break
+				}
+				next := ns[0]
+				if next == prev {
+					next = ns[1]
+				}
+				prev, cur = cur, next
+			}
+			if closed && less(d.Cells[len(d.Cells)-1], tip) {
+				continue
+			}
+
+			d.Mouth = d.Cells[len(d.Cells)-1]
+			for _, c := range d.Cells {
+				if g.item(c) {
+					d.Items++
+				}
+			}
+			result = append(result, d)
+		}
+	}
+	return result
+}
+
+// less は位置を行優先で比較する
+func less(a, b chasermap.Position) bool {
+	return a.Y < b.Y || (a.Y == b.Y && a.X < b.X)
+}
@@ -0,0 +1,129 @@
+package arena
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/kqnade/CHaserGo/chasermap"
+)
+
+// parseMap は ASCII アート書式のマップを読み込む
+func parseMap(t *testing.T, turns string, rows ...string) *chasermap.Map {
+	t.Helper()
+	m, err := chasermap.Parse(strings.NewReader("turns: " + turns + "\n" + strings.Join(rows, "\n")))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	return m
+}
+
+// script は決められたコマンドを順に返し、尽きたら最後のコマンドを繰り返すボット
+type script struct {
+	commands []string
+	readies  [][10]int
+}
+
+func (s *script) Act(ready [10]int) string {
+	s.readies = append(s.readies, ready)
+	cmd := s.commands[0]
+	if len(s.commands) > 1 {
+		s.commands = s.commands[1:]
+	}
+	return cmd
+}
+
+func TestPlayItemsAndTurns(t *testing.T) {
+	m := parseMap(t, "4",
+		"#####",
+		"#H**#",
+		"#...#",
+		"#C..#",
+		"#####",
+	)
+	hot := &script{commands: []string{"wr", "wr", "wl", "wl"}}
+	cool := &script{commands: []string{"sr"}}
+	r := Play(m, hot, cool)
+
+	if r.Turns != 4 || r.MaxTurns != 4 || r.Died {
+		t.Errorf("Turns=%d MaxTurns=%d Died=%v, want 4 4 false", r.Turns, r.MaxTurns, r.Died)
+	}
+	if r.HotItems != 2 || r.CoolItems != 0 || r.Winner != "hot" {
+		t.Errorf("items %d-%d winner %q, want 2-0 hot", r.HotItems, r.CoolItems, r.Winner)
+	}
+	// マップは変更しない
+	if m.Cells[1][2] != chasermap.Item {
+		t.Error("Play modified the map")
+	}
+	// 偶数ターンは Hot、奇数ターンは Cool が先攻で、1ターンに1回ずつ行動する
+	if len(hot.readies) != 4 || len(cool.readies) != 4 {
+		t.Errorf("hot acted %d times, cool %d times; want 4 each", len(hot.readies), len(cool.readies))
+	}
+	// 周辺9マス: 上が壁、右がアイテム
+	if got := hot.readies[0]; got[0] != 1 || got[2] != 2 || got[6] != 3 || got[5] != 0 {
+		t.Errorf("first ready = %v", got)
+	}
+}
+
+func TestPlayWallDeath(t *testing.T) {
+	m := parseMap(t, "10",
+		"#####",
+		"#H..#",
+		"#...#",
+		"#..C#",
+		"#####",
+	)
+	r := Play(m, &script{commands: []string{"wu"}}, &script{commands: []string{"wl"}})
+	if !r.Died || r.Winner != "cool" || r.Turns != 0 {
+		t.Errorf("got %+v, want cool to win at turn 0 after hot hits a wall", r)
+	}
+
+	r = Play(m, &script{commands: []string{"sr"}}, &script{commands: []string{"xx"}})
+	if !r.Died || r.Winner != "hot" {
+		t.Errorf("got %+v, want hot to win after cool sends an invalid command", r)
+	}
+}
+
+func TestBotsAreReproducible(t *testing.T) {
+	m := parseMap(t, "60",
+		"###########",
+		"#H..*..*..#",
+		"#.#.#.#.#.#",
+		"#*..*..*.*#",
+		"#.#.#.#.#.#",
+		"#..*..*..C#",
+		"###########",
+	)
+	for _, a := range Strategies {
+		for _, b := range Strategies {
+			play := func() Result {
+				rng := rand.New(rand.NewSource(7))
+				return Play(m, NewBot(a, rng), NewBot(b, rng))
+			}
+			if r1, r2 := play(), play(); r1 != r2 {
+				t.Errorf("%s vs %s: %+v != %+v", a, b, r1, r2)
+			}
+		}
+	}
+}
+
+func TestGreedyTakesItems(t *testing.T) {
+	ready := [10]int{1, 0, 0, 0, 3, 0, 0, 0, 0, 0}
+	if got := NewBot(StrategyGreedy, rand.New(rand.NewSource(1))).Act(ready); got != "wl" {
+		t.Errorf("greedy = %q, want wl", got)
+	}
+	ready[6] = 1
+	if got := NewBot(StrategyHunter, rand.New(rand.NewSource(1))).Act(ready); got != "pr" {
+		t.Errorf("hunter = %q, want pr", got)
+	}
+}
+
+func TestParseStrategies(t *testing.T) {
+	list, err := ParseStrategies("random, hunter")
+	if err != nil || len(list) != 2 || list[0] != StrategyRandom || list[1] != StrategyHunter {
+		t.Errorf("ParseStrategies = %v, %v", list, err)
+	}
+	if _, err := ParseStrategies("random,smart"); err == nil {
+		t.Error("ParseStrategies(\"random,smart\") expected error")
+	}
+}
@@ -0,0 +1,159 @@
+package arena
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+)
+
+// Bot は対戦するボット
+//
+// Act は getReady の応答（周辺9マス。server.BuildWalkResponse と同じ並び）を受け取り、
+// 行動コマンド（"wu", "pl" など）を返す。行動に対する応答は受け取らない。
+type Bot interface {
+	Act(ready [10]int) string
+}
+
+// 応答の値（CHaser プロトコル）
+const (
+	cellEmpty    = 0
+	cellOpponent = 1
+	cellWall     = 2
+	cellItem     = 3
+)
+
+// 上下左右の方向文字と、応答で対応するマスの添字
+var (
+	directions = []byte{'u', 'd', 'l', 'r'}
+	neighbor   = map[byte]int{'u': 2, 'd': 8, 'l': 4, 'r': 6}
+)
+
+// Strategy は組み込みの参考ボットの名前
+type Strategy string
+
+const (
+	// StrategyRandom は壁でない方向へランダムに歩く
+	StrategyRandom Strategy = "random"
+	// StrategyWallFollower は下→右→上→左の順に、壁に当たるまで同じ方向へ歩く（examples/test2 と同じ動き）
+	StrategyWallFollower Strategy = "wall-follower"
+	// StrategyGreedy は隣のアイテムを優先して取り、なければ今の方向へ歩き続ける
+	StrategyGreedy Strategy = "greedy"
+	// StrategyHunter は greedy に加え、相手が上下左右に隣接したらブロックを置いて閉じ込めを狙う（examples/test3 と同じ考え方）
+	StrategyHunter Strategy = "hunter"
+)
+
+// Strategies は組み込みの参考ボットの一覧
+var Strategies = []Strategy{StrategyRandom, StrategyWallFollower, StrategyGreedy, StrategyHunter}
+
+// ParseStrategy は文字列から Strategy を得る
+func ParseStrategy(s string) (Strategy, error) {
+	for _, st := range Strategies {
+		if string(st) == s {
+			return st, nil
+		}
+	}
+	return "", fmt.Errorf("unknown bot %q (want one of %s)", s, strategyNames())
+}
+
+// ParseStrategies はカンマ区切りのボット名の一覧を解釈する
+func ParseStrategies(s string) ([]Strategy, error) {
+	var list []Strategy
+	for _, name := range strings.Split(s, ",") {
+		st, err := ParseStrategy(strings.TrimSpace(name))
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, st)
+	}
+	return list, nil
+}
+
+func strategyNames() string {
+	names := make([]string, len(Strategies))
+	for i, st := range Strategies {
+		names[i] = string(st)
+	}
+	return strings.Join(names, ", ")
+}
+
+// NewBot は st のボットを作成する。乱数は rng から得るため、同じ rng の状態からは同じ動きをする
+func NewBot(st Strategy, rng *rand.Rand) Bot {
+	switch st {
+	case StrategyWallFollower:
+		return &wallFollower{}
+	case StrategyGreedy:
+		return &greedy{rng: rng}
+	case StrategyHunter:
+		return &greedy{rng: rng, hunt: true}
+	default:
+		return &randomWalker{rng: rng}
+	}
+}
+
+// open は ready で dir の隣が歩けるマス（壁でない）かを返す
+func open(ready [10]int, dir byte) bool {
+	return ready[neighbor[dir]] != cellWall
+}
+
+// randomDirection は歩ける方向からランダムに選ぶ（なければ上）
+func randomDirection(ready [10]int, rng *rand.Rand) byte {
+	var candidates []byte
+	for _, d := range directions {
+		if open(ready, d) {
+			candidates = append(candidates, d)
+		}
+	}
+	if len(candidates) == 0 {
+		return 'u'
+	}
+	return candidates[rng.Intn(len(candidates))]
+}
+
+type randomWalker struct {
+	rng *rand.Rand
+}
+
+func (b *randomWalker) Act(ready [10]int) string {
+	return "w" + string(randomDirection(ready, b.rng))
+}
+
+type wallFollower struct {
+	mode int
+}
+
+func (b *wallFollower) Act(ready [10]int) string {
+	// 進む方向と、壁に当たったときに曲がる方向
+	order := []byte{'d', 'r', 'u', 'l'}
+	dir := order[b.mode]
+	if open(ready, dir) {
+		return "w" + string(dir)
+	}
+	b.mode = (b.mode + 1) % len(order)
+	return "w" + string(order[b.mode])
+}
+
+type greedy struct {
+	rng     *rand.Rand
+	hunt    bool
+	heading byte
+}
+
+func (b *greedy) Act(ready [10]int) string {
+	if b.hunt {
+		for _, d := range directions {
+			if ready[neighbor[d]] == cellOpponent {
+				return "p" + string(d)
+			}
+		}
+	}
+	for _, d := range directions {
+		if ready[neighbor[d]] == cellItem {
+			b.heading = d
+			return "w" + string(d)
+		}
+	}
+	if b.heading == 0 || !open(ready, b.heading) {
+		b.heading = randomDirection(ready, b.rng)
+	}
+	return "w" + string(b.heading)
+}
@@ -0,0 +1,85 @@
+// Package arena はサーバーを起動せずにボット同士の対戦をプロセス内で行う
+//
+// 対戦は server.Board を使い、server の対戦ループと同じ手順（偶数ターンは Hot、
+// 奇数ターンは Cool が先攻、getReady の応答は周辺9マス）で進める。マップの評価や
+// 探索（chaser-mapgen evolve）のように、多数の対戦を高速に行う用途を想定している。
+package arena
+
+import (
+	"github.com/kqnade/CHaserGo/chasermap"
+	"github.com/kqnade/CHaserGo/server"
+)
+
+// Result は1試合の結果
+type Result struct {
+	// Turns は終了時のターン数（途中で決着した場合は MaxTurns より小さい）
+	Turns    int
+	MaxTurns int
+	// Winner は "hot", "cool" または引き分けの場合 ""
+	Winner    string
+	Reason    string
+	HotItems  int
+	CoolItems int
+	// Died はどちらかが壁に当たる・囲まれるなどして試合が終わったことを示す
+	Died bool
+}
+
+// Draw は引き分けかを返す
+func (r Result) Draw() bool {
+	return r.Winner == ""
+}
+
+// Play は m の上で hot と cool を対戦させる。m は変更しない
+func Play(m *chasermap.Map, hot, cool Bot) Result {
+	b := server.NewBoardFromMap(m)
+	b.Hot.Name, b.Cool.Name = "hot", "cool"
+
+	for b.Turn < b.MaxTurns && !b.GameOver {
+		first, second := b.Hot, b.Cool
+		firstBot, secondBot := hot, cool
+		if b.Turn%2 != 0 {
+			first, second = second, first
+			firstBot, secondBot = secondBot, firstBot
+		}
+		if act(b, first, firstBot); !b.GameOver {
+			act(b, second, secondBot)
+		}
+		if !b.GameOver {
+			b.IncrementTurn()
+		}
+	}
+
+	r := Result{
+		Turns:     b.Turn,
+		MaxTurns:  b.MaxTurns,
+		HotItems:  b.Hot.Items,
+		CoolItems: b.Cool.Items,
+		Died:      !b.Hot.IsAlive || !b.Cool.IsAlive,
+	}
+	var winner *server.Character
+	winner, r.Reason = b.GetResult()
+	if winner != nil {
+		r.Winner = winner.Name
+	}
+	return r
+}
+
+// act は char の1手（getReady と行動）を行う
+func act(b *server.Board, char *server.Character, bot Bot) {
+	opponent := b.GetOpponent(char)
+	// getReady の応答は walk の応答と同じ周辺9マス
+	action, dir, err := server.ParseAction(bot.Act(server.BuildWalkResponse(char, opponent, b)))
+	if err != nil {
+		// サーバーと同じく、不正なコマンドを送ったプレイヤーの負け
+		char.IsAlive = false
+		b.GameOver = true
+		return
+	}
+	switch action {
+	case "wk":
+		// 壁に当たった・囲まれた場合は Walk が char を負けにして GameOver にする
+		_ = b.Walk(char, dir)
+	case "pt":
+		b.Put(char.Position, dir)
+	}
+}
@@ -0,0 +1,132 @@
+package chasermap
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// FormatASCII は盤面を文字で描く書式（手書きのテスト用マップ向け）
+//
+//	name: <マップ名>    ← 省略可
+//	turns: <ターン数>
+//	#####
+//	#H.*#              ← #: ブロック, *: アイテム, .: 空白, H/C: 初期位置（空白マス）
+//	#*.C#
+//	#####
+//
+// ヘッダーは盤面より前に置く。盤面の各行は同じ幅でなければならない。
+const FormatASCII Format = "ascii"
+
+// asciiCells は盤面の文字とマスの対応
+var asciiCells = map[byte]CellType{'#': Block, '*': Item, '.': Empty, 'H': Empty, 'C': Empty}
+
+// isASCIIRow は line が盤面の1行として読めるかを返す
+func isASCIIRow(line string) bool {
+	for i := 0; i < len(line); i++ {
+		if _, ok := asciiCells[line[i]]; !ok {
+			return false
+		}
+	}
+	return line != ""
+}
+
+// isASCIIHeader は line が FormatASCII のヘッダー行かを返す
+func isASCIIHeader(line string) bool {
+	key, _, ok := strings.Cut(line, ":")
+	return ok && (key == "name" || key == "turns")
+}
+
+// asciiLines は FormatASCII の各行を返す
+func (m *Map) asciiLines() []string {
+	lines := make([]string, 0, m.Height+2)
+	if m.Name != "" {
+		lines = append(lines, "name: "+m.Name)
+	}
+	lines = append(lines, fmt.Sprintf("turns: %d", m.Turns))
+	for y, row := range m.Cells {
+		b := make([]byte, len(row))
+		for x, v := range row {
+			switch {
+			case m.Hot == (Position{X: x, Y: y}):
+				b[x] = 'H'
+			case m.Cool == (Position{X: x, Y: y}):
+				b[x] = 'C'
+			case v == Block:
+				b[x] = '#'
+			case v == Item:
+				b[x] = '*'
+			default:
+				b[x] = '.'
+			}
+		}
+		lines = append(lines, string(b))
+	}
+	return lines
+}
+
+// parseASCIILine は FormatASCII の1行を解釈する
+func (p *parser) parseASCIILine(line string) error {
+	if !isASCIIRow(line) {
+		key, value, ok := strings.Cut(line, ":")
+		if !ok || !isASCIIHeader(line) {
+			return p.errorf("unrecognized line %q", line)
+		}
+		if p.m.Cells != nil {
+			return p.errorf("%s header after the map rows", key)
+		}
+		tag := strings.ToUpper(key[:1])[0]
+		if first, ok := p.seen[tag]; ok {
+			return p.errorf("duplicate %s header (first on line %d)", key, first)
+		}
+		p.seen[tag] = p.line
+		value = strings.TrimSpace(value)
+		if key == "name" {
+			p.m.Name = value
+			return nil
+		}
+		turns, err := strconv.Atoi(value)
+		if err != nil {
+			return p.errorf("turns: invalid number %q", value)
+		}
+		p.m.Turns = turns
+		return nil
+	}
+
+	if p.m.Cells != nil && len(line) != p.m.Width {
+		return p.errorf("map row %d: got %d columns, want %d", p.m.Height, len(line), p.m.Width)
+	}
+	y := p.m.Height
+	row := make([]CellType, len(line))
+	for x := 0; x < len(line); x++ {
+		row[x] = asciiCells[line[x]]
+		if line[x] != 'H' && line[x] != 'C' {
+			continue
+		}
+		tag := line[x]
+		if first, ok := p.seen[tag]; ok {
+			return p.errorf("duplicate %c (first on line %d)", tag, first)
+		}
+		p.seen[tag] = p.line
+		p.setStart(tag, Position{X: x, Y: y})
+	}
+	p.m.Width = len(line)
+	p.m.Height++
+	p.m.Cells = append(p.m.Cells, row)
+	return nil
+}
+
+// finishASCII はファイル末尾で FormatASCII の必須項目を確認する
+func (p *parser) finishASCII() error {
+	switch {
+	case p.seen['T'] == 0:
+		return &ParseError{Line: p.line, Msg: "missing turns header"}
+	case p.m.Cells == nil:
+		return &ParseError{Line: p.line, Msg: "missing map rows"}
+	case p.seen['H'] == 0:
+		return &ParseError{Line: p.line, Msg: "missing H in map rows"}
+	case p.seen['C'] == 0:
+		return &ParseError{Line: p.line, Msg: "missing C in map rows"}
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+package chasermap
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Format はマップファイルの書式
+type Format string
+
+const (
+	// FormatSpace は "D y,x,値" でマスを1つずつ指定する書式（既定）
+	FormatSpace Format = "space"
+	// FormatColon は U-16 大会などで使われるコロン区切りの書式
+	//
+	//	N:<マップ名>
+	//	T:<ターン数>
+	//	S:<幅>,<高さ>
+	//	D:<値>,<値>,...   ← 1行につき盤面1行分（上から順に高さの数だけ）
+	//	H:<x>,<y>
+	//	C:<x>,<y>
+	//
+	// S・H・C は FormatSpace と異なり x（幅）が先になる。
+	FormatColon Format = "colon"
+)
+
+// Formats は指定可能な書式の一覧
+var Formats = []Format{FormatSpace, FormatColon, FormatASCII}
+
+// ParseFormat はフラグ等の文字列から Format を得る（空文字は FormatSpace）
+func ParseFormat(s string) (Format, error) {
+	if s == "" {
+		return FormatSpace, nil
+	}
+	for _, f := range Formats {
+		if string(f) == s {
+			return f, nil
+		}
+	}
+	return "", fmt.Errorf("unknown map format %q (want one of %v)", s, Formats)
+}
+
+// colonLines は FormatColon の各行を返す
+func (m *Map) colonLines() []string {
+	lines := make([]string, 0, m.Height+5)
+	lines = append(lines,
+		"N:"+m.Name,
+		fmt.Sprintf("T:%d", m.Turns),
+		fmt.Sprintf("S:%d,%d", m.Width, m.Height),
+	)
+	for _, row := range m.Cells {
+		values := make([]string, len(row))
+		for x, v := range row {
+			values[x] = strconv.Itoa(int(v))
+		}
+		lines = append(lines, "D:"+strings.Join(values, ","))
+	}
+	return append(lines,
+		fmt.Sprintf("H:%d,%d", m.Hot.X, m.Hot.Y),
+		fmt.Sprintf("C:%d,%d", m.Cool.X, m.Cool.Y),
+	)
+}
+
+// parseColonLine は FormatColon の1行を解釈する（tag の重複は呼び出し元で確認済み）
+func (p *parser) parseColonLine(tag byte, data string) error {
+	switch tag {
+	case 'N':
+		p.m.Name = data
+	case 'T':
+		v, err := p.ints(data, 1, "turn count")
+		if err != nil {
+			return err
+		}
+		p.m.Turns = v[0]
+	case 'S':
+		v, err := p.ints(data, 2, "size")
+		if err != nil {
+			return err
+		}
+		return p.setSize(v[0], v[1])
+	case 'D':
+		if p.m.Cells == nil {
+			return p.errorf("D line before S line")
+		}
+		if p.rows >= p.m.Height {
+			return p.errorf("too many D rows for height %d", p.m.Height)
+		}
+		row, err := p.colonRow(data)
+		if err != nil {
+			return err
+		}
+		p.m.Cells[p.rows] = row
+		p.rows++
+	case 'H', 'C':
+		v, err := p.ints(data, 2, startName(tag)+" position")
+		if err != nil {
+			return err
+		}
+		p.setStart(tag, Position{X: v[0], Y: v[1]})
+	default:
+		return p.errorf("unrecognized line %q", string(tag)+":"+data)
+	}
+	return nil
+}
+
+// colonRow は D 行の1行分の値を読む（"0,2,3" と "023" のどちらも受け付ける）
+func (p *parser) colonRow(data string) ([]CellType, error) {
+	var parts []string
+	if strings.Contains(data, ",") {
+		parts = strings.Split(data, ",")
+	} else {
+		parts = strings.Split(data, "")
+	}
+	if len(parts) != p.m.Width {
+		return nil, p.errorf("map row %d: got %d values, want %d", p.rows, len(parts), p.m.Width)
+	}
+	row := make([]CellType, len(parts))
+	for x, s := range parts {
+		v, err := strconv.Atoi(strings.TrimSpace(s))
+		if err != nil {
+			return nil, p.errorf("map row %d: invalid number %q", p.rows, s)
+		}
+		if !CellType(v).Valid() {
+			return nil, p.errorf("invalid cell value %d at (%d,%d)", v, p.rows, x)
+		}
+		row[x] = CellType(v)
+	}
+	return row, nil
+}
@@ -0,0 +1,112 @@
+// Package chasermap は CHaser のマップファイルを読み書きする。
+//
+// 既定の書式（FormatSpace）は次の行で構成される（y が先、x が後）:
+//
+//	N <マップ名>
+//	T <ターン数>
+//	S <高さ>,<幅>
+//	D <y>,<x>,<値>    ← 0: 空白, 2: ブロック, 3: アイテム（省略したマスは空白）
+//	H <y>,<x>         ← Hot（先攻）の初期位置
+//	C <y>,<x>         ← Cool（後攻）の初期位置
+//
+// コロン区切りの書式（FormatColon）と文字で盤面を描く書式（FormatASCII）も
+// 読み書きでき、読み込み時は自動で判別する。
+//
+// server（盤面）と mapgen（生成）はこのパッケージの Map を介してマップを扱う。
+// 変換関数は循環 import を避けるため各パッケージ側に置く
+// （server.NewBoardFromMap、mapgen.Map.ChaserMap）。
+package chasermap
+
+import (
+	"errors"
+	"fmt"
+)
+
+// CellType はマスの種類
+type CellType int
+
+const (
+	Empty CellType = 0
+	Block CellType = 2
+	Item  CellType = 3
+)
+
+// Valid はマップファイルに書ける値かを返す
+func (c CellType) Valid() bool {
+	return c == Empty || c == Block || c == Item
+}
+
+// Position は盤面上の座標
+type Position struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+}
+
+// Map は1つのマップファイルの内容
+type Map struct {
+	Name   string
+	Turns  int
+	Width  int
+	Height int
+	Cells  [][]CellType // Cells[y][x]
+	Hot    Position
+	Cool   Position
+}
+
+// New は全マスが空白の width×height のマップを作成する
+func New(width, height int) *Map {
+	m := &Map{Width: width, Height: height, Cells: make([][]CellType, height)}
+	for y := range m.Cells {
+		m.Cells[y] = make([]CellType, width)
+	}
+	return m
+}
+
+// InBounds は pos が盤面内かを返す
+func (m *Map) InBounds(pos Position) bool {
+	return pos.X >= 0 && pos.X < m.Width && pos.Y >= 0 && pos.Y < m.Height
+}
+
+// Validate はマップの内容が対戦に使えるか確認し、見つかった問題をまとめて返す
+func (m *Map) Validate() error {
+	var errs []error
+	if m.Turns <= 0 {
+		errs = append(errs, fmt.Errorf("invalid max turns: %d (must be > 0)", m.Turns))
+	}
+	if m.Width <= 0 || m.Height <= 0 {
+		return errors.Join(append(errs, fmt.Errorf("invalid board size: %dx%d", m.Width, m.Height))...)
+	}
+	if len(m.Cells) != m.Height {
+		return errors.Join(append(errs, fmt.Errorf("cells have %d rows, want %d", len(m.Cells), m.Height))...)
+	}
+	for y, row := range m.Cells {
+		if len(row) != m.Width {
+			errs = append(errs, fmt.Errorf("cells row %d has %d columns, want %d", y, len(row), m.Width))
+			continue
+		}
+		for x, v := range row {
+			if !v.Valid() {
+				errs = append(errs, fmt.Errorf("invalid cell value %d at (%d,%d)", v, y, x))
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	for _, start := range []struct {
+		name string
+		pos  Position
+	}{{"hot", m.Hot}, {"cool", m.Cool}} {
+		switch {
+		case !m.InBounds(start.pos):
+			errs = append(errs, fmt.Errorf("%s start position (%d,%d) is out of bounds", start.name, start.pos.Y, start.pos.X))
+		case m.Cells[start.pos.Y][start.pos.X] == Block:
+			errs = append(errs, fmt.Errorf("%s start position (%d,%d) is on a wall", start.name, start.pos.Y, start.pos.X))
+		}
+	}
+	if m.Hot == m.Cool {
+		errs = append(errs, fmt.Errorf("hot and cool start at the same position (%d,%d)", m.Hot.Y, m.Hot.X))
+	}
+	return errors.Join(errs...)
+}
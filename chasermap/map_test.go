@@ -0,0 +1,261 @@
+package chasermap
+
+import (
+	"bytes"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testMap = `N test
+T 100
+S 3,4
+D 0,0,2
+D 1,2,3
+
+H 1,0
+C 2,3
+`
+
+func TestParse(t *testing.T) {
+	m, err := Parse(strings.NewReader(testMap))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if m.Name != "test" || m.Turns != 100 || m.Width != 4 || m.Height != 3 {
+		t.Errorf("header = %q turns %d %dx%d", m.Name, m.Turns, m.Width, m.Height)
+	}
+	if m.Cells[0][0] != Block || m.Cells[1][2] != Item || m.Cells[2][2] != Empty {
+		t.Errorf("cells = %v", m.Cells)
+	}
+	if m.Hot != (Position{X: 0, Y: 1}) || m.Cool != (Position{X: 3, Y: 2}) {
+		t.Errorf("hot %+v cool %+v", m.Hot, m.Cool)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		line  int // 0 は ParseError 以外（Validate のエラー）
+	}{
+		{"unknown line", "N a\nX 1\n", 2},
+		{"no space", "T100\n", 1},
+		{"duplicate turns", "T 1\nT 2\n", 2},
+		{"D before S", "T 1\nD 0,0,2\n", 2},
+		{"out of bounds", "S 2,2\nD 2,0,2\n", 2},
+		{"bad value", "S 2,2\nD 0,0,5\n", 2},
+		{"duplicate cell", "S 2,2\nD 0,0,2\nD 0,0,3\n", 3},
+		{"bad number", "S 2,x\n", 1},
+		{"missing cool", "T 1\nS 2,2\nH 0,0\n", 3},
+		{"hot on wall", "T 1\nS 2,2\nD 0,0,2\nH 0,0\nC 1,1\n", 0},
+		{"same start", "T 1\nS 2,2\nH 0,0\nC 0,0\n", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(strings.NewReader(tt.input))
+			if err == nil {
+				t.Fatal("Parse succeeded, want error")
+			}
+			var pe *ParseError
+			if got := errors.As(err, &pe); got != (tt.line > 0) {
+				t.Fatalf("error %v: ParseError = %v, want %v", err, got, tt.line > 0)
+			}
+			if pe != nil && pe.Line != tt.line {
+				t.Errorf("error %v: line %d, want %d", err, pe.Line, tt.line)
+			}
+		})
+	}
+}
+
+func TestWriteRoundTrip(t *testing.T) {
+	m, err := Parse(strings.NewReader(testMap))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := m.Write(&buf); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if n := strings.Count(buf.String(), "\nD "); n != 12 {
+		t.Errorf("wrote %d D lines, want every cell (12)", n)
+	}
+
+	got, err := Parse(&buf)
+	if err != nil {
+		t.Fatalf("Parse(Write): %v", err)
+	}
+	if got.Name != m.Name || got.Turns != m.Turns || got.Hot != m.Hot || got.Cool != m.Cool {
+		t.Errorf("header = %+v, want %+v", got, m)
+	}
+	for y := range m.Cells {
+		for x := range m.Cells[y] {
+			if got.Cells[y][x] != m.Cells[y][x] {
+				t.Errorf("cell (%d,%d) = %d, want %d", y, x, got.Cells[y][x], m.Cells[y][x])
+			}
+		}
+	}
+}
+
+func TestSaveLoad(t *testing.T) {
+	m := New(3, 2)
+	m.Turns = 10
+	m.Cells[0][1] = Block
+	m.Cool = Position{X: 2, Y: 1}
+
+	path := filepath.Join(t.TempDir(), "sub", "a.map")
+	if err := m.Save(path); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	got, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if got.Name != "" || got.Cells[0][1] != Block || got.Cool != m.Cool {
+		t.Errorf("Load = %+v", got)
+	}
+	if _, err := Load(filepath.Join(t.TempDir(), "missing.map")); err == nil {
+		t.Error("Load of a missing file succeeded")
+	}
+}
+
+// testColonMap は testMap と同じ内容のコロン区切り書式
+const testColonMap = `N:test
+T:100
+S:4,3
+D:2,0,0,0
+D:0,0,3,0
+D:0000
+H:0,1
+C:3,2
+`
+
+func TestParseColon(t *testing.T) {
+	want, err := Parse(strings.NewReader(testMap))
+	if err != nil {
+		t.Fatalf("Parse(space): %v", err)
+	}
+	got, err := Parse(strings.NewReader(testColonMap))
+	if err != nil {
+		t.Fatalf("Parse(colon): %v", err)
+	}
+	if got.Name != want.Name || got.Width != want.Width || got.Height != want.Height || got.Hot != want.Hot || got.Cool != want.Cool {
+		t.Errorf("colon header = %+v, want %+v", got, want)
+	}
+	for y := range want.Cells {
+		for x := range want.Cells[y] {
+			if got.Cells[y][x] != want.Cells[y][x] {
+				t.Errorf("cell (%d,%d) = %d, want %d", y, x, got.Cells[y][x], want.Cells[y][x])
+			}
+		}
+	}
+}
+
+func TestParseColonErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		line  int
+	}{
+		{"mixed formats", "N:a\nT 100\n", 2},
+		{"short row", "T:1\nS:3,2\nD:0,0\n", 3},
+		{"too many rows", "S:1,1\nD:0\nD:0\n", 3},
+		{"missing rows", "T:1\nS:2,2\nD:00\nH:0,0\nC:1,1\n", 5},
+		{"bad value", "S:2,1\nD:05\n", 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(strings.NewReader(tt.input))
+			var pe *ParseError
+			if !errors.As(err, &pe) || pe.Line != tt.line {
+				t.Errorf("Parse error = %v, want ParseError on line %d", err, tt.line)
+			}
+		})
+	}
+}
+
+func TestWriteFormatRoundTrip(t *testing.T) {
+	m, err := Parse(strings.NewReader(testMap))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := m.WriteFormat(&buf, FormatColon); err != nil {
+		t.Fatalf("WriteFormat: %v", err)
+	}
+	if want := strings.Replace(testColonMap, "D:0000", "D:0,0,0,0", 1); buf.String() != want {
+		t.Errorf("WriteFormat(colon) =\n%s\nwant\n%s", buf.String(), want)
+	}
+	if err := m.WriteFormat(&buf, "xml"); err == nil {
+		t.Error("WriteFormat accepted an unknown format")
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	for in, want := range map[string]Format{"": FormatSpace, "space": FormatSpace, "colon": FormatColon} {
+		if got, err := ParseFormat(in); err != nil || got != want {
+			t.Errorf("ParseFormat(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	if _, err := ParseFormat("csv"); err == nil {
+		t.Error(`ParseFormat("csv") succeeded`)
+	}
+}
+
+// testASCIIMap は testMap と同じ内容の FormatASCII
+const testASCIIMap = `name: test
+turns: 100
+#...
+H.*.
+...C
+`
+
+func TestParseASCII(t *testing.T) {
+	want, err := Parse(strings.NewReader(testMap))
+	if err != nil {
+		t.Fatalf("Parse(space): %v", err)
+	}
+	got, err := Parse(strings.NewReader(testASCIIMap))
+	if err != nil {
+		t.Fatalf("Parse(ascii): %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ascii map = %+v, want %+v", got, want)
+	}
+
+	var buf bytes.Buffer
+	if err := want.WriteFormat(&buf, FormatASCII); err != nil {
+		t.Fatalf("WriteFormat: %v", err)
+	}
+	if buf.String() != testASCIIMap {
+		t.Errorf("WriteFormat(ascii) =\n%s\nwant\n%s", buf.String(), testASCIIMap)
+	}
+}
+
+func TestParseASCIIErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		line  int
+	}{
+		{"unknown header", "turns: 1\nsize: 3\n", 2},
+		{"bad turns", "turns: x\n", 1},
+		{"header after rows", "H.C\nturns: 1\n", 2},
+		{"ragged rows", "turns: 1\nH.C\n..\n", 3},
+		{"unknown character", "turns: 1\nH.C\n.x.\n", 3},
+		{"duplicate start", "turns: 1\nH.C\n.H.\n", 3},
+		{"missing turns", "H.C\n", 1},
+		{"missing cool", "turns: 1\nH..\n", 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(strings.NewReader(tt.input))
+			var pe *ParseError
+			if !errors.As(err, &pe) || pe.Line != tt.line {
+				t.Errorf("Parse error = %v, want ParseError on line %d", err, tt.line)
+			}
+		})
+	}
+}
@@ -0,0 +1,235 @@
+package chasermap
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// ParseError はマップファイルの構文エラー（Line は1始まりの行番号）
+type ParseError struct {
+	Line int
+	Msg  string
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("map line %d: %s", e.Line, e.Msg)
+}
+
+// Load はファイルからマップを読み込む
+func Load(path string) (*Map, error) {
+	m, _, err := LoadFormat(path)
+	return m, err
+}
+
+// LoadFormat は Load と同じくマップを読み込み、ファイルの書式もあわせて返す
+func LoadFormat(path string) (*Map, Format, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to open map file: %w", err)
+	}
+	defer file.Close()
+	return parse(file)
+}
+
+// Parse はマップを読み込み、Validate で内容を検証する
+//
+// 書式は最初の空行でない行で判別する。FormatASCII のヘッダーまたは盤面の行であれば
+// FormatASCII、それ以外は2文字目（' ' か ':'）で FormatSpace / FormatColon とする。
+// 未知の行、重複した N/T/S/H/C 行、S より前の D 行、盤面外や重複した D 行は
+// 行番号付きの *ParseError になる。空行は無視する。
+func Parse(r io.Reader) (*Map, error) {
+	m, _, err := parse(r)
+	return m, err
+}
+
+// parse は Parse の本体で、判別した書式もあわせて返す
+func parse(r io.Reader) (*Map, Format, error) {
+	p := &parser{seen: make(map[byte]int)}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		p.line++
+		if err := p.parseLine(strings.TrimRight(scanner.Text(), " \t\r")); err != nil {
+			return nil, "", err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, "", fmt.Errorf("error reading map file: %w", err)
+	}
+
+	if err := p.finish(); err != nil {
+		return nil, "", err
+	}
+	if err := p.m.Validate(); err != nil {
+		return nil, "", err
+	}
+	return &p.m, p.format, nil
+}
+
+// finish はファイル末尾で必須の行がそろっているか確認する
+func (p *parser) finish() error {
+	if p.format == FormatASCII {
+		return p.finishASCII()
+	}
+	for _, tag := range []byte{'T', 'S', 'H', 'C'} {
+		if p.seen[tag] == 0 {
+			return &ParseError{Line: p.line, Msg: fmt.Sprintf("missing %c line", tag)}
+		}
+	}
+	if p.format == FormatColon && p.rows != p.m.Height {
+		return &ParseError{Line: p.line, Msg: fmt.Sprintf("got %d D rows, want %d", p.rows, p.m.Height)}
+	}
+	return nil
+}
+
+type parser struct {
+	m      Map
+	format Format
+	line   int
+	seen   map[byte]int // 行の種類 → 最初に現れた行番号
+	// defined は FormatSpace の D 行で値が指定されたマス（重複検出用）
+	defined [][]bool
+	// rows は FormatColon で読み込んだ D 行の数
+	rows int
+}
+
+func (p *parser) errorf(format string, args ...any) error {
+	return &ParseError{Line: p.line, Msg: fmt.Sprintf(format, args...)}
+}
+
+func (p *parser) parseLine(line string) error {
+	if line == "" {
+		return nil
+	}
+	if p.format == "" && (isASCIIHeader(line) || isASCIIRow(line)) {
+		p.format = FormatASCII
+	}
+	if p.format == FormatASCII {
+		return p.parseASCIILine(line)
+	}
+	tag, data := line[0], ""
+	if len(line) > 1 {
+		sep := line[1]
+		if p.format == "" {
+			switch sep {
+			case ' ':
+				p.format = FormatSpace
+			case ':':
+				p.format = FormatColon
+			}
+		}
+		if (p.format == FormatSpace && sep != ' ') || (p.format == FormatColon && sep != ':') || p.format == "" {
+			return p.errorf("unrecognized line %q", line)
+		}
+		data = strings.TrimSpace(line[2:])
+	}
+
+	if tag != 'D' {
+		if first, ok := p.seen[tag]; ok {
+			return p.errorf("duplicate %c line (first on line %d)", tag, first)
+		}
+	}
+	if _, ok := p.seen[tag]; !ok {
+		p.seen[tag] = p.line
+	}
+
+	if p.format == FormatColon {
+		return p.parseColonLine(tag, data)
+	}
+
+	switch tag {
+	case 'N':
+		p.m.Name = data
+	case 'T':
+		v, err := p.ints(data, 1, "turn count")
+		if err != nil {
+			return err
+		}
+		p.m.Turns = v[0]
+	case 'S':
+		v, err := p.ints(data, 2, "size")
+		if err != nil {
+			return err
+		}
+		return p.setSize(v[1], v[0])
+	case 'D':
+		if p.m.Cells == nil {
+			return p.errorf("D line before S line")
+		}
+		v, err := p.ints(data, 3, "map data")
+		if err != nil {
+			return err
+		}
+		pos := Position{Y: v[0], X: v[1]}
+		if !p.m.InBounds(pos) {
+			return p.errorf("map data (%d,%d) is out of bounds for %dx%d", pos.Y, pos.X, p.m.Width, p.m.Height)
+		}
+		if !CellType(v[2]).Valid() {
+			return p.errorf("invalid cell value %d at (%d,%d)", v[2], pos.Y, pos.X)
+		}
+		if p.defined[pos.Y][pos.X] {
+			return p.errorf("duplicate map data for (%d,%d)", pos.Y, pos.X)
+		}
+		p.defined[pos.Y][pos.X] = true
+		p.m.Cells[pos.Y][pos.X] = CellType(v[2])
+	case 'H', 'C':
+		v, err := p.ints(data, 2, startName(tag)+" position")
+		if err != nil {
+			return err
+		}
+		p.setStart(tag, Position{Y: v[0], X: v[1]})
+	default:
+		return p.errorf("unrecognized line %q", line)
+	}
+	return nil
+}
+
+// setSize は S 行の大きさで盤面を初期化する
+func (p *parser) setSize(width, height int) error {
+	if width <= 0 || height <= 0 {
+		return p.errorf("invalid board size %dx%d", width, height)
+	}
+	p.m.Width, p.m.Height = width, height
+	p.m.Cells = New(width, height).Cells
+	p.defined = make([][]bool, height)
+	for y := range p.defined {
+		p.defined[y] = make([]bool, width)
+	}
+	return nil
+}
+
+// setStart は H（Hot）または C（Cool）行の初期位置を設定する
+func (p *parser) setStart(tag byte, pos Position) {
+	if tag == 'H' {
+		p.m.Hot = pos
+	} else {
+		p.m.Cool = pos
+	}
+}
+
+func startName(tag byte) string {
+	if tag == 'H' {
+		return "hot"
+	}
+	return "cool"
+}
+
+// ints はカンマ区切りの整数を want 個読む
+func (p *parser) ints(data string, want int, what string) ([]int, error) {
+	parts := strings.Split(data, ",")
+	if len(parts) != want {
+		return nil, p.errorf("%s: got %d values, want %d", what, len(parts), want)
+	}
+	values := make([]int, want)
+	for i, s := range parts {
+		v, err := strconv.Atoi(strings.TrimSpace(s))
+		if err != nil {
+			return nil, p.errorf("%s: invalid number %q", what, s)
+		}
+		values[i] = v
+	}
+	return values, nil
+}
@@ -0,0 +1,81 @@
+package chasermap
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// Lines は FormatSpace のマップファイルの各行を返す（D 行は全マスを行優先で並べる）
+func (m *Map) Lines() []string {
+	lines := make([]string, 0, m.Width*m.Height+5)
+	lines = append(lines,
+		"N "+m.Name,
+		fmt.Sprintf("T %d", m.Turns),
+		fmt.Sprintf("S %d,%d", m.Height, m.Width),
+	)
+	for y := 0; y < m.Height; y++ {
+		for x := 0; x < m.Width; x++ {
+			lines = append(lines, fmt.Sprintf("D %d,%d,%d", y, x, m.Cells[y][x]))
+		}
+	}
+	return append(lines,
+		fmt.Sprintf("H %d,%d", m.Hot.Y, m.Hot.X),
+		fmt.Sprintf("C %d,%d", m.Cool.Y, m.Cool.X),
+	)
+}
+
+// Write はマップを FormatSpace で書き出す
+func (m *Map) Write(w io.Writer) error {
+	return m.WriteFormat(w, FormatSpace)
+}
+
+// WriteFormat はマップを format の書式で書き出す
+func (m *Map) WriteFormat(w io.Writer, format Format) error {
+	var lines []string
+	switch format {
+	case FormatSpace:
+		lines = m.Lines()
+	case FormatColon:
+		lines = m.colonLines()
+	case FormatASCII:
+		lines = m.asciiLines()
+	default:
+		return fmt.Errorf("unknown map format %q", format)
+	}
+
+	bw := bufio.NewWriter(w)
+	for _, line := range lines {
+		if _, err := bw.WriteString(line + "\n"); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+// Save はマップを FormatSpace でファイルに保存する（ディレクトリがなければ作成する）
+func (m *Map) Save(filename string) error {
+	return m.SaveFormat(filename, FormatSpace)
+}
+
+// SaveFormat はマップを format の書式でファイルに保存する
+func (m *Map) SaveFormat(filename string, format Format) error {
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	file, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+	if err := m.WriteFormat(file, format); err != nil {
+		file.Close() // エラー時も明示的にクローズ
+		return fmt.Errorf("failed to write map: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to close file: %w", err)
+	}
+	return nil
+}
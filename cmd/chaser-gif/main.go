@@ -0,0 +1,82 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kqnade/CHaserGo/dump"
+	"github.com/kqnade/CHaserGo/render"
+	"github.com/kqnade/CHaserGo/theme"
+)
+
+const version = "0.2.0"
+
+func main() {
+	// コマンドライン引数の定義
+	output := flag.String("o", "", "Output GIF file (default: the dump file name with .gif)")
+	flag.StringVar(output, "output", "", "Output GIF file (default: the dump file name with .gif)")
+
+	themeName := flag.String("theme", "Light", "Tile theme: Light, Heavy, Jewel or a theme under -theme-dir")
+	themeDir := flag.String("theme-dir", theme.DefaultDir, "Directory of custom themes (one subdirectory per theme)")
+	tileSize := flag.Int("tile", render.DefaultTileSize, "Tile size in pixels")
+	delay := flag.Duration("delay", render.DefaultFrameDelay, "Display time of each frame")
+	endDelay := flag.Duration("end-delay", render.DefaultEndDelay, "Display time of the last frame (the result)")
+
+	showVersion := flag.Bool("v", false, "Show version")
+	flag.BoolVar(showVersion, "version", false, "Show version")
+
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "CHaser GIF - Render a finished game to an animated GIF\n\n")
+		fmt.Fprintf(os.Stderr, "Usage: %s [options] <dumpfile>\n\n", filepath.Base(os.Args[0]))
+		fmt.Fprintf(os.Stderr, "Arguments:\n")
+		fmt.Fprintf(os.Stderr, "  <dumpfile>   Dump file written by chaser-server (viewer or jsonl format)\n\n")
+		fmt.Fprintf(os.Stderr, "Options:\n")
+		flag.PrintDefaults()
+		fmt.Fprintf(os.Stderr, "\nExample:\n")
+		fmt.Fprintf(os.Stderr, "  %s chaser.dump\n", filepath.Base(os.Args[0]))
+		fmt.Fprintf(os.Stderr, "  %s -theme Jewel -delay 80ms -o final.gif game.jsonl\n", filepath.Base(os.Args[0]))
+	}
+
+	flag.Parse()
+
+	// バージョン表示
+	if *showVersion {
+		fmt.Printf("CHaser GIF version %s\n", version)
+		return
+	}
+
+	if flag.NArg() != 1 {
+		fmt.Fprintf(os.Stderr, "Error: dump file is required\n\n")
+		flag.Usage()
+		os.Exit(1)
+	}
+	dumpPath := flag.Arg(0)
+	if *output == "" {
+		*output = strings.TrimSuffix(dumpPath, filepath.Ext(dumpPath)) + ".gif"
+	}
+
+	th, err := theme.Named(*themeDir, *themeName)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	r := render.New(th, *tileSize)
+
+	game, err := dump.Load(dumpPath)
+	if err != nil {
+		log.Fatalf("Failed to load dump: %v", err)
+	}
+	// 多少壊れたダンプでも書き出せるよう、検証エラーは警告に留める
+	if err := game.Validate(); err != nil {
+		log.Printf("Warning: dump has inconsistencies:\n%v", err)
+	}
+	snaps := game.Snapshots()
+	if err := r.SaveGIF(*output, snaps, render.GIFOptions{Delay: *delay, EndDelay: *endDelay}); err != nil {
+		log.Fatalf("Failed to write GIF: %v", err)
+	}
+	log.Printf("Wrote %s (%d snapshots)", *output, len(snaps))
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/kqnade/CHaserGo/analysis"
+	"github.com/kqnade/CHaserGo/chasermap"
+)
+
+// runAnalyze は "analyze" サブコマンド: マップの関節点・行き止まり・アイテムの集まりを表示する
+func runAnalyze(args []string) {
+	fs := flag.NewFlagSet("analyze", flag.ExitOnError)
+	asJSON := fs.Bool("json", false, "Print the full analysis (including distance fields) as JSON")
+	limit := fs.Int("n", 10, "Maximum number of entries printed per section (0 for all)")
+	fs.Usage = func() {
+		name := filepath.Base(os.Args[0])
+		fmt.Fprintf(os.Stderr, "Usage: %s analyze [options] <map>\n\n", name)
+		fmt.Fprintf(os.Stderr, "Prints chokepoints, dead-end corridors and item clusters of a map file.\n")
+		fmt.Fprintf(os.Stderr, "Positions are printed as (y,x), the same order as in map files.\n\n")
+		fmt.Fprintf(os.Stderr, "Options:\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		fmt.Fprintf(os.Stderr, "Error: analyze needs exactly one map file\n\n")
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	m, err := chasermap.Load(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s: %v\n", fs.Arg(0), err)
+		os.Exit(1)
+	}
+	a := analysis.Analyze(m)
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(a); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+	printAnalysis(m, a, *limit)
+}
+
+// printAnalysis は解析結果を人が読む形式で標準出力に書き出す
+func printAnalysis(m *chasermap.Map, a *analysis.Analysis, limit int) {
+	at := func(p chasermap.Position) string { return fmt.Sprintf("(%d,%d)", p.Y, p.X) }
+	// shown は limit 件までに切り詰めた件数を返す
+	shown := func(n int) int {
+		if limit > 0 && n > limit {
+			return limit
+		}
+		return n
+	}
+	more := func(n int) {
+		if s := shown(n); s < n {
+			fmt.Printf("  ... and %d more\n", n-s)
+		}
+	}
+
+	fmt.Printf("Map: %s (%dx%d, %d turns)\n", m.Name, m.Width, m.Height, m.Turns)
+	fmt.Printf("Walkable cells: %d, items: %d\n", a.Walkable, a.Items)
+	fmt.Printf("Hot %s, Cool %s, start distance: %d\n", at(m.Hot), at(m.Cool), a.StartDistance)
+
+	fmt.Printf("\nChokepoints (%d):\n", len(a.Chokepoints))
+	for _, c := range a.Chokepoints[:shown(len(a.Chokepoints))] {
+		fmt.Printf("  %-8s cuts off %d cells, %d items\n", at(c.Position), c.Separated, c.SeparatedItems)
+	}
+	more(len(a.Chokepoints))
+
+	fmt.Printf("\nDead ends (%d):\n", len(a.DeadEnds))
+	for _, d := range a.DeadEnds[:shown(len(a.DeadEnds))] {
+		fmt.Printf("  %-8s -> %-8s length %d, %d items\n", at(d.Tip), at(d.Mouth), len(d.Cells), d.Items)
+	}
+	more(len(a.DeadEnds))
+
+	fmt.Printf("\nItem clusters (%d):\n", len(a.ItemClusters))
+	for _, c := range a.ItemClusters[:shown(len(a.ItemClusters))] {
+		fmt.Printf("  %2d items around %-8s hot %d, cool %d steps\n", len(c.Items), at(c.Center), c.HotDistance, c.CoolDistance)
+	}
+	more(len(a.ItemClusters))
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/kqnade/CHaserGo/chasermap"
+)
+
+// runConvert は "convert" サブコマンド: マップファイルを別の書式に変換する
+// 入力の書式は自動で判別する。出力先を省略した場合は標準出力に書き出す
+func runConvert(args []string) {
+	fs := flag.NewFlagSet("convert", flag.ExitOnError)
+	format := fs.String("format", string(chasermap.FormatSpace), "Output format: space, colon or ascii")
+	fs.Usage = func() {
+		name := filepath.Base(os.Args[0])
+		fmt.Fprintf(os.Stderr, "Usage: %s convert [options] <input> [output]\n\n", name)
+		fmt.Fprintf(os.Stderr, "Converts a map file between the space, colon and ascii formats.\n")
+		fmt.Fprintf(os.Stderr, "The input format is detected automatically. Without [output] the map is written to stdout.\n\n")
+		fmt.Fprintf(os.Stderr, "Options:\n")
+		fs.PrintDefaults()
+		fmt.Fprintf(os.Stderr, "\nExample:\n")
+		fmt.Fprintf(os.Stderr, "  %s convert -format ascii map.map          # Print a map as ASCII art\n", name)
+		fmt.Fprintf(os.Stderr, "  %s convert scenario.txt scenario.map      # Convert an ASCII-art map to a map file\n", name)
+	}
+	fs.Parse(args)
+
+	if fs.NArg() < 1 || fs.NArg() > 2 {
+		fmt.Fprintf(os.Stderr, "Error: convert needs an input file and an optional output file\n\n")
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	to, err := chasermap.ParseFormat(*format)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	m, err := chasermap.Load(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s: %v\n", fs.Arg(0), err)
+		os.Exit(1)
+	}
+
+	if fs.NArg() == 1 {
+		err = m.WriteFormat(os.Stdout, to)
+	} else {
+		err = m.SaveFormat(fs.Arg(1), to)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
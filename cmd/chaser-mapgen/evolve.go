@@ -0,0 +1,119 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/kqnade/CHaserGo/arena"
+	"github.com/kqnade/CHaserGo/chasermap"
+	"github.com/kqnade/CHaserGo/evolve"
+	"github.com/kqnade/CHaserGo/mapgen"
+)
+
+// runEvolve は "evolve" サブコマンド: 参考ボット同士の対戦で評価しながらマップを進化的に探索する
+func runEvolve(args []string) {
+	def := evolve.DefaultConfig()
+	fs := flag.NewFlagSet("evolve", flag.ExitOnError)
+	generations := fs.Int("generations", def.Generations, "Number of generations")
+	population := fs.Int("population", def.Population, "Maps per generation")
+	elite := fs.Int("elite", def.Elite, "Best maps carried over unchanged to the next generation")
+	mutations := fs.Int("mutations", def.Mutations, "Cells (with their symmetric counterparts) changed per mutation")
+	bots := fs.String("bots", "greedy,hunter,random", "Comma-separated reference bots: random, wall-follower, greedy or hunter")
+	games := fs.Int("games", def.Games, "Matches per bot pairing and side")
+	keep := fs.Int("keep", 3, "Number of best maps to save")
+
+	wLength := fs.Float64("w-length", def.Weights.Length, "Fitness weight of the average game length")
+	wBalance := fs.Float64("w-balance", def.Weights.Balance, "Fitness weight of how close the item counts are")
+	wDraw := fs.Float64("w-draw", def.Weights.Draw, "Fitness penalty per draw rate")
+	wDeath := fs.Float64("w-death", def.Weights.Death, "Fitness penalty per rate of games ended by a player hitting a wall")
+
+	width := fs.Int("width", mapgen.DefaultWidth, "Map width")
+	height := fs.Int("height", mapgen.DefaultHeight, "Map height")
+	symmetry := fs.String("symmetry", string(mapgen.SymmetryRotate4), "Map symmetry: rotate4, point, mirror-lr, mirror-tb or none")
+	style := fs.String("style", string(mapgen.StyleScatter), "Block layout of the initial maps: scatter, maze, rooms or caves")
+	turns := fs.Int("turns", mapgen.DefaultTurns, "Number of turns written to the map")
+	format := fs.String("format", string(chasermap.FormatSpace), "Map file format: space, colon or ascii")
+	outputDir := fs.String("o", "./evolved_map", "Output directory")
+	seed := fs.Int64("s", 0, "Random seed (0 for current time; the seed used is logged)")
+	fs.Usage = func() {
+		name := filepath.Base(os.Args[0])
+		fmt.Fprintf(os.Stderr, "Usage: %s evolve [options]\n\n", name)
+		fmt.Fprintf(os.Stderr, "Searches for maps where the reference bots play long, close games with few wall deaths.\n")
+		fmt.Fprintf(os.Stderr, "fitness = w-length*length + w-balance*balance - w-draw*draws - w-death*deaths\n\n")
+		fmt.Fprintf(os.Stderr, "Options:\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() != 0 {
+		fmt.Fprintf(os.Stderr, "Error: evolve takes no arguments\n\n")
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	cfg := evolve.Config{
+		Population:  *population,
+		Generations: *generations,
+		Elite:       *elite,
+		Mutations:   *mutations,
+		Games:       *games,
+		Weights:     evolve.Weights{Length: *wLength, Balance: *wBalance, Draw: *wDraw, Death: *wDeath},
+		Seed:        *seed,
+	}
+	var err error
+	if cfg.Bots, err = arena.ParseStrategies(*bots); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: -bots: %v\n", err)
+		os.Exit(1)
+	}
+	opts := mapgen.DefaultOptions()
+	opts.Width, opts.Height, opts.Turns = *width, *height, *turns
+	if opts.Symmetry, err = mapgen.ParseSymmetry(*symmetry); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if opts.Style, err = mapgen.ParseStyle(*style); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	opts.ClampCounts()
+	cfg.Options = opts
+	mapFormat, err := chasermap.ParseFormat(*format)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if *keep <= 0 || *keep > *population {
+		fmt.Fprintf(os.Stderr, "Error: -keep must be between 1 and -population\n")
+		os.Exit(1)
+	}
+	if err := cfg.Validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	// 再現できるように、乱数で決めた場合もシードを表示する
+	if cfg.Seed == 0 {
+		cfg.Seed = time.Now().UnixNano()
+	}
+	log.Printf("Using seed: %d", cfg.Seed)
+	log.Printf("Size: %dx%d, Symmetry: %s, Style: %s, Bots: %s", opts.Width, opts.Height, opts.Symmetry, opts.Style, *bots)
+
+	result, err := evolve.Run(cfg, func(g int, pop []evolve.Candidate) {
+		log.Printf("Generation %d/%d: best %v", g, cfg.Generations, pop[0].Score)
+	})
+	if err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+
+	for i, c := range result[:*keep] {
+		filename := filepath.Join(*outputDir, fmt.Sprintf("EvolvedMap_%d.map", i+1))
+		if err := c.Map.SaveToFileFormat(filename, mapFormat); err != nil {
+			log.Fatalf("Error: failed to save %s: %v", filename, err)
+		}
+		log.Printf("Saved: %s (generation %d, %v)", filename, c.Generation, c.Score)
+	}
+}
@@ -3,16 +3,39 @@ package main
 import (
 	"flag"
 	"fmt"
+	"image"
 	"log"
 	"os"
 	"path/filepath"
+	"strings"
 
+	"github.com/kqnade/CHaserGo/chasermap"
 	"github.com/kqnade/CHaserGo/mapgen"
+	"github.com/kqnade/CHaserGo/render"
+	"github.com/kqnade/CHaserGo/theme"
 )
 
 const version = "0.2.0"
 
 func main() {
+	// サブコマンド
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "convert":
+			runConvert(os.Args[2:])
+			return
+		case "analyze":
+			runAnalyze(os.Args[2:])
+			return
+		case "evolve":
+			runEvolve(os.Args[2:])
+			return
+		case "render":
+			runRender(os.Args[2:])
+			return
+		}
+	}
+
 	// コマンドライン引数の定義
 	blockNum := flag.Int("b", 9, "Maximum number of blocks in small map")
 	flag.IntVar(blockNum, "blockNum", 9, "Maximum number of blocks in small map")
@@ -20,6 +43,30 @@ func main() {
 	itemNum := flag.Int("i", 10, "Maximum number of items in small map")
 	flag.IntVar(itemNum, "itemNum", 10, "Maximum number of items in small map")
 
+	blocks := flag.String("blocks", "", "Blocks per small map: N for exactly N or MIN-MAX (overrides -b)")
+	items := flag.String("items", "", "Items per small map: N for exactly N or MIN-MAX (overrides -i)")
+
+	turns := flag.Int("turns", mapgen.DefaultTurns, "Number of turns written to the map")
+	minDistance := flag.Int("min-distance", 0, "Minimum path length between the Hot and Cool starts (0 for no limit)")
+
+	width := flag.Int("width", mapgen.DefaultWidth, "Map width")
+	height := flag.Int("height", mapgen.DefaultHeight, "Map height")
+
+	symmetry := flag.String("symmetry", string(mapgen.SymmetryRotate4), "Map symmetry: rotate4, point, mirror-lr, mirror-tb or none")
+
+	style := flag.String("style", string(mapgen.StyleScatter), "Block layout: scatter, maze, rooms or caves")
+
+	fair := flag.Bool("fair", false, "Reject maps with unreachable cells, enclosed starts or unbalanced items")
+	repair := flag.Bool("repair", false, "With -fair, remove blocks to make every cell reachable instead of rejecting the map")
+	maxAdvantage := flag.Float64("max-advantage", 0.1, "With -fair, maximum distance-weighted item advantage of either player (0-1, 0 for no limit)")
+
+	format := flag.String("format", string(chasermap.FormatSpace), "Map file format: space (\"D y,x,v\" lines), colon (U-16 style \"D:\" rows) or ascii")
+
+	pngOut := flag.Bool("png", false, "Also save a PNG preview of each map and a contact sheet (contact_sheet.png) of all maps")
+	themeName := flag.String("theme", "Light", "Tile theme of the PNG previews: Light, Heavy, Jewel or a theme under -theme-dir")
+	themeDir := flag.String("theme-dir", theme.DefaultDir, "Directory of custom themes (one subdirectory per theme)")
+	tileSize := flag.Int("tile", render.DefaultTileSize, "Tile size of the PNG previews in pixels")
+
 	outputDir := flag.String("o", "./generated_map", "Output directory")
 	flag.StringVar(outputDir, "output", "./generated_map", "Output directory")
 
@@ -31,15 +78,26 @@ func main() {
 
 	flag.Usage = func() {
 		fmt.Fprintf(os.Stderr, "CHaser MapGenerator - Random map generator for CHaser\n\n")
-		fmt.Fprintf(os.Stderr, "Usage: %s [options] <count>\n\n", filepath.Base(os.Args[0]))
+		fmt.Fprintf(os.Stderr, "Usage: %s [options] <count>\n", filepath.Base(os.Args[0]))
+		fmt.Fprintf(os.Stderr, "       %s convert [options] <input> [output]\n", filepath.Base(os.Args[0]))
+		fmt.Fprintf(os.Stderr, "       %s analyze [options] <map>\n", filepath.Base(os.Args[0]))
+		fmt.Fprintf(os.Stderr, "       %s evolve [options]\n", filepath.Base(os.Args[0]))
+		fmt.Fprintf(os.Stderr, "       %s render [options] <map>...\n\n", filepath.Base(os.Args[0]))
 		fmt.Fprintf(os.Stderr, "Arguments:\n")
 		fmt.Fprintf(os.Stderr, "  <count>      Number of maps to generate (required)\n\n")
 		fmt.Fprintf(os.Stderr, "Options:\n")
 		flag.PrintDefaults()
 		fmt.Fprintf(os.Stderr, "\nExample:\n")
 		fmt.Fprintf(os.Stderr, "  %s 10                    # Generate 10 maps with default settings\n", filepath.Base(os.Args[0]))
-		fmt.Fprintf(os.Stderr, "  %s -b 15 -i 20 5         # Generate 5 maps with 15 blocks and 20 items\n", filepath.Base(os.Args[0]))
+		fmt.Fprintf(os.Stderr, "  %s -b 15 -i 20 5         # Generate 5 maps with up to 15 blocks and 20 items\n", filepath.Base(os.Args[0]))
+		fmt.Fprintf(os.Stderr, "  %s -blocks 8 -items 6-10 5 # Generate 5 maps with exactly 8 blocks and 6-10 items\n", filepath.Base(os.Args[0]))
 		fmt.Fprintf(os.Stderr, "  %s -o ./maps -s 12345 3  # Generate 3 maps with specific seed\n", filepath.Base(os.Args[0]))
+		fmt.Fprintf(os.Stderr, "  %s -width 21 -height 17 3 # Generate 3 maps of size 21x17\n", filepath.Base(os.Args[0]))
+		fmt.Fprintf(os.Stderr, "  %s -symmetry mirror-lr 3 # Generate 3 left-right mirrored maps\n", filepath.Base(os.Args[0]))
+		fmt.Fprintf(os.Stderr, "  %s -fair -repair 3       # Generate 3 maps where every cell is reachable\n", filepath.Base(os.Args[0]))
+		fmt.Fprintf(os.Stderr, "  %s -style maze 3         # Generate 3 corridor-heavy maze maps\n", filepath.Base(os.Args[0]))
+		fmt.Fprintf(os.Stderr, "  %s -format colon 3       # Generate 3 maps in the colon-delimited format\n", filepath.Base(os.Args[0]))
+		fmt.Fprintf(os.Stderr, "  %s -png 8                # Generate 8 maps with PNG previews and a contact sheet\n", filepath.Base(os.Args[0]))
 	}
 
 	flag.Parse()
@@ -77,6 +135,72 @@ func main() {
 		os.Exit(1)
 	}
 
+	sym, err := mapgen.ParseSymmetry(*symmetry)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	mapStyle, err := mapgen.ParseStyle(*style)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	mapFormat, err := chasermap.ParseFormat(*format)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	var renderer *render.Renderer
+	if *pngOut {
+		th, err := theme.Named(*themeDir, *themeName)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		renderer = render.New(th, *tileSize)
+	}
+
+	if *repair && !*fair {
+		fmt.Fprintf(os.Stderr, "Error: -repair requires -fair\n")
+		os.Exit(1)
+	}
+
+	// 生成設定（-b/-i は上限なので置ける数に丸め、-blocks/-items は指定どおりの数を置く）
+	opts := mapgen.Options{
+		Width:            *width,
+		Height:           *height,
+		Symmetry:         sym,
+		Style:            mapStyle,
+		Turns:            *turns,
+		Blocks:           mapgen.UpTo(*blockNum),
+		Items:            mapgen.UpTo(*itemNum),
+		MinStartDistance: *minDistance,
+	}
+	opts.ClampCounts()
+	if *blocks != "" {
+		if opts.Blocks, err = mapgen.ParseRange(*blocks); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: -blocks: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	if *items != "" {
+		if opts.Items, err = mapgen.ParseRange(*items); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: -items: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	if *turns <= 0 {
+		fmt.Fprintf(os.Stderr, "Error: -turns must be positive\n")
+		os.Exit(1)
+	}
+	if err := opts.Validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
 	// ジェネレーター作成
 	var gen *mapgen.Generator
 	if *seed != 0 {
@@ -89,25 +213,55 @@ func main() {
 
 	// マップ生成
 	log.Printf("Generating %d maps...", count)
-	log.Printf("Max blocks: %d, Max items: %d", *blockNum, *itemNum)
+	log.Printf("Size: %dx%d, Symmetry: %s, Style: %s, Format: %s, Turns: %d", *width, *height, sym, mapStyle, mapFormat, *turns)
+	log.Printf("Blocks: %v, Items: %v (per small map)", opts.Blocks, opts.Items)
 	log.Printf("Output directory: %s", *outputDir)
 
 	successCount := 0
+	var previews []image.Image
 	for i := 0; i < count; i++ {
 		// マップ生成
-		m := gen.GenerateMap(*blockNum, *itemNum)
+		var m *mapgen.Map
+		if *fair {
+			m, err = gen.GenerateFair(opts, mapgen.Fairness{Repair: *repair, MaxAdvantage: *maxAdvantage})
+		} else {
+			m, err = gen.Generate(opts)
+		}
+		if err != nil {
+			log.Printf("Warning: failed to generate map %d: %v", i+1, err)
+			continue
+		}
 
 		// ファイル名
 		filename := filepath.Join(*outputDir, fmt.Sprintf("RandMap_%d.map", i+1))
 
 		// ファイル保存
-		if err := m.SaveToFile(filename); err != nil {
+		if err := m.SaveToFileFormat(filename, mapFormat); err != nil {
 			log.Printf("Warning: failed to save map %d: %v", i+1, err)
 			continue
 		}
 
 		successCount++
-		log.Printf("[%d/%d] Generated: %s", i+1, count, filename)
+		log.Printf("[%d/%d] Generated: %s (%v)", i+1, count, filename, m.Stats())
+
+		if renderer != nil {
+			img := renderer.Map(m.ChaserMap(""))
+			pngFile := strings.TrimSuffix(filename, ".map") + ".png"
+			if err := render.Save(pngFile, img); err != nil {
+				log.Printf("Warning: failed to save preview of map %d: %v", i+1, err)
+				continue
+			}
+			previews = append(previews, img)
+		}
+	}
+
+	if len(previews) > 1 {
+		sheetFile := filepath.Join(*outputDir, "contact_sheet.png")
+		if err := render.Save(sheetFile, render.ContactSheet(previews, 0)); err != nil {
+			log.Printf("Warning: failed to save contact sheet: %v", err)
+		} else {
+			log.Printf("Contact sheet: %s", sheetFile)
+		}
 	}
 
 	if successCount == count {
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"image"
+	"os"
+	"path/filepath"
+
+	"github.com/kqnade/CHaserGo/chasermap"
+	"github.com/kqnade/CHaserGo/render"
+	"github.com/kqnade/CHaserGo/theme"
+)
+
+// runRender は "render" サブコマンド: マップファイルを PNG 画像に描画する
+// 複数のマップを指定した場合は1枚のコンタクトシートにまとめる
+func runRender(args []string) {
+	fs := flag.NewFlagSet("render", flag.ExitOnError)
+	output := fs.String("o", "map.png", "Output PNG file")
+	themeName := fs.String("theme", "Light", "Tile theme: Light, Heavy, Jewel or a theme under -theme-dir")
+	themeDir := fs.String("theme-dir", theme.DefaultDir, "Directory of custom themes (one subdirectory per theme)")
+	tileSize := fs.Int("tile", render.DefaultTileSize, "Tile size in pixels")
+	columns := fs.Int("columns", 0, "Columns of the contact sheet (0 for a near-square grid)")
+	fs.Usage = func() {
+		name := filepath.Base(os.Args[0])
+		fmt.Fprintf(os.Stderr, "Usage: %s render [options] <map>...\n\n", name)
+		fmt.Fprintf(os.Stderr, "Draws a map file to PNG, or several map files to a contact sheet.\n\n")
+		fmt.Fprintf(os.Stderr, "Options:\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() == 0 {
+		fmt.Fprintf(os.Stderr, "Error: render needs at least one map file\n\n")
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	th, err := theme.Named(*themeDir, *themeName)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	r := render.New(th, *tileSize)
+
+	var imgs []image.Image
+	for _, path := range fs.Args() {
+		m, err := chasermap.Load(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s: %v\n", path, err)
+			os.Exit(1)
+		}
+		imgs = append(imgs, r.Map(m))
+	}
+
+	img := imgs[0]
+	if len(imgs) > 1 {
+		img = render.ContactSheet(imgs, *columns)
+	}
+	if err := render.Save(*output, img); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
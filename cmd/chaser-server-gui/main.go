@@ -8,15 +8,42 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/kqnade/CHaserGo/dump"
 	"github.com/kqnade/CHaserGo/gui"
 	"github.com/kqnade/CHaserGo/mapgen"
 	"github.com/kqnade/CHaserGo/server"
+	"github.com/kqnade/CHaserGo/stats"
+	"github.com/kqnade/CHaserGo/theme"
 )
 
 const version = "0.3.0"
 
+// display は live・リプレイ共通の表示設定
+type display struct {
+	textScale float64
+	themes    []*theme.Theme
+	themeName string
+	heat      *stats.Heatmap
+}
+
+// apply は表示設定を app に反映する
+func (d *display) apply(app *gui.App) {
+	app.SetTextScale(d.textScale)
+	app.SetThemes(d.themes)
+	if d.themeName != "" {
+		if err := app.SelectTheme(d.themeName); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	if d.heat != nil {
+		app.SetHeatmap(d.heat)
+	}
+}
+
 func main() {
 	hotPort := flag.Int("f", 2009, "Hot (first) player port")
 	flag.IntVar(hotPort, "first-port", 2009, "Hot (first) player port")
@@ -27,8 +54,26 @@ func main() {
 	dumpPath := flag.String("d", "./chaser.dump", "Dump file output path")
 	flag.StringVar(dumpPath, "dump-path", "./chaser.dump", "Dump file output path")
 
+	dumpFormat := flag.String("dump-format", "viewer", "Dump file format: viewer (CHaserViewer) or jsonl (per half-turn actions)")
+
 	bindAddr := flag.String("bind", "127.0.0.1", "Address to bind (use 0.0.0.0 to expose to network)")
 
+	controlAddr := flag.String("control", "", "Control HTTP API address (e.g. 127.0.0.1:8080; empty to disable)")
+
+	viewerAddr := flag.String("viewer", "", "Web viewer address (e.g. 0.0.0.0:8081 to let audiences watch; empty to disable)")
+
+	replayPath := flag.String("replay", "", "Replay a dump file instead of hosting a game")
+
+	textScale := flag.Float64("text-scale", 1, "Text size multiplier: 1, 1.5 or 2 (larger for projectors; +/- keys change it while running)")
+
+	themeDir := flag.String("theme-dir", theme.DefaultDir, "Directory of custom tile themes (each subdirectory with a "+theme.ManifestName+" manifest); added after the built-in themes")
+
+	themeName := flag.String("theme", "", "Initial tile theme name (default: the first built-in theme)")
+
+	heatmapDumps := flag.String("heatmap", "", "Dump files for the visit heatmap (comma-separated, globs allowed; e.g. 'dumps/*.dump'). Replaces the current game's heatmap")
+
+	delay := flag.Duration("delay", 0, "Initial wait before each half-turn (e.g. 200ms; Up/Down keys change it while running)")
+
 	noDump := flag.Bool("nd", false, "Disable dump output")
 	flag.BoolVar(noDump, "non-dump", false, "Disable dump output")
 
@@ -42,6 +87,13 @@ func main() {
 		fmt.Fprintf(os.Stderr, "  [mapfile]    Path to the map file (optional; auto-generated if omitted)\n\n")
 		fmt.Fprintf(os.Stderr, "Options:\n")
 		flag.PrintDefaults()
+		fmt.Fprintf(os.Stderr, "\nDisplay controls:\n")
+		fmt.Fprintf(os.Stderr, "  T: next theme (built-in, then -theme-dir), A: action overlay, V: view, S: stats, +/-: text size\n")
+		fmt.Fprintf(os.Stderr, "  R: movement trails, H: visit heatmap (off -> each player -> off)\n")
+		fmt.Fprintf(os.Stderr, "\nLive game controls:\n")
+		fmt.Fprintf(os.Stderr, "  Space: pause/resume, Right: step one action, Up/Down: shorter/longer delay\n")
+		fmt.Fprintf(os.Stderr, "\nReplay controls:\n")
+		fmt.Fprintf(os.Stderr, "  Space: play/pause, Left/Right: step, Up/Down: speed, Home/End: jump, click bar: seek\n")
 	}
 
 	flag.Parse()
@@ -51,6 +103,29 @@ func main() {
 		return
 	}
 
+	disp := &display{textScale: *textScale, themeName: *themeName}
+	disp.themes = loadThemes(*themeDir)
+	if *heatmapDumps != "" {
+		heat, err := loadHeatmap(*heatmapDumps)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		log.Printf("Heatmap: %d games on a %dx%d board", heat.Games, heat.Width, heat.Height)
+		disp.heat = heat
+	}
+
+	if *replayPath != "" {
+		runReplay(*replayPath, disp)
+		return
+	}
+
+	format, err := server.ParseDumpFormat(*dumpFormat)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
 	// マップファイルの決定（省略時は自動生成）
 	var mapPath string
 	if flag.NArg() >= 1 {
@@ -80,14 +155,25 @@ func main() {
 	// スナップショット channel（buffered=1: 常に最新だけ保持）
 	ch := make(chan server.BoardSnapshot, 1)
 
+	// 状態管理（巻き戻し用の履歴は Recorder として取りこぼしなく受け取る）
+	state := &gui.GameState{}
+
+	// 進行速度（一時停止・コマ送り・待ち時間）は GUI のキー操作で変える
+	pace := server.NewPace(*delay)
+
 	config := server.ServerConfig{
-		MapPath:    mapPath,
-		HotPort:    *hotPort,
-		CoolPort:   *coolPort,
-		DumpPath:   *dumpPath,
-		EnableDump: !*noDump,
-		BindAddr:   *bindAddr,
-		SnapshotCh: ch,
+		MapPath:     mapPath,
+		HotPort:     *hotPort,
+		CoolPort:    *coolPort,
+		DumpPath:    *dumpPath,
+		EnableDump:  !*noDump,
+		DumpFormat:  format,
+		BindAddr:    *bindAddr,
+		SnapshotCh:  ch,
+		ControlAddr: *controlAddr,
+		ViewerAddr:  *viewerAddr,
+		Pacer:       pace,
+		Recorder:    state,
 	}
 
 	srv, err := server.NewServer(config)
@@ -95,8 +181,6 @@ func main() {
 		log.Fatalf("Failed to create server: %v", err)
 	}
 
-	// 状態管理
-	state := &gui.GameState{}
 	go state.Run(ch)
 
 	// サーバーを goroutine で起動
@@ -126,7 +210,8 @@ func main() {
 	ebiten.SetWindowTitle("CHaser Server GUI")
 	ebiten.SetWindowResizingMode(ebiten.WindowResizingModeEnabled)
 
-	app := gui.NewApp(state, cancel)
+	app := gui.NewLiveApp(state, gui.NewLiveControl(pace), cancel)
+	disp.apply(app)
 	if err := ebiten.RunGame(app); err != nil {
 		log.Printf("GUI error: %v", err)
 	}
@@ -134,3 +219,85 @@ func main() {
 	// ウィンドウが閉じられたらサーバーも停止
 	cancel()
 }
+
+// runReplay はダンプファイルを読み込み、GUI で再生する
+func runReplay(path string, disp *display) {
+	g, err := dump.Load(path)
+	if err != nil {
+		log.Fatalf("Failed to load dump: %v", err)
+	}
+	// 多少壊れたダンプでも観られるよう、検証エラーは警告に留める
+	if err := g.Validate(); err != nil {
+		log.Printf("Warning: dump has inconsistencies:\n%v", err)
+	}
+	log.Printf("Replay: %s vs %s on %s (%d frames)", g.HotName, g.CoolName, g.MapName, len(g.Frames))
+
+	ebiten.SetWindowSize(gui.ScreenWidth, gui.ScreenHeight)
+	ebiten.SetWindowTitle("CHaser Replay - " + filepath.Base(path))
+	ebiten.SetWindowResizingMode(ebiten.WindowResizingModeEnabled)
+
+	replay := gui.NewReplay(&gui.GameState{}, g.Snapshots())
+	// 停止すべきサーバーはないため cancel は何もしない
+	app := gui.NewReplayApp(replay, func() {})
+	disp.apply(app)
+	if err := ebiten.RunGame(app); err != nil {
+		log.Printf("GUI error: %v", err)
+	}
+}
+
+// loadThemes は組み込みテーマと dir の独自テーマを読み込む
+// 読み込めないテーマは警告を出して飛ばす（既定のディレクトリがない場合は何も出さない）
+func loadThemes(dir string) []*theme.Theme {
+	themes, err := theme.All(dir)
+	if err != nil {
+		log.Printf("Warning: some themes could not be loaded:\n%v", err)
+	}
+	for _, t := range themes {
+		if t.Dir != "" {
+			log.Printf("Theme: %s (%s)", t.Name, t.Dir)
+		}
+	}
+	return themes
+}
+
+// loadHeatmap は patterns（カンマ区切りのパスまたは glob）のダンプを積み上げたヒートマップを作る
+// 盤面の大きさは最初のダンプに合わせ、大きさの違うダンプや読めないダンプはファイル名付きのエラーにする
+func loadHeatmap(patterns string) (*stats.Heatmap, error) {
+	var paths []string
+	for _, p := range strings.Split(patterns, ",") {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+		matches, err := filepath.Glob(p)
+		if err != nil {
+			return nil, fmt.Errorf("heatmap: bad pattern %q: %w", p, err)
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("heatmap: no dump files match %q", p)
+		}
+		paths = append(paths, matches...)
+	}
+
+	var h *stats.Heatmap
+	for _, path := range paths {
+		g, err := dump.Load(path)
+		if err != nil {
+			return nil, fmt.Errorf("heatmap: %s: %w", path, err)
+		}
+		snaps := g.Snapshots()
+		if len(snaps) == 0 {
+			continue
+		}
+		if h == nil {
+			h = stats.NewHeatmap(snaps[0].Width, snaps[0].Height)
+		}
+		if err := h.Add(snaps); err != nil {
+			return nil, fmt.Errorf("heatmap: %s: %w", path, err)
+		}
+	}
+	if h == nil {
+		return nil, fmt.Errorf("heatmap: no games in %q", patterns)
+	}
+	return h, nil
+}
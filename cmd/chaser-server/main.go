@@ -2,18 +2,26 @@ package main
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"log"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/kqnade/CHaserGo/mapgen"
+	"github.com/kqnade/CHaserGo/render"
 	"github.com/kqnade/CHaserGo/server"
+	"github.com/kqnade/CHaserGo/theme"
 )
 
 const version = "0.2.0"
 
+// maxRetryDelay は -repeat で対戦が失敗したときに次の対戦まで待つ時間の上限
+const maxRetryDelay = 30 * time.Second
+
 func main() {
 	// コマンドライン引数の定義
 	hotPort := flag.Int("f", 2009, "Hot (first) player port")
@@ -25,8 +33,22 @@ func main() {
 	dumpPath := flag.String("d", "./chaser.dump", "Dump file output path")
 	flag.StringVar(dumpPath, "dump-path", "./chaser.dump", "Dump file output path")
 
+	dumpFormat := flag.String("dump-format", "viewer", "Dump file format: viewer (CHaserViewer) or jsonl (per half-turn actions)")
+
 	bindAddr := flag.String("bind", "127.0.0.1", "Address to bind (use 0.0.0.0 to expose to network)")
 
+	controlAddr := flag.String("control", "", "Control HTTP API address (e.g. 127.0.0.1:8080; empty to disable)")
+
+	viewerAddr := flag.String("viewer", "", "Web viewer address (e.g. 0.0.0.0:8081 to let audiences watch; empty to disable)")
+
+	repeat := flag.Bool("repeat", false, "Run games back to back (next map can be selected via the control API)")
+
+	gifPath := flag.String("gif", "", "Also write an animated GIF of the game to this file (numbered with -repeat)")
+	gifTheme := flag.String("gif-theme", "Light", "Tile theme of the GIF: Light, Heavy, Jewel or a theme under -theme-dir")
+	themeDir := flag.String("theme-dir", theme.DefaultDir, "Directory of custom themes for -gif-theme (one subdirectory per theme)")
+
+	delay := flag.Duration("delay", 0, "Wait before each half-turn (e.g. 200ms) to slow the game down for spectators")
+
 	noDump := flag.Bool("nd", false, "Disable dump output")
 	flag.BoolVar(noDump, "non-dump", false, "Disable dump output")
 
@@ -44,6 +66,8 @@ func main() {
 		fmt.Fprintf(os.Stderr, "  %s\n", filepath.Base(os.Args[0]))
 		fmt.Fprintf(os.Stderr, "  %s map.txt\n", filepath.Base(os.Args[0]))
 		fmt.Fprintf(os.Stderr, "  %s -f 3000 -s 3001 -d game.dump map.txt\n", filepath.Base(os.Args[0]))
+		fmt.Fprintf(os.Stderr, "  %s -control :8080 -repeat map.txt\n", filepath.Base(os.Args[0]))
+		fmt.Fprintf(os.Stderr, "  %s -gif game.gif map.txt\n", filepath.Base(os.Args[0]))
 	}
 
 	flag.Parse()
@@ -54,6 +78,22 @@ func main() {
 		return
 	}
 
+	format, err := server.ParseDumpFormat(*dumpFormat)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	var renderer *render.Renderer
+	if *gifPath != "" {
+		th, err := theme.Named(*themeDir, *gifTheme)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		renderer = render.New(th, 0)
+	}
+
 	// マップファイルの決定（省略時は自動生成）
 	var mapPath string
 	if flag.NArg() >= 1 {
@@ -99,35 +139,112 @@ func main() {
 
 	// サーバー設定
 	config := server.ServerConfig{
-		MapPath:    mapPath,
-		HotPort:    *hotPort,
-		CoolPort:   *coolPort,
-		DumpPath:   *dumpPath,
-		EnableDump: !*noDump,
-		BindAddr:   *bindAddr,
+		MapPath:     mapPath,
+		HotPort:     *hotPort,
+		CoolPort:    *coolPort,
+		DumpPath:    *dumpPath,
+		EnableDump:  !*noDump,
+		DumpFormat:  format,
+		BindAddr:    *bindAddr,
+		ViewerAddr:  *viewerAddr,
+		KeepHistory: *gifPath != "", // 対戦全体のスナップショットは GIF の書き出しにだけ使う
 	}
-
-	// サーバー作成
-	srv, err := server.NewServer(config)
-	if err != nil {
-		log.Fatalf("Failed to create server: %v", err)
+	if *delay > 0 {
+		config.Pacer = server.NewPace(*delay)
 	}
 
-	// サーバー起動
-	log.Println("=== CHaser Server ===")
-	log.Printf("Map: %s", mapPath)
-	log.Printf("Hot port: %d", *hotPort)
-	log.Printf("Cool port: %d", *coolPort)
-	if *noDump {
-		log.Println("Dump: disabled")
-	} else {
-		log.Printf("Dump: %s", *dumpPath)
+	// 制御APIは対戦ごとの Server ではなく Controller が持ち、対戦の合間も開いておく
+	var ctl *server.Controller
+	if *controlAddr != "" {
+		ctl = server.NewController()
+		addr, stop, err := ctl.Serve(*controlAddr)
+		if err != nil {
+			log.Fatalf("Failed to start control API: %v", err)
+		}
+		defer stop()
+		log.Printf("Control API: http://%s", addr)
 	}
-	log.Println("=====================")
 
-	if err := srv.Start(context.Background()); err != nil {
-		log.Fatalf("Server error: %v", err)
+	// 連続対戦で Start が失敗し続ける場合（ポートが使用中など）に備え、再試行の間隔を空ける
+	played := false
+	var retryDelay time.Duration
+	for game := 1; ; game++ {
+		if *repeat {
+			config.DumpPath = numberedPath(*dumpPath, game)
+		}
+
+		// サーバー作成
+		srv, err := server.NewServer(config)
+		if err != nil {
+			log.Fatalf("Failed to create server: %v", err)
+		}
+		if ctl != nil {
+			ctl.SetServer(srv)
+		}
+
+		// サーバー起動
+		log.Println("=== CHaser Server ===")
+		if *repeat {
+			log.Printf("Game: %d", game)
+		}
+		log.Printf("Map: %s", config.MapPath)
+		log.Printf("Hot port: %d", *hotPort)
+		log.Printf("Cool port: %d", *coolPort)
+		if *noDump {
+			log.Println("Dump: disabled")
+		} else {
+			log.Printf("Dump: %s", config.DumpPath)
+		}
+		log.Println("=====================")
+
+		err = srv.Start(context.Background())
+		if renderer != nil {
+			saveGIF(renderer, srv, *gifPath, game, *repeat)
+		}
+		if !*repeat {
+			if err != nil {
+				log.Fatalf("Server error: %v", err)
+			}
+			break
+		}
+		if err != nil && !errors.Is(err, server.ErrAborted) {
+			if !played {
+				// 1度も対戦できていなければ、次の対戦も同じ理由で失敗する
+				log.Fatalf("Server error: %v", err)
+			}
+			retryDelay = min(max(2*retryDelay, time.Second), maxRetryDelay)
+			log.Printf("Server error: %v (next game in %v)", err, retryDelay)
+			time.Sleep(retryDelay)
+		} else {
+			played = true
+			retryDelay = 0
+		}
+
+		// 制御APIで次のマップが選択されていれば切り替える
+		if ctl != nil {
+			if next := ctl.TakeNextMap(); next != "" {
+				config.MapPath = next
+			}
+		}
 	}
 
 	log.Println("Server finished successfully")
 }
+
+// saveGIF は対戦のスナップショット履歴をアニメーション GIF に書き出す
+func saveGIF(r *render.Renderer, srv *server.Server, path string, game int, repeat bool) {
+	if repeat {
+		path = numberedPath(path, game)
+	}
+	if err := r.SaveGIF(path, srv.History(), render.GIFOptions{}); err != nil {
+		log.Printf("Warning: failed to write GIF: %v", err)
+		return
+	}
+	log.Printf("GIF: %s", path)
+}
+
+// numberedPath は連続対戦用にファイル名へゲーム番号を付与する（chaser.dump → chaser-001.dump）
+func numberedPath(path string, n int) string {
+	ext := filepath.Ext(path)
+	return fmt.Sprintf("%s-%03d%s", strings.TrimSuffix(path, ext), n, ext)
+}
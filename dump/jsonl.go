@@ -0,0 +1,148 @@
+package dump
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/kqnade/CHaserGo/server"
+)
+
+// jsonlRecord は JSON Lines ダンプの1行。type により使われるフィールドが異なる
+type jsonlRecord struct {
+	Type string `json:"type"`
+
+	// type = "start"
+	HotName  string              `json:"hotName"`
+	CoolName string              `json:"coolName"`
+	Map      string              `json:"map"`
+	MaxTurns int                 `json:"maxTurns"`
+	Width    int                 `json:"width"`
+	Height   int                 `json:"height"`
+	Cells    [][]server.CellType `json:"cells"`
+
+	// type = "result"
+	Draw   bool   `json:"draw"`
+	Winner string `json:"winner"`
+	Reason string `json:"reason"`
+}
+
+// jsonlStartPositions は start レコードの初期位置
+// ActionRecord の hot/cool（PlayerState）と名前が重なるため別に読む
+type jsonlStartPositions struct {
+	Hot  server.Position `json:"hot"`
+	Cool server.Position `json:"cool"`
+}
+
+// readJSONL は server.DumpFormatJSONL 形式のダンプを読む
+func readJSONL(r io.Reader) (*Game, error) {
+	lr := newLineReader(r)
+	g := &Game{HalfTurns: true}
+	started := false
+
+	for {
+		text, ok := lr.next()
+		if !ok {
+			if err := lr.scanner.Err(); err != nil {
+				return nil, err
+			}
+			if !started {
+				return nil, &SyntaxError{Line: lr.line + 1, Msg: "unexpected end of file, want start record"}
+			}
+			return g, nil
+		}
+		if text == "" {
+			continue
+		}
+
+		var rec jsonlRecord
+		if err := json.Unmarshal([]byte(text), &rec); err != nil {
+			return nil, lr.errorf("invalid JSON: %v", err)
+		}
+		if !started && rec.Type != "start" {
+			return nil, lr.errorf("first record is %q, want start", rec.Type)
+		}
+		if g.Result != nil {
+			return nil, lr.errorf("%q record after result", rec.Type)
+		}
+
+		switch rec.Type {
+		case "start":
+			if started {
+				return nil, lr.errorf("duplicate start record")
+			}
+			if err := readJSONLStart(lr, g, &rec, text); err != nil {
+				return nil, err
+			}
+			started = true
+		case "action":
+			var action server.ActionRecord
+			if err := json.Unmarshal([]byte(text), &action); err != nil {
+				return nil, lr.errorf("invalid action record: %v", err)
+			}
+			frame, err := applyAction(lr, g, &action)
+			if err != nil {
+				return nil, err
+			}
+			g.Frames = append(g.Frames, frame)
+		case "result":
+			g.Result = &Result{Draw: rec.Draw, Reason: rec.Reason}
+			if !rec.Draw {
+				g.Result.Winner = rec.Winner
+			}
+		default:
+			return nil, lr.errorf("unknown record type %q", rec.Type)
+		}
+	}
+}
+
+// readJSONLStart は start レコードからヘッダーと初期盤面を設定する
+func readJSONLStart(lr *lineReader, g *Game, rec *jsonlRecord, text string) error {
+	var pos jsonlStartPositions
+	if err := json.Unmarshal([]byte(text), &pos); err != nil {
+		return lr.errorf("invalid start record: %v", err)
+	}
+	if rec.Width <= 0 || rec.Height <= 0 {
+		return lr.errorf("invalid board size %dx%d", rec.Width, rec.Height)
+	}
+	if len(rec.Cells) != rec.Height {
+		return lr.errorf("cells: got %d rows, want %d", len(rec.Cells), rec.Height)
+	}
+
+	g.HotName, g.CoolName = rec.HotName, rec.CoolName
+	g.MapName, g.MaxTurns = rec.Map, rec.MaxTurns
+	g.Width, g.Height = rec.Width, rec.Height
+	g.Initial = Frame{Cells: make([]server.CellType, 0, g.Width*g.Height), Hot: pos.Hot, Cool: pos.Cool}
+	for y, row := range rec.Cells {
+		if len(row) != g.Width {
+			return lr.errorf("cells row %d: got %d values, want %d", y, len(row), g.Width)
+		}
+		g.Initial.Cells = append(g.Initial.Cells, row...)
+	}
+	return nil
+}
+
+// applyAction は直前のフレームに action の盤面差分を適用した新しいフレームを返す
+func applyAction(lr *lineReader, g *Game, action *server.ActionRecord) (Frame, error) {
+	prev := &g.Initial
+	if len(g.Frames) > 0 {
+		prev = &g.Frames[len(g.Frames)-1]
+	}
+
+	frame := Frame{
+		Turn:      action.Turn,
+		Cells:     append([]server.CellType(nil), prev.Cells...),
+		Hot:       server.Position{X: action.Hot.X, Y: action.Hot.Y},
+		Cool:      server.Position{X: action.Cool.X, Y: action.Cool.Y},
+		HotItems:  action.Hot.Items,
+		CoolItems: action.Cool.Items,
+		Step:      action.Step,
+		Action:    action,
+	}
+	for _, c := range action.Changes {
+		if c.X < 0 || c.X >= g.Width || c.Y < 0 || c.Y >= g.Height {
+			return Frame{}, lr.errorf("change (%d,%d) is out of bounds", c.Y, c.X)
+		}
+		frame.Cells[c.Y*g.Width+c.X] = c.To
+	}
+	return frame, nil
+}
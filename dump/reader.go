@@ -0,0 +1,381 @@
+// Package dump は server.DumpSystem が出力する CHaserViewer 形式のダンプを読み込む。
+//
+// ダンプは次の順に行が並ぶ:
+//
+//	<hot名>,<cool名>
+//	<マップ名>                  ← マップファイルの N 行
+//	<ターン数>                  ← T 行
+//	<高さ>,<幅>                 ← S 行
+//	<y>,<x>,<値>  ...           ← D 行（複数）
+//	<y>,<x>                     ← H 行（Hot の初期位置）
+//	<y>,<x>                     ← C 行（Cool の初期位置）
+//	0,0                         ← 初期スコア
+//	（各ターン終了時のフレーム）
+//	<値>,<値>,...  × 高さ       ← 盤面
+//	<y>,<x>                     ← Hot の位置
+//	<y>,<x>                     ← Cool の位置
+//	<hotアイテム>,<coolアイテム>
+//	gameend
+//	<勝者名>,win,<理由> または draw,draw,<理由>
+//	alive,<true|false>,<true|false>  ← 終了時の Hot, Cool の生存（古いダンプにはない）
+//
+// server.DumpFormatJSONL で出力された JSON Lines 形式のダンプも読み込める。
+// この場合 Frames は半ターンごとのフレームになり、各 Frame.Action に行動の記録が入る。
+package dump
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/kqnade/CHaserGo/server"
+)
+
+// Game はダンプ1件分の対戦記録
+type Game struct {
+	HotName  string
+	CoolName string
+	MapName  string
+	MaxTurns int
+	Width    int
+	Height   int
+
+	// Initial はゲーム開始時の盤面（Turn 0）
+	Initial Frame
+	// Frames は各ターン終了時の盤面（Frames[i].Turn == i+1）
+	// HalfTurns が true の場合は半ターンごとの盤面（Turn は行動時のターン番号）
+	Frames []Frame
+	// HalfTurns は JSON Lines 形式から読み込んだことを示す
+	HalfTurns bool
+	// Result は gameend 以降の勝敗情報（途中で途切れたダンプでは nil）
+	Result *Result
+}
+
+// Frame はある時点の盤面・位置・アイテム数
+type Frame struct {
+	Turn      int
+	Cells     []server.CellType // Cells[y*Width+x]
+	Hot       server.Position
+	Cool      server.Position
+	HotItems  int
+	CoolItems int
+
+	// Step と Action は JSON Lines 形式のフレームのみ設定される
+	Step   server.TurnStep
+	Action *server.ActionRecord
+}
+
+// Result は勝敗情報
+type Result struct {
+	Draw   bool
+	Winner string // Draw の場合は空
+	Reason string
+
+	// HasAlive は終了時の生存がダンプに記録されていたことを示す
+	HasAlive  bool
+	HotAlive  bool
+	CoolAlive bool
+}
+
+// SyntaxError はダンプの構文エラー（Line は1始まりの行番号）
+type SyntaxError struct {
+	Line int
+	Msg  string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("dump line %d: %s", e.Line, e.Msg)
+}
+
+// Load はファイルからダンプを読み込む
+func Load(path string) (*Game, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open dump file: %w", err)
+	}
+	defer file.Close()
+	return Read(file)
+}
+
+// lineReader は行番号を追跡しながら1行ずつ読む
+type lineReader struct {
+	scanner *bufio.Scanner
+	line    int
+}
+
+func newLineReader(r io.Reader) *lineReader {
+	scanner := bufio.NewScanner(r)
+	// JSON Lines の start レコードは盤面全体を含むため長くなりうる
+	scanner.Buffer(nil, 4*1024*1024)
+	return &lineReader{scanner: scanner}
+}
+
+// next は次の行を返す（EOF の場合 ok=false）
+func (lr *lineReader) next() (text string, ok bool) {
+	if !lr.scanner.Scan() {
+		return "", false
+	}
+	lr.line++
+	return strings.TrimRight(lr.scanner.Text(), "\r"), true
+}
+
+// must は次の行を返し、EOF の場合は what を含む構文エラーを返す
+func (lr *lineReader) must(what string) (string, error) {
+	text, ok := lr.next()
+	if !ok {
+		if err := lr.scanner.Err(); err != nil {
+			return "", err
+		}
+		return "", &SyntaxError{Line: lr.line + 1, Msg: "unexpected end of file, want " + what}
+	}
+	return text, nil
+}
+
+func (lr *lineReader) errorf(format string, args ...any) error {
+	return &SyntaxError{Line: lr.line, Msg: fmt.Sprintf(format, args...)}
+}
+
+// ints はカンマ区切りの整数列をパースする（want >= 0 の場合は個数も検証する）
+func (lr *lineReader) ints(text string, want int, what string) ([]int, error) {
+	parts := strings.Split(text, ",")
+	if want >= 0 && len(parts) != want {
+		return nil, lr.errorf("%s: got %d values, want %d", what, len(parts), want)
+	}
+	values := make([]int, len(parts))
+	for i, p := range parts {
+		v, err := strconv.Atoi(strings.TrimSpace(p))
+		if err != nil {
+			return nil, lr.errorf("%s: invalid number %q", what, p)
+		}
+		values[i] = v
+	}
+	return values, nil
+}
+
+// position は "y,x" 形式の行を読む
+func (lr *lineReader) position(what string) (server.Position, error) {
+	text, err := lr.must(what)
+	if err != nil {
+		return server.Position{}, err
+	}
+	v, err := lr.ints(text, 2, what)
+	if err != nil {
+		return server.Position{}, err
+	}
+	return server.Position{Y: v[0], X: v[1]}, nil
+}
+
+// Read はダンプを読み込む。構文のみを検査するため、内容の整合性は Validate で確認する
+// 先頭が '{' の場合は JSON Lines 形式として読む
+func Read(r io.Reader) (*Game, error) {
+	br := bufio.NewReader(r)
+	if head, err := br.Peek(1); err == nil && head[0] == '{' {
+		return readJSONL(br)
+	}
+
+	lr := newLineReader(br)
+	g := &Game{}
+
+	names, err := lr.must("player names")
+	if err != nil {
+		return nil, err
+	}
+	hot, cool, ok := strings.Cut(names, ",")
+	if !ok {
+		return nil, lr.errorf("player names: want \"<hot>,<cool>\", got %q", names)
+	}
+	g.HotName, g.CoolName = hot, cool
+
+	if err := readHeader(lr, g); err != nil {
+		return nil, err
+	}
+
+	for {
+		text, ok := lr.next()
+		if !ok {
+			// gameend のないダンプ（サーバーが途中で停止した場合など）
+			return g, lr.scanner.Err()
+		}
+		if text == "" {
+			continue
+		}
+		if text == "gameend" {
+			result, err := readResult(lr)
+			if err != nil {
+				return nil, err
+			}
+			if err := readAlive(lr, result); err != nil {
+				return nil, err
+			}
+			g.Result = result
+			return g, nil
+		}
+		frame, err := readFrame(lr, g, text)
+		if err != nil {
+			return nil, err
+		}
+		frame.Turn = len(g.Frames) + 1
+		g.Frames = append(g.Frames, frame)
+	}
+}
+
+// readHeader はマップファイル由来の行と初期スコアを読む
+func readHeader(lr *lineReader, g *Game) error {
+	var err error
+	if g.MapName, err = lr.must("map name"); err != nil {
+		return err
+	}
+
+	text, err := lr.must("turn count")
+	if err != nil {
+		return err
+	}
+	turns, err := lr.ints(text, 1, "turn count")
+	if err != nil {
+		return err
+	}
+	g.MaxTurns = turns[0]
+
+	text, err = lr.must("board size")
+	if err != nil {
+		return err
+	}
+	size, err := lr.ints(text, 2, "board size")
+	if err != nil {
+		return err
+	}
+	g.Height, g.Width = size[0], size[1]
+	if g.Width <= 0 || g.Height <= 0 {
+		return lr.errorf("invalid board size %dx%d", g.Width, g.Height)
+	}
+
+	// D 行（y,x,値）は H 行（y,x）が現れるまで続く
+	g.Initial.Cells = make([]server.CellType, g.Width*g.Height)
+	var hot []int
+	for hot == nil {
+		text, err := lr.must("map data or hot position")
+		if err != nil {
+			return err
+		}
+		v, err := lr.ints(text, -1, "map data")
+		if err != nil {
+			return err
+		}
+		switch len(v) {
+		case 3:
+			y, x := v[0], v[1]
+			if y < 0 || y >= g.Height || x < 0 || x >= g.Width {
+				return lr.errorf("map data (%d,%d) is out of bounds", y, x)
+			}
+			g.Initial.Cells[y*g.Width+x] = server.CellType(v[2])
+		case 2:
+			hot = v
+		default:
+			return lr.errorf("map data: got %d values, want 3", len(v))
+		}
+	}
+	g.Initial.Hot = server.Position{Y: hot[0], X: hot[1]}
+
+	if g.Initial.Cool, err = lr.position("cool position"); err != nil {
+		return err
+	}
+
+	text, err = lr.must("initial score")
+	if err != nil {
+		return err
+	}
+	score, err := lr.ints(text, 2, "initial score")
+	if err != nil {
+		return err
+	}
+	g.Initial.HotItems, g.Initial.CoolItems = score[0], score[1]
+	return nil
+}
+
+// readFrame は first を盤面の1行目として1ターン分のフレームを読む
+func readFrame(lr *lineReader, g *Game, first string) (Frame, error) {
+	frame := Frame{Cells: make([]server.CellType, 0, g.Width*g.Height)}
+	text := first
+	for y := 0; y < g.Height; y++ {
+		if y > 0 {
+			var err error
+			if text, err = lr.must(fmt.Sprintf("board row %d", y)); err != nil {
+				return Frame{}, err
+			}
+		}
+		row, err := lr.ints(text, g.Width, fmt.Sprintf("board row %d", y))
+		if err != nil {
+			return Frame{}, err
+		}
+		for _, v := range row {
+			frame.Cells = append(frame.Cells, server.CellType(v))
+		}
+	}
+
+	var err error
+	if frame.Hot, err = lr.position("hot position"); err != nil {
+		return Frame{}, err
+	}
+	if frame.Cool, err = lr.position("cool position"); err != nil {
+		return Frame{}, err
+	}
+
+	text, err = lr.must("item counts")
+	if err != nil {
+		return Frame{}, err
+	}
+	items, err := lr.ints(text, 2, "item counts")
+	if err != nil {
+		return Frame{}, err
+	}
+	frame.HotItems, frame.CoolItems = items[0], items[1]
+	return frame, nil
+}
+
+// readResult は gameend の次の行を読む
+func readResult(lr *lineReader) (*Result, error) {
+	text, err := lr.must("game result")
+	if err != nil {
+		return nil, err
+	}
+	parts := strings.SplitN(text, ",", 3)
+	if len(parts) != 3 {
+		return nil, lr.errorf("game result: want \"<winner>,win,<reason>\" or \"draw,draw,<reason>\", got %q", text)
+	}
+	switch {
+	case parts[0] == "draw" && parts[1] == "draw":
+		return &Result{Draw: true, Reason: parts[2]}, nil
+	case parts[1] == "win":
+		return &Result{Winner: parts[0], Reason: parts[2]}, nil
+	default:
+		return nil, lr.errorf("game result: unknown outcome %q", parts[1])
+	}
+}
+
+// readAlive は勝敗の行に続く生存の行があれば読む（ない場合は何もしない）
+func readAlive(lr *lineReader, r *Result) error {
+	text, ok := lr.next()
+	if !ok {
+		return lr.scanner.Err()
+	}
+	rest, ok := strings.CutPrefix(text, "alive,")
+	if !ok {
+		return nil
+	}
+	hot, cool, ok := strings.Cut(rest, ",")
+	if !ok {
+		return lr.errorf("alive: want \"alive,<hot>,<cool>\", got %q", text)
+	}
+	var err error
+	if r.HotAlive, err = strconv.ParseBool(hot); err != nil {
+		return lr.errorf("alive: invalid value %q", hot)
+	}
+	if r.CoolAlive, err = strconv.ParseBool(cool); err != nil {
+		return lr.errorf("alive: invalid value %q", cool)
+	}
+	r.HasAlive = true
+	return nil
+}
@@ -0,0 +1,414 @@
+package dump
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kqnade/CHaserGo/server"
+)
+
+const testMap = "../server/testdata/test.map"
+
+// writeTestDump は server.DumpSystem で2ターン分のダンプを書き出し、そのパスを返す
+//
+// Turn 1: Hot が右へ移動、Cool が上にブロックを置く
+// Turn 2: Hot が下へ移動してアイテムを取得
+func writeTestDump(t *testing.T, finish bool) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "game.dump")
+	d, err := server.NewDumpSystem(path, testMap, true)
+	if err != nil {
+		t.Fatalf("NewDumpSystem: %v", err)
+	}
+	board, err := server.NewBoard(testMap)
+	if err != nil {
+		t.Fatalf("NewBoard: %v", err)
+	}
+	board.Hot.Name, board.Cool.Name = "ホット", "Cool"
+
+	if err := d.SetNames(board.Hot.Name, board.Cool.Name); err != nil {
+		t.Fatalf("SetNames: %v", err)
+	}
+
+	_ = board.Walk(board.Hot, server.Right)
+	board.Put(board.Cool.Position, server.Up)
+	board.IncrementTurn()
+	if err := d.Action(board); err != nil {
+		t.Fatalf("Action: %v", err)
+	}
+
+	_ = board.Walk(board.Hot, server.Down)
+	board.IncrementTurn()
+	if err := d.Action(board); err != nil {
+		t.Fatalf("Action: %v", err)
+	}
+
+	if finish {
+		if err := d.Result(board.Hot, board.Cool, "hot has more items"); err != nil {
+			t.Fatalf("Result: %v", err)
+		}
+	}
+	if err := d.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	g, err := Load(writeTestDump(t, true))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	if g.HotName != "ホット" || g.CoolName != "Cool" {
+		t.Errorf("names = %q,%q, want ホット,Cool", g.HotName, g.CoolName)
+	}
+	if g.MapName != "test" || g.MaxTurns != 100 {
+		t.Errorf("map = %q (%d turns), want test (100 turns)", g.MapName, g.MaxTurns)
+	}
+	if g.Width != 5 || g.Height != 5 {
+		t.Errorf("size = %dx%d, want 5x5", g.Width, g.Height)
+	}
+
+	if g.Initial.Hot != (server.Position{Y: 1, X: 1}) || g.Initial.Cool != (server.Position{Y: 3, X: 3}) {
+		t.Errorf("initial positions = %v,%v, want {1,1},{3,3}", g.Initial.Hot, g.Initial.Cool)
+	}
+	if c := g.Initial.Cells[2*5+2]; c != server.Item {
+		t.Errorf("initial (2,2) = %v, want Item", c)
+	}
+
+	if len(g.Frames) != 2 {
+		t.Fatalf("len(Frames) = %d, want 2", len(g.Frames))
+	}
+	f1, f2 := g.Frames[0], g.Frames[1]
+	if f1.Turn != 1 || f2.Turn != 2 {
+		t.Errorf("frame turns = %d,%d, want 1,2", f1.Turn, f2.Turn)
+	}
+	if f1.Hot != (server.Position{Y: 1, X: 2}) {
+		t.Errorf("turn 1 hot = %v, want {2,1}", f1.Hot)
+	}
+	if c := f1.Cells[2*5+3]; c != server.Wall {
+		t.Errorf("turn 1 (2,3) = %v, want Wall (put)", c)
+	}
+	if f2.HotItems != 1 || f2.Cells[2*5+2] != server.Empty {
+		t.Errorf("turn 2 hot items = %d, (2,2) = %v, want 1, Empty", f2.HotItems, f2.Cells[2*5+2])
+	}
+
+	if g.Result == nil || g.Result.Draw || g.Result.Winner != "ホット" || g.Result.Reason != "hot has more items" {
+		t.Errorf("Result = %+v, want ホット win", g.Result)
+	}
+
+	if err := g.Validate(); err != nil {
+		t.Errorf("Validate: %v", err)
+	}
+}
+
+func TestLoadUnfinished(t *testing.T) {
+	g, err := Load(writeTestDump(t, false))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if g.Result != nil {
+		t.Errorf("Result = %+v, want nil", g.Result)
+	}
+	if len(g.Frames) != 2 {
+		t.Errorf("len(Frames) = %d, want 2", len(g.Frames))
+	}
+}
+
+func TestReadSyntaxErrors(t *testing.T) {
+	header := "a,b\ntest\n100\n2,2\n0,0,0\n0,1,0\n1,0,0\n1,1,0\n0,0\n1,1\n0,0\n"
+
+	tests := []struct {
+		name  string
+		input string
+		line  int
+	}{
+		{"名前行なし", "", 1},
+		{"名前にカンマなし", "ab\n", 1},
+		{"ターン数が数値でない", "a,b\ntest\nx\n", 3},
+		{"盤面外のD行", "a,b\ntest\n100\n2,2\n5,5,2\n", 5},
+		{"行の列数不一致", header + "0,0,0\n", 12},
+		{"フレームの途中で終了", header + "0,0\n0,0\n", 14},
+		{"不明な勝敗", header + "gameend\na,lose,x\n", 13},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Read(strings.NewReader(tt.input))
+			var se *SyntaxError
+			if !errors.As(err, &se) {
+				t.Fatalf("Read error = %v, want *SyntaxError", err)
+			}
+			if se.Line != tt.line {
+				t.Errorf("line = %d, want %d (%v)", se.Line, tt.line, err)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	load := func(t *testing.T) *Game {
+		g, err := Load(writeTestDump(t, true))
+		if err != nil {
+			t.Fatalf("Load: %v", err)
+		}
+		return g
+	}
+
+	tests := []struct {
+		name   string
+		mutate func(g *Game)
+		want   string
+	}{
+		{"アイテム数の減少", func(g *Game) { g.Frames[0].HotItems = 2 }, "hot items decreased"},
+		{"アイテムの増加", func(g *Game) { g.Frames[1].Cells[1*5+3] = server.Item }, "in total"},
+		{"壁の消滅", func(g *Game) { g.Frames[1].Cells[0] = server.Empty }, "disappeared"},
+		{"壁の上のプレイヤー", func(g *Game) { g.Frames[0].Cool = server.Position{Y: 0, X: 0} }, "on a wall"},
+		{"壁の上へ移動", func(g *Game) { g.Frames[1].Cool = server.Position{Y: 2, X: 3} }, "on a wall"},
+		{"盤面外のプレイヤー", func(g *Game) { g.Frames[0].Hot = server.Position{Y: 9, X: 9} }, "out of bounds"},
+		{"不正なセル値", func(g *Game) { g.Frames[0].Cells[6] = 7 }, "invalid cell value"},
+		{"ターン数超過", func(g *Game) { g.MaxTurns = 1 }, "exceed max turns"},
+		{"不明な勝者", func(g *Game) { g.Result.Winner = "someone" }, "neither"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := load(t)
+			tt.mutate(g)
+			err := g.Validate()
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Validate = %v, want error containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateBuried(t *testing.T) {
+	g, err := Load(writeTestDump(t, true))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	// 2ターン目に Hot が Cool のマス (3,3) に壁を置いた場合（Cool の負け）
+	g.Frames[1].Cells[3*5+3] = server.Wall
+	if err := g.Validate(); err != nil {
+		t.Errorf("Validate with a wall put on cool: %v", err)
+	}
+}
+
+func TestSnapshots(t *testing.T) {
+	g, err := Load(writeTestDump(t, true))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	snaps := g.Snapshots()
+	if len(snaps) != 4 {
+		t.Fatalf("len(Snapshots) = %d, want 4 (initial, 2 turns, game over)", len(snaps))
+	}
+
+	wantKinds := []server.SnapshotKind{server.KindConnected, server.KindTurnEnd, server.KindTurnEnd, server.KindGameOver}
+	for i, s := range snaps {
+		if s.Kind != wantKinds[i] {
+			t.Errorf("snaps[%d].Kind = %v, want %v", i, s.Kind, wantKinds[i])
+		}
+		if s.Revision != uint64(i+1) {
+			t.Errorf("snaps[%d].Revision = %d, want %d", i, s.Revision, i+1)
+		}
+	}
+
+	end := snaps[3]
+	if end.Phase != server.PhaseGameOver || end.WinnerName != "ホット" {
+		t.Errorf("end = phase %v winner %q, want game over ホット", end.Phase, end.WinnerName)
+	}
+	if end.Turn != 2 || end.HotItems != 1 || end.HotX != 2 || end.HotY != 2 {
+		t.Errorf("end = turn %d items %d pos (%d,%d), want turn 2 items 1 pos (2,2)", end.Turn, end.HotItems, end.HotX, end.HotY)
+	}
+}
+
+func TestSnapshotsAlive(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "game.dump")
+	d, err := server.NewDumpSystem(path, testMap, true)
+	if err != nil {
+		t.Fatalf("NewDumpSystem: %v", err)
+	}
+	if err := d.SetNames("Hot", "Cool"); err != nil {
+		t.Fatalf("SetNames: %v", err)
+	}
+	// 理由の文言に依らず、記録された生存が使われる
+	if err := d.Result(&server.Character{Name: "Cool"}, nil, "something else"); err != nil {
+		t.Fatalf("Result: %v", err)
+	}
+	if err := d.Alive(false, true); err != nil {
+		t.Fatalf("Alive: %v", err)
+	}
+	if err := d.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	g, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if r := g.Result; r == nil || !r.HasAlive || r.HotAlive || !r.CoolAlive {
+		t.Fatalf("Result = %+v, want hot dead, cool alive", r)
+	}
+	snaps := g.Snapshots()
+	if end := snaps[len(snaps)-1]; end.HotAlive || !end.CoolAlive {
+		t.Errorf("end alive = %v,%v, want false,true", end.HotAlive, end.CoolAlive)
+	}
+
+	// 生存の記録がない古いダンプでは最後の盤面から推定する
+	g.Result.HasAlive = false
+	at := func(p server.Position) int { return p.Y*g.Width + p.X }
+	g.Initial.Cells[at(g.Initial.Hot)] = server.Wall // Hot は壁に埋まる
+	for _, p := range []server.Position{{Y: 2, X: 3}, {Y: 4, X: 3}, {Y: 3, X: 2}, {Y: 3, X: 4}} {
+		g.Initial.Cells[at(p)] = server.Wall // Cool (3,3) は四方を囲まれる
+	}
+	snaps = g.Snapshots()
+	if end := snaps[len(snaps)-1]; end.HotAlive || end.CoolAlive {
+		t.Errorf("end alive = %v,%v, want false,false", end.HotAlive, end.CoolAlive)
+	}
+
+	g.Initial.Cells[at(server.Position{Y: 2, X: 3})] = server.Empty
+	snaps = g.Snapshots()
+	if end := snaps[len(snaps)-1]; end.HotAlive || !end.CoolAlive {
+		t.Errorf("end alive = %v,%v, want false,true", end.HotAlive, end.CoolAlive)
+	}
+}
+
+// writeTestJSONL は server.DumpSystem で JSON Lines 形式のダンプを書き出す
+//
+// Turn 0: Hot が右へ移動、Cool が上にブロックを置く
+// Turn 1: Cool が左へ移動、Hot が上の壁に移動して死亡
+func writeTestJSONL(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "game.jsonl")
+	d, err := server.NewDumpSystemWithFormat(path, testMap, true, server.DumpFormatJSONL)
+	if err != nil {
+		t.Fatalf("NewDumpSystemWithFormat: %v", err)
+	}
+	if err := d.SetNames("ホット", "Cool"); err != nil {
+		t.Fatalf("SetNames: %v", err)
+	}
+
+	state := func(x, y int, alive bool) server.PlayerState { return server.PlayerState{X: x, Y: y, Alive: alive} }
+	records := []*server.ActionRecord{
+		{Turn: 0, Step: server.TurnStepFirst, Player: "hot", Command: "wr", Action: "wk", Direction: server.Right,
+			Hot: state(2, 1, true), Cool: state(3, 3, true)},
+		{Turn: 0, Step: server.TurnStepSecond, Player: "cool", Command: "pu", Action: "pt", Direction: server.Up,
+			Changes: []server.CellChange{{X: 3, Y: 2, From: server.Empty, To: server.Wall}},
+			Hot:     state(2, 1, true), Cool: state(3, 3, true)},
+		{Turn: 1, Step: server.TurnStepFirst, Player: "cool", Command: "wl", Action: "wk", Direction: server.Left,
+			Hot: state(2, 1, true), Cool: state(2, 3, true)},
+		{Turn: 1, Step: server.TurnStepSecond, Player: "hot", Command: "wu", Action: "wk", Direction: server.Up,
+			Hot: state(2, 1, false), Cool: state(2, 3, true), GameOver: true},
+	}
+	for _, rec := range records {
+		if err := d.HalfTurn(rec); err != nil {
+			t.Fatalf("HalfTurn: %v", err)
+		}
+	}
+	if err := d.Result(&server.Character{Name: "Cool"}, nil, "hot died"); err != nil {
+		t.Fatalf("Result: %v", err)
+	}
+	if err := d.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	return path
+}
+
+func TestLoadJSONL(t *testing.T) {
+	g, err := Load(writeTestJSONL(t))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if !g.HalfTurns || g.HotName != "ホット" || g.MapName != "test" || g.Width != 5 || g.MaxTurns != 100 {
+		t.Errorf("header = %+v", g)
+	}
+	if g.Initial.Cool != (server.Position{Y: 3, X: 3}) || g.Initial.Cells[2*5+2] != server.Item {
+		t.Errorf("initial = cool %v, (2,2) %v", g.Initial.Cool, g.Initial.Cells[2*5+2])
+	}
+	if len(g.Frames) != 4 {
+		t.Fatalf("len(Frames) = %d, want 4", len(g.Frames))
+	}
+	if f := g.Frames[1]; f.Cells[2*5+3] != server.Wall || f.Step != server.TurnStepSecond || f.Action.Command != "pu" {
+		t.Errorf("frame 1 = (2,3) %v step %v command %q, want Wall second pu", f.Cells[2*5+3], f.Step, f.Action.Command)
+	}
+	if f := g.Frames[2]; f.Turn != 1 || f.Cool != (server.Position{Y: 3, X: 2}) || f.Cells[2*5+3] != server.Wall {
+		t.Errorf("frame 2 = turn %d cool %v, want turn 1 cool {2,3} with wall kept", f.Turn, f.Cool)
+	}
+	if g.Result == nil || g.Result.Winner != "Cool" || g.Result.Reason != "hot died" {
+		t.Errorf("Result = %+v, want Cool wins", g.Result)
+	}
+	if err := g.Validate(); err != nil {
+		t.Errorf("Validate: %v", err)
+	}
+
+	snaps := g.Snapshots()
+	if len(snaps) != 6 {
+		t.Fatalf("len(Snapshots) = %d, want 6", len(snaps))
+	}
+	if snaps[1].Kind != server.KindActionEnd || snaps[2].Step != server.TurnStepSecond {
+		t.Errorf("half-turn snapshots = %v/%v, want action_end/second", snaps[1].Kind, snaps[2].Step)
+	}
+	if end := snaps[5]; end.HotAlive || !end.CoolAlive || end.WinnerName != "Cool" {
+		t.Errorf("end = hot alive %v cool alive %v winner %q", end.HotAlive, end.CoolAlive, end.WinnerName)
+	}
+
+	// 各プレイヤーの最後の行動を引き継ぐ
+	if snaps[0].HotAction != nil || snaps[1].CoolAction != nil {
+		t.Errorf("actions before the first move: hot %+v cool %+v", snaps[0].HotAction, snaps[1].CoolAction)
+	}
+	if a := snaps[2].HotAction; a == nil || a.Action != "wk" || a.From != (server.Position{X: 1, Y: 1}) {
+		t.Errorf("snaps[2].HotAction = %+v, want wk from {1,1}", a)
+	}
+	if a := snaps[2].CoolAction; a == nil || a.Action != "pt" || len(a.Cells) != 1 || a.Cells[0] != (server.Position{X: 3, Y: 2}) {
+		t.Errorf("snaps[2].CoolAction = %+v, want pt at {3,2}", a)
+	}
+	if a := snaps[3].CoolAction; a == nil || a.From != (server.Position{X: 3, Y: 3}) || a.Direction != server.Left {
+		t.Errorf("snaps[3].CoolAction = %+v, want wk left from {3,3}", a)
+	}
+	if snaps[5].HotAction != snaps[4].HotAction {
+		t.Error("game over snapshot does not keep the last action")
+	}
+
+	// 記録された応答から各プレイヤーが知り得た盤面
+	if snaps[1].HotView == nil || snaps[1].CoolView == nil {
+		t.Fatal("half-turn snapshots have no player views")
+	}
+	if _, turn := snaps[1].HotView.At(1, 1); turn != 0 {
+		t.Errorf("hot view of its start cell: turn %d, want 0", turn)
+	}
+	if _, turn := snaps[1].CoolView.At(3, 3); turn != -1 {
+		t.Errorf("cool view before its first action: turn %d, want -1", turn)
+	}
+}
+
+func TestReadJSONLErrors(t *testing.T) {
+	start := `{"type":"start","hotName":"a","coolName":"b","map":"m","maxTurns":10,"width":2,"height":1,"cells":[[0,0]],"hot":{"x":0,"y":0},"cool":{"x":1,"y":0}}` + "\n"
+
+	tests := []struct {
+		name  string
+		input string
+		line  int
+	}{
+		{"start がない", `{"type":"action"}` + "\n", 1},
+		{"不正な JSON", start + "{\n", 2},
+		{"行の列数不一致", `{"type":"start","width":3,"height":1,"cells":[[0,0]]}` + "\n", 1},
+		{"盤面外の差分", start + `{"type":"action","changes":[{"x":5,"y":0,"from":0,"to":2}]}` + "\n", 2},
+		{"不明な種別", start + `{"type":"chat"}` + "\n", 2},
+		{"result の後のレコード", start + `{"type":"result","draw":true}` + "\n" + `{"type":"action"}` + "\n", 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Read(strings.NewReader(tt.input))
+			var se *SyntaxError
+			if !errors.As(err, &se) {
+				t.Fatalf("Read error = %v, want *SyntaxError", err)
+			}
+			if se.Line != tt.line {
+				t.Errorf("line = %d, want %d (%v)", se.Line, tt.line, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package dump
+
+import (
+	"github.com/kqnade/CHaserGo/server"
+)
+
+// Snapshots はダンプをリプレイ用の BoardSnapshot 列に変換する
+//
+// 先頭は初期盤面（KindConnected）、続いて各ターン終了時（KindTurnEnd）、
+// Result があれば最後に勝敗を含むスナップショット（KindGameOver）を置く。
+// 半ターン単位のダンプでは各フレームが KindActionEnd になる。
+// Revision は 1 から始まる通し番号になる。
+// 半ターン単位のダンプでは各プレイヤーの最後の行動（HotAction, CoolAction）と
+// 記録された応答から知り得た盤面（HotView, CoolView）も設定する。
+func (g *Game) Snapshots() []server.BoardSnapshot {
+	snaps := make([]server.BoardSnapshot, 0, len(g.Frames)+2)
+	snaps = append(snaps, g.snapshot(&g.Initial, server.KindConnected, server.PhaseRunning))
+	kind := server.KindTurnEnd
+	if g.HalfTurns {
+		kind = server.KindActionEnd
+	}
+	var hotAction, coolAction *server.PlayerAction
+	var hotView, coolView *server.PlayerView
+	if g.HalfTurns {
+		hotView, coolView = server.NewPlayerView(g.Width, g.Height), server.NewPlayerView(g.Width, g.Height)
+	}
+	prev := &g.Initial
+	for i := range g.Frames {
+		f := &g.Frames[i]
+		snap := g.snapshot(f, kind, server.PhaseRunning)
+		if f.Action != nil {
+			// 行動前の位置は直前のフレームから得る
+			if f.Action.Player == "hot" {
+				hotAction = server.NewPlayerAction(f.Action, prev.Hot)
+				hotView.Observe(f.Action, prev.Hot, f.Hot)
+			} else {
+				coolAction = server.NewPlayerAction(f.Action, prev.Cool)
+				coolView.Observe(f.Action, prev.Cool, f.Cool)
+			}
+		}
+		snap.HotAction, snap.CoolAction = hotAction, coolAction
+		snap.HotView, snap.CoolView = hotView.Clone(), coolView.Clone()
+		snaps = append(snaps, snap)
+		prev = f
+	}
+
+	if g.Result != nil {
+		last := &g.Initial
+		if len(g.Frames) > 0 {
+			last = &g.Frames[len(g.Frames)-1]
+		}
+		end := g.snapshot(last, server.KindGameOver, server.PhaseGameOver)
+		end.HotAction, end.CoolAction = hotAction, coolAction
+		end.HotView, end.CoolView = hotView.Clone(), coolView.Clone()
+		end.WinnerName = g.Result.Winner
+		end.Reason = g.Result.Reason
+		if last.Action == nil {
+			end.HotAlive, end.CoolAlive = g.alive(last)
+		}
+		snaps = append(snaps, end)
+	}
+
+	for i := range snaps {
+		snaps[i].Revision = uint64(i + 1)
+	}
+	return snaps
+}
+
+func (g *Game) snapshot(f *Frame, kind server.SnapshotKind, phase server.SnapshotPublicPhase) server.BoardSnapshot {
+	flat := make([]int, len(f.Cells))
+	for i, c := range f.Cells {
+		flat[i] = int(c)
+	}
+	step := server.TurnStepSecond
+	hotAlive, coolAlive := true, true
+	if f.Action != nil {
+		// 行動の記録があれば生存フラグはそこから得られる
+		step = f.Step
+		hotAlive, coolAlive = f.Action.Hot.Alive, f.Action.Cool.Alive
+	}
+	return server.BoardSnapshot{
+		Kind:      kind,
+		Step:      step,
+		Phase:     phase,
+		MapFlat:   flat,
+		Width:     g.Width,
+		Height:    g.Height,
+		MaxTurns:  g.MaxTurns,
+		Turn:      f.Turn,
+		HotName:   g.HotName,
+		HotX:      f.Hot.X,
+		HotY:      f.Hot.Y,
+		HotItems:  f.HotItems,
+		HotAlive:  hotAlive,
+		CoolName:  g.CoolName,
+		CoolX:     f.Cool.X,
+		CoolY:     f.Cool.Y,
+		CoolItems: f.CoolItems,
+		CoolAlive: coolAlive,
+	}
+}
+
+// alive は終了時の各プレイヤーの生存を返す
+// ダンプに生存が記録されていなければ最後の盤面から推定する（壁に埋まっているか、四方を壁に囲まれていれば死亡）。
+// 壁への移動や通信エラーによる死亡は盤面に残らないため、古いダンプでは生存扱いになる
+func (g *Game) alive(last *Frame) (hot, cool bool) {
+	if g.Result.HasAlive {
+		return g.Result.HotAlive, g.Result.CoolAlive
+	}
+	return !g.trapped(last, last.Hot), !g.trapped(last, last.Cool)
+}
+
+// trapped は f で pos が壁に埋まっているか、四方を壁（盤面外を含む）に囲まれていれば true を返す
+func (g *Game) trapped(f *Frame, pos server.Position) bool {
+	wall := func(x, y int) bool {
+		if x < 0 || x >= g.Width || y < 0 || y >= g.Height {
+			return true
+		}
+		return f.Cells[y*g.Width+x] == server.Wall
+	}
+	if wall(pos.X, pos.Y) {
+		return true
+	}
+	return wall(pos.X, pos.Y-1) && wall(pos.X, pos.Y+1) && wall(pos.X-1, pos.Y) && wall(pos.X+1, pos.Y)
+}
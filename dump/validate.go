@@ -0,0 +1,121 @@
+package dump
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/kqnade/CHaserGo/server"
+)
+
+// Validate はダンプ内容の整合性を検証し、見つかった全ての問題をまとめて返す
+//
+//   - ターン数・盤面サイズが正であること
+//   - フレーム数がターン数（半ターン単位のダンプでは2倍）を超えないこと
+//   - セル値が 0/2/3 のいずれかであること
+//   - プレイヤーが盤面内かつ壁以外の位置にいること（相手の put で自分のマスを埋められた場合を除く）
+//   - アイテム数が減らないこと
+//   - 盤面上のアイテムと取得済みアイテムの合計が変わらないこと
+//   - 壁が消えないこと（put で増えるのみ）
+//   - 勝者名がどちらかのプレイヤー名と一致すること
+func (g *Game) Validate() error {
+	var errs []error
+	if g.MaxTurns <= 0 {
+		errs = append(errs, fmt.Errorf("invalid max turns: %d", g.MaxTurns))
+	}
+	if g.Width <= 0 || g.Height <= 0 {
+		errs = append(errs, fmt.Errorf("invalid board size: %dx%d", g.Width, g.Height))
+		return errors.Join(errs...)
+	}
+	limit := g.MaxTurns
+	if g.HalfTurns {
+		limit *= 2
+	}
+	if len(g.Frames) > limit {
+		errs = append(errs, fmt.Errorf("%d frames exceed max turns %d", len(g.Frames), g.MaxTurns))
+	}
+
+	totalItems := -1
+	var hotBuried, coolBuried bool
+	prev := &g.Initial
+	for i := -1; i < len(g.Frames); i++ {
+		f := &g.Initial
+		if i >= 0 {
+			f = &g.Frames[i]
+		}
+		label := "initial map"
+		if i >= 0 {
+			label = fmt.Sprintf("turn %d", f.Turn)
+			if g.HalfTurns {
+				label += " " + f.Step.String()
+			}
+		}
+
+		if len(f.Cells) != g.Width*g.Height {
+			errs = append(errs, fmt.Errorf("%s: %d cells, want %d", label, len(f.Cells), g.Width*g.Height))
+			continue
+		}
+
+		onBoard := 0
+		for idx, c := range f.Cells {
+			y, x := idx/g.Width, idx%g.Width
+			switch c {
+			case server.Empty, server.Wall:
+			case server.Item:
+				onBoard++
+			default:
+				errs = append(errs, fmt.Errorf("%s: invalid cell value %d at (%d,%d)", label, c, y, x))
+			}
+			if f != prev && len(prev.Cells) == len(f.Cells) && prev.Cells[idx] == server.Wall && c != server.Wall {
+				errs = append(errs, fmt.Errorf("%s: wall at (%d,%d) disappeared", label, y, x))
+			}
+		}
+
+		errs = append(errs, g.checkPosition(label, "hot", f, prev, f.Hot, prev.Hot, &hotBuried)...)
+		errs = append(errs, g.checkPosition(label, "cool", f, prev, f.Cool, prev.Cool, &coolBuried)...)
+
+		if f.HotItems < prev.HotItems {
+			errs = append(errs, fmt.Errorf("%s: hot items decreased from %d to %d", label, prev.HotItems, f.HotItems))
+		}
+		if f.CoolItems < prev.CoolItems {
+			errs = append(errs, fmt.Errorf("%s: cool items decreased from %d to %d", label, prev.CoolItems, f.CoolItems))
+		}
+
+		total := onBoard + f.HotItems + f.CoolItems
+		if totalItems < 0 {
+			totalItems = total
+		} else if total != totalItems {
+			errs = append(errs, fmt.Errorf("%s: %d items on board and %d collected, want %d in total",
+				label, onBoard, f.HotItems+f.CoolItems, totalItems))
+		}
+
+		prev = f
+	}
+
+	if r := g.Result; r != nil && !r.Draw && r.Winner != g.HotName && r.Winner != g.CoolName {
+		errs = append(errs, fmt.Errorf("winner %q is neither %q nor %q", r.Winner, g.HotName, g.CoolName))
+	}
+
+	return errors.Join(errs...)
+}
+
+// checkPosition はプレイヤー位置が盤面内かつ壁でないことを確認する
+//
+// Board.Put は相手のいるマスにも壁を置ける（置かれた側の負け）ため、前のフレームから動かずに
+// 自分のマスが壁になった場合は問題にしない。buried はその状態かどうかで、以降のフレームに引き継ぐ
+func (g *Game) checkPosition(label, player string, f, prev *Frame, pos, prevPos server.Position, buried *bool) []error {
+	if pos.X < 0 || pos.X >= g.Width || pos.Y < 0 || pos.Y >= g.Height {
+		*buried = false
+		return []error{fmt.Errorf("%s: %s position (%d,%d) is out of bounds", label, player, pos.Y, pos.X)}
+	}
+	idx := pos.Y*g.Width + pos.X
+	if f.Cells[idx] != server.Wall {
+		*buried = false
+		return nil
+	}
+	if f != prev && pos == prevPos && (*buried || (len(prev.Cells) == len(f.Cells) && prev.Cells[idx] != server.Wall)) {
+		*buried = true
+		return nil
+	}
+	*buried = false
+	return []error{fmt.Errorf("%s: %s position (%d,%d) is on a wall", label, player, pos.Y, pos.X)}
+}
@@ -0,0 +1,239 @@
+// Package evolve は参考ボット同士の対戦結果を評価値として、マップを進化的に探索する
+//
+// mapgen で生成した初期個体を arena で対戦させて評価し、上位の個体を残しながら
+// 変異（mapgen.Generator.Mutate）させた子を加えて世代を進める。評価値は試合の長さ、
+// アイテム数の接戦度、引き分け率、壁に当たるなどで決着した試合の割合から計算する。
+// 乱数は全て Config.Seed から得るため、同じ設定からは同じ結果が得られる。
+package evolve
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"sort"
+
+	"github.com/kqnade/CHaserGo/arena"
+	"github.com/kqnade/CHaserGo/mapgen"
+)
+
+// Weights は評価値の各項目の重み
+//
+// 評価値 = Length×試合長 + Balance×接戦度 − Draw×引き分け率 − Death×決着死亡率
+type Weights struct {
+	// Length は平均試合長（終了ターン数 / 最大ターン数）の重み
+	Length float64
+	// Balance は接戦度（1 − |Hot−Cool| / (Hot+Cool)。両者 0 個の試合は 0）の重み
+	Balance float64
+	// Draw は引き分け率の重み（減点）
+	Draw float64
+	// Death は壁に当たる・囲まれるなどで終わった試合の割合の重み（減点）
+	Death float64
+}
+
+// DefaultWeights は既定の重み
+func DefaultWeights() Weights {
+	return Weights{Length: 1, Balance: 1, Draw: 0.5, Death: 1}
+}
+
+// Score はマップ1つの評価結果（各項目は試合の平均、0〜1）
+type Score struct {
+	Games     int
+	Length    float64
+	Balance   float64
+	DrawRate  float64
+	DeathRate float64
+	Fitness   float64
+}
+
+func (s Score) String() string {
+	return fmt.Sprintf("fitness=%.3f length=%.2f balance=%.2f draws=%.2f deaths=%.2f (%d games)",
+		s.Fitness, s.Length, s.Balance, s.DrawRate, s.DeathRate, s.Games)
+}
+
+// Config は探索の設定
+type Config struct {
+	// Options は初期個体の生成設定（大きさ・対称性・スタイル・ターン数など）
+	Options mapgen.Options
+	// Population は1世代の個体数
+	Population int
+	// Generations は世代を進める回数（0 なら初期個体の評価のみ）
+	Generations int
+	// Elite は次の世代にそのまま残す上位の個体数
+	Elite int
+	// Mutations は子を作るときに書き換えるマス（対称な位置はまとめて1回）の数
+	Mutations int
+	// Bots は対戦させる参考ボット。全ての組み合わせ（同じボット同士を含む）を先攻・後攻を入れ替えて対戦させる
+	Bots []arena.Strategy
+	// Games は組み合わせと先攻・後攻ごとの対戦数
+	Games   int
+	Weights Weights
+	Seed    int64
+}
+
+// DefaultConfig は既定の設定
+func DefaultConfig() Config {
+	return Config{
+		Options:     mapgen.DefaultOptions(),
+		Population:  16,
+		Generations: 20,
+		Elite:       4,
+		Mutations:   4,
+		Bots:        []arena.Strategy{arena.StrategyGreedy, arena.StrategyHunter, arena.StrategyRandom},
+		Games:       2,
+		Weights:     DefaultWeights(),
+	}
+}
+
+// Validate は設定が探索できる値か確認する
+func (c Config) Validate() error {
+	var errs []error
+	if c.Population <= 0 {
+		errs = append(errs, errors.New("population must be positive"))
+	}
+	if c.Generations < 0 {
+		errs = append(errs, errors.New("generations must be non-negative"))
+	}
+	if c.Elite <= 0 || c.Elite > c.Population {
+		errs = append(errs, fmt.Errorf("elite must be between 1 and the population (%d)", c.Population))
+	}
+	if c.Mutations <= 0 {
+		errs = append(errs, errors.New("mutations must be positive"))
+	}
+	if len(c.Bots) == 0 {
+		errs = append(errs, errors.New("at least one bot is required"))
+	}
+	if c.Games <= 0 {
+		errs = append(errs, errors.New("games must be positive"))
+	}
+	if err := c.Options.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// Candidate は探索中の個体
+type Candidate struct {
+	Map   *mapgen.Map
+	Score Score
+	// Generation は個体が作られた世代（初期個体は 0）
+	Generation int
+}
+
+// Run は cfg に従って探索し、最後の世代を評価値の高い順に返す
+// progress が nil でなければ、各世代（初期個体を含む）の評価が終わるたびに評価順の個体で呼ばれる
+func Run(cfg Config, progress func(generation int, population []Candidate)) ([]Candidate, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	gen := mapgen.NewGeneratorWithSeed(cfg.Seed)
+	// 対戦用の乱数はマップ生成と別の系列にする（生成の乱数の消費量に結果が左右されないように）
+	rng := rand.New(rand.NewSource(cfg.Seed ^ 0x5eed))
+
+	population := make([]Candidate, 0, cfg.Population)
+	for len(population) < cfg.Population {
+		m, err := gen.GenerateFair(cfg.Options, mapgen.Fairness{Repair: true})
+		if err != nil {
+			return nil, err
+		}
+		population = append(population, Candidate{Map: m, Score: cfg.evaluate(m, rng)})
+	}
+	sortCandidates(population)
+	if progress != nil {
+		progress(0, population)
+	}
+
+	for g := 1; g <= cfg.Generations; g++ {
+		next := append(make([]Candidate, 0, cfg.Population), population[:cfg.Elite]...)
+		for len(next) < cfg.Population {
+			parent := tournament(population, rng)
+			child := mutant(gen, parent.Map, cfg.Mutations)
+			next = append(next, Candidate{Map: child, Score: cfg.evaluate(child, rng), Generation: g})
+		}
+		population = next
+		sortCandidates(population)
+		if progress != nil {
+			progress(g, population)
+		}
+	}
+	return population, nil
+}
+
+// Evaluate は bots の全ての組み合わせを先攻・後攻を入れ替えて games 回ずつ m で対戦させ、評価する
+// ボットの乱数は rng から得る
+func Evaluate(m *mapgen.Map, bots []arena.Strategy, games int, w Weights, rng *rand.Rand) Score {
+	cm := m.ChaserMap("")
+	var s Score
+	for i := range bots {
+		for j := i; j < len(bots); j++ {
+			for _, pair := range [][2]arena.Strategy{{bots[i], bots[j]}, {bots[j], bots[i]}} {
+				for k := 0; k < games; k++ {
+					hot := arena.NewBot(pair[0], rand.New(rand.NewSource(rng.Int63())))
+					cool := arena.NewBot(pair[1], rand.New(rand.NewSource(rng.Int63())))
+					s.add(arena.Play(cm, hot, cool))
+				}
+			}
+		}
+	}
+	if s.Games > 0 {
+		n := float64(s.Games)
+		s.Length /= n
+		s.Balance /= n
+		s.DrawRate /= n
+		s.DeathRate /= n
+	}
+	s.Fitness = w.Length*s.Length + w.Balance*s.Balance - w.Draw*s.DrawRate - w.Death*s.DeathRate
+	return s
+}
+
+func (c Config) evaluate(m *mapgen.Map, rng *rand.Rand) Score {
+	return Evaluate(m, c.Bots, c.Games, c.Weights, rng)
+}
+
+// add は1試合の結果を合計に加える（平均は Evaluate で取る）
+func (s *Score) add(r arena.Result) {
+	s.Games++
+	if r.MaxTurns > 0 {
+		s.Length += float64(r.Turns) / float64(r.MaxTurns)
+	}
+	if total := r.HotItems + r.CoolItems; total > 0 {
+		diff := r.HotItems - r.CoolItems
+		if diff < 0 {
+			diff = -diff
+		}
+		s.Balance += 1 - float64(diff)/float64(total)
+	}
+	if r.Draw() {
+		s.DrawRate++
+	}
+	if r.Died {
+		s.DeathRate++
+	}
+}
+
+// sortCandidates は評価値の高い順に並べる（同点は元の順序を保つ）
+func sortCandidates(cs []Candidate) {
+	sort.SliceStable(cs, func(i, j int) bool { return cs[i].Score.Fitness > cs[j].Score.Fitness })
+}
+
+// tournament は2個体を無作為に選び、評価値の高い方を返す
+func tournament(population []Candidate, rng *rand.Rand) Candidate {
+	a := population[rng.Intn(len(population))]
+	b := population[rng.Intn(len(population))]
+	if b.Score.Fitness > a.Score.Fitness {
+		return b
+	}
+	return a
+}
+
+// mutant は parent を変異させ、全てのマスに両スタートから到達でき、スタートが塞がれていない子を返す
+// 条件を満たす子が得られない場合は parent の複製を返す
+func mutant(gen *mapgen.Generator, parent *mapgen.Map, mutations int) *mapgen.Map {
+	for i := 0; i < 100; i++ {
+		child := gen.Mutate(parent, mutations)
+		child.Repair()
+		if child.Stats().Check(0) == nil {
+			return child
+		}
+	}
+	return parent.Clone()
+}
@@ -0,0 +1,105 @@
+package evolve
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/kqnade/CHaserGo/arena"
+	"github.com/kqnade/CHaserGo/mapgen"
+)
+
+// smallConfig はテスト用に小さくした設定
+func smallConfig(seed int64) Config {
+	cfg := DefaultConfig()
+	cfg.Population = 6
+	cfg.Generations = 3
+	cfg.Elite = 2
+	cfg.Games = 1
+	cfg.Seed = seed
+	return cfg
+}
+
+func TestRunIsReproducible(t *testing.T) {
+	run := func(seed int64) []Candidate {
+		pop, err := Run(smallConfig(seed), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return pop
+	}
+	a, b := run(5), run(5)
+	if len(a) != 6 {
+		t.Fatalf("got %d candidates, want 6", len(a))
+	}
+	for i := range a {
+		if a[i].Score != b[i].Score || a[i].Map.String() != b[i].Map.String() {
+			t.Fatalf("candidate %d differs between runs with the same seed", i)
+		}
+	}
+	if c := run(6); c[0].Map.String() == a[0].Map.String() {
+		t.Error("different seeds produced the same best map")
+	}
+}
+
+func TestRunKeepsBestAndValidMaps(t *testing.T) {
+	var bests []float64
+	pop, err := Run(smallConfig(1), func(g int, pop []Candidate) {
+		bests = append(bests, pop[0].Score.Fitness)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bests) != 4 {
+		t.Fatalf("progress called %d times, want 4 (initial + 3 generations)", len(bests))
+	}
+	// 上位の個体はそのまま残るので、最良の評価値は下がらない
+	for i := 1; i < len(bests); i++ {
+		if bests[i] < bests[i-1] {
+			t.Errorf("best fitness dropped from %.3f to %.3f in generation %d", bests[i-1], bests[i], i)
+		}
+	}
+	for i, c := range pop {
+		if i > 0 && c.Score.Fitness > pop[i-1].Score.Fitness {
+			t.Errorf("population not sorted at %d", i)
+		}
+		if err := c.Map.Stats().Check(0); err != nil {
+			t.Errorf("candidate %d: %v", i, err)
+		}
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	m, err := mapgen.NewGeneratorWithSeed(2).Generate(mapgen.DefaultOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	bots := []arena.Strategy{arena.StrategyGreedy, arena.StrategyRandom}
+	s := Evaluate(m, bots, 2, DefaultWeights(), rand.New(rand.NewSource(1)))
+
+	// 組み合わせ 3 通り × 先攻後攻 2 × 2 回
+	if s.Games != 12 {
+		t.Errorf("Games = %d, want 12", s.Games)
+	}
+	for name, v := range map[string]float64{"Length": s.Length, "Balance": s.Balance, "DrawRate": s.DrawRate, "DeathRate": s.DeathRate} {
+		if v < 0 || v > 1 {
+			t.Errorf("%s = %v, want 0-1", name, v)
+		}
+	}
+	w := DefaultWeights()
+	if want := s.Length + s.Balance - w.Draw*s.DrawRate - s.DeathRate; s.Fitness != want {
+		t.Errorf("Fitness = %v, want %v", s.Fitness, want)
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	cfg := DefaultConfig()
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("default config: %v", err)
+	}
+	cfg.Population, cfg.Elite, cfg.Bots = 2, 3, nil
+	err := cfg.Validate()
+	if err == nil || !strings.Contains(err.Error(), "elite") || !strings.Contains(err.Error(), "bot") {
+		t.Errorf("Validate = %v, want elite and bot errors", err)
+	}
+}
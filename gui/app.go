@@ -9,9 +9,11 @@ import (
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/audio"
 	"github.com/hajimehoshi/ebiten/v2/audio/wav"
-	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
 	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/kqnade/CHaserGo/gui/assets"
 	"github.com/kqnade/CHaserGo/server"
+	"github.com/kqnade/CHaserGo/stats"
+	"github.com/kqnade/CHaserGo/theme"
 )
 
 const (
@@ -24,10 +26,22 @@ const (
 // App は Ebitengine のゲームループを管理する
 type App struct {
 	state     *GameState
+	text      *Text
 	renderer  *BoardRenderer
 	hud       *HUD
+	stats     *StatsPanel
+	history   func(rev uint64) []server.BoardSnapshot // Revision が rev 以下のスナップショット（統計パネル・軌跡用）
 	cancel    context.CancelFunc
 	bgmPlayer *audio.Player
+	panel     hudPanel     // HUD 右側の操作欄（リプレイまたはライブ操作。なければ nil）
+	live      *LiveControl // ライブ操作（巻き戻し表示を含む）でのみ設定
+}
+
+// hudPanel は HUD 右側に状態と操作方法を表示し、キー入力を処理する欄
+type hudPanel interface {
+	Update()
+	PanelWidth() int
+	Draw(screen *ebiten.Image, hudTop int)
 }
 
 // compile-time check
@@ -35,16 +49,20 @@ var _ ebiten.Game = (*App)(nil)
 
 // NewApp creates a new App
 func NewApp(state *GameState, cancel context.CancelFunc) *App {
+	t := NewText(1)
 	app := &App{
 		state:    state,
-		renderer: NewBoardRenderer(),
-		hud:      NewHUD(),
+		text:     t,
+		renderer: NewBoardRenderer(t),
+		hud:      NewHUD(t),
+		stats:    NewStatsPanel(t),
+		history:  state.HistoryUpTo,
 		cancel:   cancel,
 	}
 
 	// BGM 初期化
 	audioCtx := audio.NewContext(sampleRate)
-	stream, err := wav.DecodeWithSampleRate(sampleRate, bytes.NewReader(assetBGMWAV))
+	stream, err := wav.DecodeWithSampleRate(sampleRate, bytes.NewReader(assets.BGMWAV))
 	if err != nil {
 		log.Printf("BGM decode error: %v", err)
 		return app
@@ -59,11 +77,74 @@ func NewApp(state *GameState, cancel context.CancelFunc) *App {
 	return app
 }
 
+// NewReplayApp はダンプのリプレイを表示する App を作成する
+func NewReplayApp(replay *Replay, cancel context.CancelFunc) *App {
+	app := NewApp(replay.state, cancel)
+	app.panel = replay
+	app.history = replay.historyUpTo
+	replay.text = app.text
+	return app
+}
+
+// NewLiveApp は live で対戦の進行を操作できる App を作成する
+func NewLiveApp(state *GameState, live *LiveControl, cancel context.CancelFunc) *App {
+	app := NewApp(state, cancel)
+	app.panel = live
+	app.live = live
+	live.state, live.text = state, app.text
+	return app
+}
+
+// SetHeatmap は表示中の対戦の代わりに、複数の対戦を積み上げたヒートマップ h を表示する
+func (a *App) SetHeatmap(h *stats.Heatmap) {
+	a.renderer.SetHeatmap(h)
+}
+
+// SetThemes は T キーで切り替えるテーマを設定する（ebiten.RunGame の前に呼ぶ）
+func (a *App) SetThemes(themes []*theme.Theme) {
+	a.renderer.SetThemes(themes)
+}
+
+// SelectTheme は SetThemes で設定したテーマから名前が name のものを表示する
+func (a *App) SelectTheme(name string) error {
+	return a.renderer.SelectTheme(name)
+}
+
+// SetTextScale は文字の倍率を設定する（TextScales にない倍率は最も近い候補に丸める）
+func (a *App) SetTextScale(scale float64) {
+	a.text.SetScale(scale)
+}
+
 // Update is called every tick (60fps)
 func (a *App) Update() error {
 	if inpututil.IsKeyJustPressed(ebiten.KeyT) {
 		a.renderer.NextTheme()
 	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyA) {
+		a.renderer.ToggleActions()
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyV) {
+		a.renderer.NextView()
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyS) {
+		a.stats.Toggle()
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyR) {
+		a.renderer.ToggleTrails()
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyH) {
+		a.renderer.NextHeatmap()
+	}
+	// 文字サイズ: JIS 配列の "+" は US 配列の ";" の位置にある
+	if inpututil.IsKeyJustPressed(ebiten.KeyEqual) || inpututil.IsKeyJustPressed(ebiten.KeySemicolon) || inpututil.IsKeyJustPressed(ebiten.KeyKPAdd) {
+		a.text.Larger()
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyMinus) || inpututil.IsKeyJustPressed(ebiten.KeyKPSubtract) {
+		a.text.Smaller()
+	}
+	if a.panel != nil {
+		a.panel.Update()
+	}
 
 	if ebiten.IsKeyPressed(ebiten.KeyEscape) || ebiten.IsKeyPressed(ebiten.KeyQ) {
 		if a.bgmPlayer != nil {
@@ -92,13 +173,16 @@ func (a *App) Update() error {
 // Draw is called every frame
 func (a *App) Draw(screen *ebiten.Image) {
 	snap := a.state.Load()
+	if a.live != nil {
+		snap = a.live.Snapshot()
+	}
 
 	if snap == nil || snap.Phase == server.PhaseWaiting {
 		msg := "Waiting for players..."
 		if snap != nil {
 			msg = "Waiting for players to connect..."
 		}
-		drawWaitingScreen(screen, msg)
+		a.drawWaitingScreen(screen, msg)
 		return
 	}
 
@@ -107,12 +191,21 @@ func (a *App) Draw(screen *ebiten.Image) {
 		if msg == "" {
 			msg = "Server error"
 		}
-		drawWaitingScreen(screen, "Error: "+msg)
+		a.drawWaitingScreen(screen, "Error: "+msg)
 		return
 	}
 
-	a.renderer.Draw(screen, snap)
-	a.hud.Draw(screen, snap)
+	hudTop := ScreenHeight - a.hud.Height()
+	hudWidth := ScreenWidth - 20
+	if a.panel != nil {
+		hudWidth -= a.panel.PanelWidth() + 10
+	}
+	a.renderer.Draw(screen, snap, hudTop, a.history)
+	a.stats.Draw(screen, snap, hudTop, a.history)
+	a.hud.Draw(screen, snap, hudWidth)
+	if a.panel != nil {
+		a.panel.Draw(screen, hudTop)
+	}
 }
 
 // Layout returns the logical screen size
@@ -121,9 +214,11 @@ func (a *App) Layout(_, _ int) (int, int) {
 }
 
 // drawWaitingScreen は接続待ち画面を描画する
-func drawWaitingScreen(screen *ebiten.Image, msg string) {
+func (a *App) drawWaitingScreen(screen *ebiten.Image, msg string) {
 	screen.Fill(color.RGBA{R: 30, G: 30, B: 30, A: 255})
-	ebitenutil.DebugPrintAt(screen, "CHaser Server GUI", ScreenWidth/2-80, ScreenHeight/2-20)
-	ebitenutil.DebugPrintAt(screen, msg, ScreenWidth/2-len(msg)*3, ScreenHeight/2)
-	ebitenutil.DebugPrintAt(screen, "Press ESC or Q to quit", ScreenWidth/2-88, ScreenHeight/2+20)
+	lh := a.text.LineHeight()
+	cx, cy := ScreenWidth/2, ScreenHeight/2
+	a.text.DrawCentered(screen, "CHaser Server GUI", cx, cy-lh, textColor)
+	a.text.DrawCentered(screen, a.text.Fit(msg, ScreenWidth-20), cx, cy, textColor)
+	a.text.DrawCentered(screen, "Press ESC or Q to quit", cx, cy+lh, textColor)
 }
@@ -1,109 +1,47 @@
 package gui
 
 import (
-	"bytes"
-	_ "embed"
-	"image"
-	_ "image/png"
+	"image/color"
+	"log"
 
 	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/kqnade/CHaserGo/theme"
 )
 
-// Theme はタイルテーマの種別
-type Theme int
-
-const (
-	ThemeLight Theme = iota
-	ThemeHeavy
-	ThemeJewel
-	ThemeCount
-)
-
-func (t Theme) String() string {
-	switch t {
-	case ThemeLight:
-		return "Light"
-	case ThemeHeavy:
-		return "Heavy"
-	case ThemeJewel:
-		return "Jewel"
-	default:
-		return "Unknown"
+// newTiles はテーマのタイル画像を Ebitengine の画像にする
+func newTiles(t *theme.Theme) tiles {
+	return tiles{
+		name:  t.Name,
+		floor: ebiten.NewImageFromImage(t.Floor),
+		wall:  ebiten.NewImageFromImage(t.Block),
+		item:  ebiten.NewImageFromImage(t.Item),
+		hot:   ebiten.NewImageFromImage(t.Hot),
+		cool:  ebiten.NewImageFromImage(t.Cool),
 	}
 }
 
-// Light テーマ
-
-//go:embed assets/images/Light/Floor.png
-var assetLightFloor []byte
-
-//go:embed assets/images/Light/Block.png
-var assetLightBlock []byte
-
-//go:embed assets/images/Light/Item.png
-var assetLightItem []byte
-
-//go:embed assets/images/Light/Hot.png
-var assetLightHot []byte
-
-//go:embed assets/images/Light/Cool.png
-var assetLightCool []byte
-
-// Heavy テーマ
-
-//go:embed assets/images/Heavy/Floor.png
-var assetHeavyFloor []byte
-
-//go:embed assets/images/Heavy/Block.png
-var assetHeavyBlock []byte
-
-//go:embed assets/images/Heavy/Item.png
-var assetHeavyItem []byte
-
-//go:embed assets/images/Heavy/Hot.png
-var assetHeavyHot []byte
-
-//go:embed assets/images/Heavy/Cool.png
-var assetHeavyCool []byte
-
-// Jewel テーマ
-
-//go:embed assets/images/Jewel/Floor.png
-var assetJewelFloor []byte
-
-//go:embed assets/images/Jewel/Block.png
-var assetJewelBlock []byte
-
-//go:embed assets/images/Jewel/Item.png
-var assetJewelItem []byte
-
-//go:embed assets/images/Jewel/Hot.png
-var assetJewelHot []byte
-
-//go:embed assets/images/Jewel/Cool.png
-var assetJewelCool []byte
-
-// BGM
-
-//go:embed assets/sounds/ji_023.wav
-var assetBGMWAV []byte
-
-// themeImageData はテーマごとの PNG バイト列をまとめた型
-type themeImageData struct {
-	floor, block, item, hot, cool []byte
-}
-
-// allThemeData は全テーマの画像データ
-var allThemeData = [ThemeCount]themeImageData{
-	ThemeLight: {floor: assetLightFloor, block: assetLightBlock, item: assetLightItem, hot: assetLightHot, cool: assetLightCool},
-	ThemeHeavy: {floor: assetHeavyFloor, block: assetHeavyBlock, item: assetHeavyItem, hot: assetHeavyHot, cool: assetHeavyCool},
-	ThemeJewel: {floor: assetJewelFloor, block: assetJewelBlock, item: assetJewelItem, hot: assetJewelHot, cool: assetJewelCool},
+// builtinThemes は組み込みテーマを読み込む（読み込めないテーマはログに出して飛ばす）
+func builtinThemes() []*theme.Theme {
+	themes, err := theme.Builtin()
+	if err != nil {
+		log.Printf("Warning: built-in themes: %v", err)
+	}
+	return themes
 }
 
-func mustLoadImage(data []byte) *ebiten.Image {
-	img, _, err := image.Decode(bytes.NewReader(data))
-	if err != nil {
-		panic("failed to load embedded image: " + err.Error())
+// plainTiles は画像を使わない単色のタイル（テーマが1つも読み込めなかった場合に使う）
+func plainTiles() tiles {
+	fill := func(c color.Color) *ebiten.Image {
+		img := ebiten.NewImage(1, 1)
+		img.Fill(c)
+		return img
+	}
+	return tiles{
+		name:  "Plain",
+		floor: fill(color.RGBA{R: 60, G: 60, B: 60, A: 255}),
+		wall:  fill(color.RGBA{R: 140, G: 140, B: 140, A: 255}),
+		item:  fill(color.RGBA{R: 230, G: 200, B: 60, A: 255}),
+		hot:   fill(hudHotColor),
+		cool:  fill(hudCoolColor),
 	}
-	return ebiten.NewImageFromImage(img)
 }
@@ -0,0 +1,10 @@
+// Package assets は GUI のサウンド素材を埋め込む。
+// タイル画像は chaser-server などの GUI 以外からも使うため theme パッケージにある。
+package assets
+
+import _ "embed"
+
+// BGMWAV は GUI の対戦中BGM
+//
+//go:embed sounds/ji_023.wav
+var BGMWAV []byte
@@ -2,14 +2,16 @@ package gui
 
 import (
 	"fmt"
+	"strings"
 	"sync"
 
 	"github.com/hajimehoshi/ebiten/v2"
-	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
 	"github.com/kqnade/CHaserGo/server"
+	"github.com/kqnade/CHaserGo/theme"
 )
 
 type tiles struct {
+	name  string
 	floor *ebiten.Image
 	wall  *ebiten.Image
 	item  *ebiten.Image
@@ -19,68 +21,143 @@ type tiles struct {
 
 // BoardRenderer はゲームフィールドを描画する
 type BoardRenderer struct {
-	allTiles     [ThemeCount]tiles
+	themes       []*theme.Theme // SetThemes で設定したテーマ（nil なら組み込みテーマ）
+	allTiles     []tiles
 	loadOnce     sync.Once
-	currentTheme Theme
+	currentTheme int
+	text         *Text
+	showActions  bool
+	viewMode     ViewMode
+	hotAction    actionOverlay
+	coolAction   actionOverlay
+	movement     movementOverlay
 }
 
 // NewBoardRenderer creates a new BoardRenderer
-func NewBoardRenderer() *BoardRenderer {
-	return &BoardRenderer{currentTheme: ThemeLight}
+func NewBoardRenderer(t *Text) *BoardRenderer {
+	return &BoardRenderer{text: t, showActions: true}
+}
+
+// SetThemes は切り替えるテーマを設定する（最初の Draw より前に呼ぶ）
+func (r *BoardRenderer) SetThemes(themes []*theme.Theme) {
+	r.themes = themes
+}
+
+// SelectTheme は SetThemes で設定したテーマから、名前（大文字小文字を区別しない）が name のものを選ぶ
+func (r *BoardRenderer) SelectTheme(name string) error {
+	for i, t := range r.themes {
+		if strings.EqualFold(t.Name, name) {
+			r.currentTheme = i
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown theme %q", name)
 }
 
 // load は最初の Draw 呼び出し時に全テーマのテクスチャを初期化する
+// テーマが1つもなければ単色のタイルを使う
 func (r *BoardRenderer) load() {
 	r.loadOnce.Do(func() {
-		for t := Theme(0); t < ThemeCount; t++ {
-			d := allThemeData[t]
-			r.allTiles[t] = tiles{
-				floor: mustLoadImage(d.floor),
-				wall:  mustLoadImage(d.block),
-				item:  mustLoadImage(d.item),
-				hot:   mustLoadImage(d.hot),
-				cool:  mustLoadImage(d.cool),
-			}
+		themes := r.themes
+		if themes == nil {
+			themes = builtinThemes()
+		}
+		for _, t := range themes {
+			r.allTiles = append(r.allTiles, newTiles(t))
+		}
+		if len(r.allTiles) == 0 {
+			r.allTiles = append(r.allTiles, plainTiles())
 		}
 	})
 }
 
-// NextTheme は次のテーマに切り替える
+// NextTheme は次のテーマに切り替える（最後のテーマの次は最初に戻る）
 func (r *BoardRenderer) NextTheme() {
-	r.currentTheme = (r.currentTheme + 1) % ThemeCount
+	if n := len(r.allTiles); n > 0 {
+		r.currentTheme = (r.currentTheme + 1) % n
+	}
 }
 
-// CurrentTheme は現在のテーマを返す
-func (r *BoardRenderer) CurrentTheme() Theme {
-	return r.currentTheme
+// CurrentTheme は現在のテーマ名を返す
+func (r *BoardRenderer) CurrentTheme() string {
+	if r.currentTheme >= len(r.allTiles) {
+		return ""
+	}
+	return r.allTiles[r.currentTheme].name
 }
 
-// Draw はゲームボードを描画する
-func (r *BoardRenderer) Draw(screen *ebiten.Image, snap *server.BoardSnapshot) {
+// Draw はゲームボードを画面上部の高さ boardAreaH の領域に描画する
+// history は軌跡とヒートマップに使う、Revision が rev 以下のスナップショットの履歴
+func (r *BoardRenderer) Draw(screen *ebiten.Image, snap *server.BoardSnapshot, boardAreaH int, history func(rev uint64) []server.BoardSnapshot) {
 	r.load()
 	if snap == nil || snap.Width <= 0 || snap.Height <= 0 {
 		return
 	}
 
 	tx := r.allTiles[r.currentTheme]
-	boardAreaH := ScreenHeight - HUDHeight
 	tileSize := min(ScreenWidth/snap.Width, boardAreaH/snap.Height, 40)
 	offsetX := (ScreenWidth - tileSize*snap.Width) / 2
 	offsetY := (boardAreaH - tileSize*snap.Height) / 2
 
-	for y := 0; y < snap.Height; y++ {
-		for x := 0; x < snap.Width; x++ {
-			cell := snap.MapFlat[y*snap.Width+x]
-			r.drawTile(screen, r.cellTile(tx, cell), offsetX+x*tileSize, offsetY+y*tileSize, tileSize)
+	v := boardView{offsetX: offsetX, offsetY: offsetY, tileSize: tileSize, width: snap.Width, height: snap.Height}
+	view := r.playerView(snap)
+	if view != nil {
+		r.drawFog(screen, v, tx, view, snap)
+	} else {
+		for y := 0; y < snap.Height; y++ {
+			for x := 0; x < snap.Width; x++ {
+				cell := snap.MapFlat[y*snap.Width+x]
+				r.drawTile(screen, r.cellTile(tx, cell), offsetX+x*tileSize, offsetY+y*tileSize, tileSize)
+			}
 		}
+
+		r.drawCharacter(screen, tx.hot, snap.HotAlive, offsetX+snap.HotX*tileSize, offsetY+snap.HotY*tileSize, tileSize)
+		r.drawCharacter(screen, tx.cool, snap.CoolAlive, offsetX+snap.CoolX*tileSize, offsetY+snap.CoolY*tileSize, tileSize)
 	}
 
-	r.drawCharacter(screen, tx.hot, snap.HotAlive, offsetX+snap.HotX*tileSize, offsetY+snap.HotY*tileSize, tileSize)
-	r.drawCharacter(screen, tx.cool, snap.CoolAlive, offsetX+snap.CoolX*tileSize, offsetY+snap.CoolY*tileSize, tileSize)
+	// 軌跡とヒートマップ
+	r.drawMovement(screen, v, snap, history)
 
-	// 現在のテーマ名を右上に表示
-	label := fmt.Sprintf("Theme: %s [T]", r.currentTheme)
-	ebitenutil.DebugPrintAt(screen, label, ScreenWidth-len(label)*7, 4)
+	// 各プレイヤーの最後の行動（視点表示ではそのプレイヤーの行動だけ）
+	r.hotAction.update(snap.HotAction)
+	r.coolAction.update(snap.CoolAction)
+	if r.showActions {
+		if view == nil || r.viewMode == ViewHot {
+			drawAction(screen, v, &r.hotAction, hudHotColor)
+		}
+		if view == nil || r.viewMode == ViewCool {
+			drawAction(screen, v, &r.coolAction, hudCoolColor)
+		}
+	}
+
+	// 現在のテーマ名・表示設定を右上に表示（2行目は軌跡とヒートマップ）
+	label := fmt.Sprintf("Theme: %s [T]  Actions: %s [A]  View: %s [V]", r.text.Fit(tx.name, ScreenWidth/4), onOff(r.showActions), r.viewMode)
+	if r.viewMode != ViewFull && view == nil {
+		label += " (no data)"
+	}
+	r.text.Draw(screen, label, ScreenWidth-r.text.Width(label)-6, 4, textColor)
+	heat := "OFF"
+	if layer, ok := r.movement.heatLayer(snap); ok {
+		side := "[COOL] "
+		if layer.Hot {
+			side = "[HOT] "
+		}
+		heat = r.text.Fit(side+layer.Name, ScreenWidth/4)
+	}
+	label = fmt.Sprintf("Trail: %s [R]  Heat: %s [H]", onOff(r.movement.showTrails), heat)
+	r.text.Draw(screen, label, ScreenWidth-r.text.Width(label)-6, 4+r.text.LineHeight(), textColor)
+}
+
+// ToggleActions は行動のオーバーレイの表示・非表示を切り替える
+func (r *BoardRenderer) ToggleActions() {
+	r.showActions = !r.showActions
+}
+
+func onOff(b bool) string {
+	if b {
+		return "ON"
+	}
+	return "OFF"
 }
 
 func (r *BoardRenderer) cellTile(tx tiles, cell int) *ebiten.Image {
@@ -0,0 +1,105 @@
+package gui
+
+import (
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/kqnade/CHaserGo/server"
+)
+
+// ViewMode は盤面を誰の視点で表示するか
+type ViewMode int
+
+const (
+	ViewFull ViewMode = iota // 実際の盤面
+	ViewHot                  // Hot が応答で知らされた盤面
+	ViewCool                 // Cool が応答で知らされた盤面
+	viewModeCount
+)
+
+func (m ViewMode) String() string {
+	switch m {
+	case ViewHot:
+		return "Hot"
+	case ViewCool:
+		return "Cool"
+	default:
+		return "Full"
+	}
+}
+
+// 視点表示での暗さ（黒を重ねる不透明度）
+// 知らないマスは unknownShade、知っているマスは古いほど暗くし staleShadeMax で止める
+const (
+	unknownShade   = 0.85
+	staleShadePer  = 0.08 // 1ターン古くなるごとの暗さ
+	staleShadeMax  = 0.6
+	enemyGhostBase = 0.6 // 最後に相手を見たマスに描く相手の不透明度
+)
+
+var fogColor = color.RGBA{A: 255}
+
+// NextView は表示する視点を 全体 → Hot → Cool の順に切り替える
+func (r *BoardRenderer) NextView() {
+	r.viewMode = (r.viewMode + 1) % viewModeCount
+}
+
+// CurrentView は現在の視点を返す
+func (r *BoardRenderer) CurrentView() ViewMode {
+	return r.viewMode
+}
+
+// playerView は現在の視点のプレイヤーが知り得た盤面を返す（全体表示や記録がない場合は nil）
+func (r *BoardRenderer) playerView(snap *server.BoardSnapshot) *server.PlayerView {
+	switch r.viewMode {
+	case ViewHot:
+		return snap.HotView
+	case ViewCool:
+		return snap.CoolView
+	}
+	return nil
+}
+
+// drawFog は view で知らされた内容でマスを描き、知らないマスや古いマスを暗くする
+// 相手は最後に見た位置に薄く描く
+func (r *BoardRenderer) drawFog(screen *ebiten.Image, v boardView, tx tiles, view *server.PlayerView, snap *server.BoardSnapshot) {
+	enemy, enemyTurn := server.Position{}, -1
+	for y := 0; y < snap.Height; y++ {
+		for x := 0; x < snap.Width; x++ {
+			p := server.Position{X: x, Y: y}
+			value, turn := view.At(x, y)
+			r.drawTile(screen, r.cellTile(tx, max(value, int(server.Empty))), v.offsetX+x*v.tileSize, v.offsetY+y*v.tileSize, v.tileSize)
+			if value == server.ViewUnknown {
+				v.fillCell(screen, p, withAlpha(fogColor, unknownShade))
+				continue
+			}
+			if shade := min(staleShadeMax, staleShadePer*float32(snap.Turn-turn)); shade > 0 {
+				v.fillCell(screen, p, withAlpha(fogColor, shade))
+			}
+			if value == server.ViewEnemy && turn > enemyTurn {
+				enemy, enemyTurn = p, turn
+			}
+		}
+	}
+
+	self, opp := tx.hot, tx.cool
+	selfAlive, selfX, selfY := snap.HotAlive, snap.HotX, snap.HotY
+	if r.viewMode == ViewCool {
+		self, opp = tx.cool, tx.hot
+		selfAlive, selfX, selfY = snap.CoolAlive, snap.CoolX, snap.CoolY
+	}
+	if enemyTurn >= 0 {
+		alpha := enemyGhostBase * (1 - min(staleShadeMax, staleShadePer*float32(snap.Turn-enemyTurn)))
+		r.drawGhost(screen, opp, alpha, v.offsetX+enemy.X*v.tileSize, v.offsetY+enemy.Y*v.tileSize, v.tileSize)
+	}
+	r.drawCharacter(screen, self, selfAlive, v.offsetX+selfX*v.tileSize, v.offsetY+selfY*v.tileSize, v.tileSize)
+}
+
+func (r *BoardRenderer) drawGhost(screen, src *ebiten.Image, alpha float32, px, py, tileSize int) {
+	sw, sh := src.Bounds().Dx(), src.Bounds().Dy()
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Scale(float64(tileSize)/float64(sw), float64(tileSize)/float64(sh))
+	op.GeoM.Translate(float64(px), float64(py))
+	op.ColorScale.ScaleAlpha(alpha)
+	screen.DrawImage(src, op)
+}
@@ -5,48 +5,51 @@ import (
 	"image/color"
 
 	"github.com/hajimehoshi/ebiten/v2"
-	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
 	"github.com/hajimehoshi/ebiten/v2/vector"
 	"github.com/kqnade/CHaserGo/server"
 )
 
-var hudBgColor = color.RGBA{R: 20, G: 20, B: 20, A: 220}
+var (
+	hudBgColor   = color.RGBA{R: 20, G: 20, B: 20, A: 220}
+	hudHotColor  = color.RGBA{R: 255, G: 110, B: 90, A: 255}
+	hudCoolColor = color.RGBA{R: 110, G: 170, B: 255, A: 255}
+)
 
 // HUD はスコア・ターン・勝者情報を描画する
-type HUD struct{}
+type HUD struct {
+	text *Text
+}
 
 // NewHUD creates a new HUD
-func NewHUD() *HUD {
-	return &HUD{}
+func NewHUD(t *Text) *HUD {
+	return &HUD{text: t}
 }
 
-// Draw はHUD領域（画面下部 HUDHeight px）に情報を描画する
-func (h *HUD) Draw(screen *ebiten.Image, snap *server.BoardSnapshot) {
+// Height は HUD 領域の高さを返す（文字の倍率に合わせて広がる）
+func (h *HUD) Height() int {
+	return h.text.HUDHeight()
+}
+
+// Draw はHUD領域（画面下部 Height() px）に情報を描画する
+// 文字は左端から幅 width に収まるように描き、長い名前は末尾を省略する
+func (h *HUD) Draw(screen *ebiten.Image, snap *server.BoardSnapshot, width int) {
 	if snap == nil {
 		return
 	}
-	y := ScreenHeight - HUDHeight
+	height := h.Height()
+	y := ScreenHeight - height + int(hudPadTop*h.text.Scale())
+	lh := h.text.LineHeight()
 
 	// 背景
-	vector.FillRect(screen, 0, float32(y), float32(ScreenWidth), float32(HUDHeight), hudBgColor, false)
+	vector.FillRect(screen, 0, float32(ScreenHeight-height), float32(ScreenWidth), float32(height), hudBgColor, false)
 
 	// ターン表示
 	turnStr := fmt.Sprintf("Turn: %d / %d", snap.Turn, snap.MaxTurns)
-	ebitenutil.DebugPrintAt(screen, turnStr, 10, y+8)
-
-	// Hot スコア
-	hotStr := fmt.Sprintf("[HOT]  %s: %d items", snap.HotName, snap.HotItems)
-	if !snap.HotAlive {
-		hotStr += " (DEAD)"
-	}
-	ebitenutil.DebugPrintAt(screen, hotStr, 10, y+28)
+	h.text.Draw(screen, h.text.Fit(turnStr, width), 10, y, textColor)
 
-	// Cool スコア
-	coolStr := fmt.Sprintf("[COOL] %s: %d items", snap.CoolName, snap.CoolItems)
-	if !snap.CoolAlive {
-		coolStr += " (DEAD)"
-	}
-	ebitenutil.DebugPrintAt(screen, coolStr, 10, y+48)
+	// Hot / Cool スコア
+	h.text.Draw(screen, h.playerLine("[HOT]  ", snap.HotName, snap.HotItems, snap.HotAlive, width), 10, y+lh, hudHotColor)
+	h.text.Draw(screen, h.playerLine("[COOL] ", snap.CoolName, snap.CoolItems, snap.CoolAlive, width), 10, y+2*lh, hudCoolColor)
 
 	// 勝者表示
 	if snap.Phase == server.PhaseGameOver {
@@ -56,6 +59,17 @@ func (h *HUD) Draw(screen *ebiten.Image, snap *server.BoardSnapshot) {
 		} else {
 			result = fmt.Sprintf("DRAW  (%s)", snap.Reason)
 		}
-		ebitenutil.DebugPrintAt(screen, result, 10, y+68)
+		h.text.Draw(screen, h.text.Fit(result, width), 10, y+3*lh, textColor)
+	}
+}
+
+// playerLine はプレイヤーの行を作る
+// 幅 width に収まらない場合は名前の末尾を省略し、アイテム数と生死は常に表示する
+func (h *HUD) playerLine(label, name string, items int, alive bool, width int) string {
+	rest := fmt.Sprintf(": %d items", items)
+	if !alive {
+		rest += " (DEAD)"
 	}
+	name = h.text.Fit(name, width-h.text.Width(label+rest))
+	return h.text.Fit(label+name+rest, width)
 }
@@ -0,0 +1,177 @@
+package gui
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/kqnade/CHaserGo/server"
+)
+
+// liveDelays は半ターンごとの待ち時間の候補（Up で短く、Down で長くする）
+var liveDelays = []time.Duration{
+	0,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	250 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	2 * time.Second,
+}
+
+// liveHelp は HUD 右側に表示する操作方法
+var liveHelp = []string{
+	"Space:Pause  Right:Step  Left:Back",
+	"Up/Down:Faster/Slower  End:Live",
+}
+
+// LiveControl は対戦中のサーバーの進行（一時停止・コマ送り・待ち時間）をキー入力で操作する
+// 操作は ServerConfig.Pacer に渡した server.Pace を通してサーバーに伝わる
+//
+// GameState の履歴を使い、対戦を続けたまま過去の盤面に巻き戻して表示することもできる。
+// 巻き戻し中は Left/Right で履歴を移動し、End（または最新を越えて Right）でライブ表示に戻る。
+type LiveControl struct {
+	pace  *server.Pace
+	state *GameState // NewLiveApp で App と共有する
+	text  *Text      // NewLiveApp で App と共有する
+
+	rewinding bool
+	revision  uint64 // 巻き戻し中に表示しているスナップショットの Revision
+}
+
+// NewLiveControl は pace を操作する LiveControl を作成する
+func NewLiveControl(pace *server.Pace) *LiveControl {
+	return &LiveControl{pace: pace}
+}
+
+// Update はキー・マウス入力を処理する
+func (l *LiveControl) Update() {
+	switch {
+	case inpututil.IsKeyJustPressed(ebiten.KeySpace):
+		l.pace.TogglePause()
+	case isKeyRepeated(ebiten.KeyLeft):
+		l.rewind(-1)
+	case isKeyRepeated(ebiten.KeyRight):
+		if l.rewinding {
+			l.rewind(1)
+		} else {
+			l.pace.Step()
+		}
+	case inpututil.IsKeyJustPressed(ebiten.KeyHome):
+		l.seek(0)
+	case inpututil.IsKeyJustPressed(ebiten.KeyEnd):
+		l.rewinding = false
+	case inpututil.IsKeyJustPressed(ebiten.KeyUp):
+		l.pace.SetDelay(shorterDelay(l.pace.Delay()))
+	case inpututil.IsKeyJustPressed(ebiten.KeyDown):
+		l.pace.SetDelay(longerDelay(l.pace.Delay()))
+	}
+
+	if frac, ok := scrubBarClicked(); ok {
+		if frac >= 1 {
+			l.rewinding = false
+		} else {
+			l.seek(int(frac*float64(l.state.HistoryLen()-1) + 0.5))
+		}
+	}
+}
+
+// position は表示中のスナップショットの履歴上の位置と履歴の件数を返す
+func (l *LiveControl) position() (int, int) {
+	n := l.state.HistoryLen()
+	if !l.rewinding {
+		return n - 1, n
+	}
+	return l.state.HistoryIndex(l.revision), n
+}
+
+// rewind は表示位置を履歴上で d 件動かす（最新を越えたらライブ表示に戻る）
+func (l *LiveControl) rewind(d int) {
+	i, n := l.position()
+	if i+d >= n {
+		l.rewinding = false
+		return
+	}
+	l.seek(i + d)
+}
+
+// seek は履歴の i 番目を表示する
+func (l *LiveControl) seek(i int) {
+	if s := l.state.HistoryAt(i); s != nil {
+		l.rewinding = true
+		l.revision = s.Revision
+	}
+}
+
+// Snapshot は表示すべきスナップショットを返す（巻き戻し中でなければ最新）
+func (l *LiveControl) Snapshot() *server.BoardSnapshot {
+	if l.rewinding {
+		if s := l.state.HistoryAt(l.state.HistoryIndex(l.revision)); s != nil {
+			return s
+		}
+	}
+	return l.state.Load()
+}
+
+// shorterDelay は d より短い候補のうち最も長いものを返す（なければ 0）
+func shorterDelay(d time.Duration) time.Duration {
+	i, _ := slices.BinarySearch(liveDelays, d)
+	return liveDelays[max(i-1, 0)]
+}
+
+// longerDelay は d より長い候補のうち最も短いものを返す（なければ最長の候補）
+func longerDelay(d time.Duration) time.Duration {
+	i, found := slices.BinarySearch(liveDelays, d)
+	if found {
+		i++
+	}
+	return liveDelays[min(i, len(liveDelays)-1)]
+}
+
+func (l *LiveControl) statusLine(paused bool, delay time.Duration) string {
+	status := "RUN"
+	if paused {
+		status = "PAUSE"
+	}
+	return fmt.Sprintf("LIVE  %s  delay %v", status, delay)
+}
+
+// rewindLine は巻き戻し中の表示位置の行を返す
+func (l *LiveControl) rewindLine(index, n int) string {
+	return fmt.Sprintf("REWIND %d/%d", index+1, n)
+}
+
+// PanelWidth は HUD 右側の進行状態の欄の幅を返す
+// 状態や待ち時間で欄が動かないよう、最も長くなる場合の幅にする
+func (l *LiveControl) PanelWidth() int {
+	w := l.text.Width(l.statusLine(true, l.pace.Delay()))
+	for _, d := range liveDelays {
+		w = max(w, l.text.Width(l.statusLine(true, d)))
+	}
+	for _, s := range liveHelp {
+		w = max(w, l.text.Width(s))
+	}
+	n := max(l.state.HistoryLen(), DefaultHistoryLimit)
+	return max(w, l.text.Width(l.rewindLine(n-1, n)))
+}
+
+// Draw は HUD 右側（HUD の上端が hudTop）に進行状態と操作方法を、最下部にスクラブバーを描画する
+func (l *LiveControl) Draw(screen *ebiten.Image, hudTop int) {
+	y := hudTop + int(hudPadTop*l.text.Scale())
+	x := ScreenWidth - 10 - l.PanelWidth()
+	lh := l.text.LineHeight()
+
+	l.text.Draw(screen, l.statusLine(l.pace.Paused(), l.pace.Delay()), x, y, textColor)
+	for i, s := range liveHelp {
+		l.text.Draw(screen, s, x, y+(i+1)*lh, textColor)
+	}
+
+	// 巻き戻し中は位置を表示し、スクラブバーは常に描く（右端がライブ）
+	index, n := l.position()
+	if l.rewinding {
+		l.text.Draw(screen, l.rewindLine(index, n), x, y+(len(liveHelp)+1)*lh, scrubBarFillColor)
+	}
+	drawScrubBar(screen, index, n)
+}
@@ -0,0 +1,138 @@
+package gui
+
+import (
+	"image/color"
+	"math"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"github.com/kqnade/CHaserGo/server"
+)
+
+// 行動のオーバーレイは現れた直後に強く表示し、overlayFade かけて overlayMinAlpha まで薄くする
+const (
+	overlayFade     = 600 * time.Millisecond
+	overlayMinAlpha = 0.35
+)
+
+var putFlashColor = color.RGBA{R: 255, G: 255, B: 255, A: 255}
+
+// actionOverlay は1プレイヤー分の行動のオーバーレイ
+type actionOverlay struct {
+	action *server.PlayerAction
+	since  time.Time // action が現れた時刻
+}
+
+// update はスナップショットの行動 a を反映する（新しい行動なら表示を始め直す）
+func (o *actionOverlay) update(a *server.PlayerAction) {
+	if a != o.action {
+		o.action = a
+		o.since = time.Now()
+	}
+}
+
+// fresh は行動が現れてからの経過に応じて 1（直後）から 0（overlayFade 経過後）に減る値を返す
+func (o *actionOverlay) fresh() float32 {
+	return float32(max(0, 1-float64(time.Since(o.since))/float64(overlayFade)))
+}
+
+// boardView は盤面の画面上の配置
+type boardView struct {
+	offsetX, offsetY, tileSize int
+	width, height              int
+}
+
+func (v boardView) inside(p server.Position) bool {
+	return p.X >= 0 && p.X < v.width && p.Y >= 0 && p.Y < v.height
+}
+
+// center はマス p の中心の画面座標を返す
+func (v boardView) center(p server.Position) (float32, float32) {
+	ts := float32(v.tileSize)
+	return float32(v.offsetX) + (float32(p.X)+0.5)*ts, float32(v.offsetY) + (float32(p.Y)+0.5)*ts
+}
+
+// fillCell はマス p を塗る
+func (v boardView) fillCell(screen *ebiten.Image, p server.Position, clr color.Color) {
+	ts := float32(v.tileSize)
+	vector.FillRect(screen, float32(v.offsetX)+float32(p.X)*ts, float32(v.offsetY)+float32(p.Y)*ts, ts, ts, clr, false)
+}
+
+// strokeCell はマス p を枠で囲む
+func (v boardView) strokeCell(screen *ebiten.Image, p server.Position, clr color.Color) {
+	ts := float32(v.tileSize)
+	w := max(2, ts/12)
+	vector.StrokeRect(screen, float32(v.offsetX)+float32(p.X)*ts+w/2, float32(v.offsetY)+float32(p.Y)*ts+w/2, ts-w, ts-w, w, clr, false)
+}
+
+// withAlpha は c の不透明度を a 倍した色を返す
+func withAlpha(c color.RGBA, a float32) color.Color {
+	a = min(max(a, 0), 1)
+	return color.RGBA{R: uint8(float32(c.R) * a), G: uint8(float32(c.G) * a), B: uint8(float32(c.B) * a), A: uint8(float32(c.A) * a)}
+}
+
+// drawAction は行動 o を色 c で描く
+// lk は見たマスを、sc は調べた直線を、pt はブロックを置いたマスを強調し、wk は移動の矢印を描く
+func drawAction(screen *ebiten.Image, v boardView, o *actionOverlay, c color.RGBA) {
+	a := o.action
+	if a == nil {
+		return
+	}
+	f := o.fresh()
+	alpha := overlayMinAlpha + (1-overlayMinAlpha)*f
+	lineWidth := max(2, float32(v.tileSize)/8)
+
+	switch a.Action {
+	case "lk":
+		for _, p := range a.Cells {
+			if v.inside(p) {
+				v.fillCell(screen, p, withAlpha(c, 0.45*alpha))
+				v.strokeCell(screen, p, withAlpha(c, alpha))
+			}
+		}
+	case "sc":
+		// 盤面内の最も遠いマスまで線を引く
+		last := a.From
+		for _, p := range a.Cells {
+			if !v.inside(p) {
+				break
+			}
+			v.fillCell(screen, p, withAlpha(c, 0.3*alpha))
+			last = p
+		}
+		if last != a.From {
+			x0, y0 := v.center(a.From)
+			x1, y1 := v.center(last)
+			vector.StrokeLine(screen, x0, y0, x1, y1, lineWidth/2, withAlpha(c, alpha), true)
+		}
+	case "pt":
+		for _, p := range a.Cells {
+			if v.inside(p) {
+				v.fillCell(screen, p, withAlpha(putFlashColor, 0.8*f))
+				v.strokeCell(screen, p, withAlpha(c, alpha))
+			}
+		}
+	case "wk":
+		if len(a.Cells) > 0 {
+			drawArrow(screen, v, a.From, a.Cells[0], lineWidth, withAlpha(c, alpha))
+		}
+	}
+}
+
+// drawArrow は from のマスから to のマスへの矢印を描く
+func drawArrow(screen *ebiten.Image, v boardView, from, to server.Position, width float32, clr color.Color) {
+	x0, y0 := v.center(from)
+	x1, y1 := v.center(to)
+	angle := math.Atan2(float64(y1-y0), float64(x1-x0))
+	head := float64(v.tileSize) * 0.3
+	// 矢印の先はマスの中心より手前で止め、キャラクターに重なりすぎないようにする
+	tipX := x1 - float32(math.Cos(angle)*head*0.5)
+	tipY := y1 - float32(math.Sin(angle)*head*0.5)
+	vector.StrokeLine(screen, x0, y0, tipX, tipY, width, clr, true)
+	for _, d := range []float64{math.Pi * 3 / 4, -math.Pi * 3 / 4} {
+		hx := tipX + float32(math.Cos(angle+d)*head)
+		hy := tipY + float32(math.Sin(angle+d)*head)
+		vector.StrokeLine(screen, tipX, tipY, hx, hy, width, clr, true)
+	}
+}
@@ -0,0 +1,176 @@
+package gui
+
+import (
+	"fmt"
+	"image/color"
+	"sort"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"github.com/kqnade/CHaserGo/server"
+)
+
+// replaySpeeds は再生速度の候補（1秒あたりに進めるスナップショット数）
+var replaySpeeds = []float64{1, 2, 4, 8, 16, 32}
+
+const defaultReplaySpeed = 2 // replaySpeeds[2] = 4
+
+// スクラブバーの配置（HUD 領域の最下部）
+const (
+	scrubBarX      = 10
+	scrubBarY      = ScreenHeight - 12
+	scrubBarWidth  = ScreenWidth - 20
+	scrubBarHeight = 6
+	// scrubBarSlop はクリック判定を上下に広げる量
+	scrubBarSlop = 6
+)
+
+var (
+	scrubBarBgColor   = color.RGBA{R: 70, G: 70, B: 70, A: 255}
+	scrubBarFillColor = color.RGBA{R: 220, G: 120, B: 40, A: 255}
+)
+
+// Replay はダンプから得たスナップショット列を GameState に流し込んで再生する
+// 再生位置の操作（再生/一時停止・コマ送り・速度・シーク）は Update でキー入力から行う
+type Replay struct {
+	state   *GameState
+	text    *Text // NewReplayApp で App と共有する
+	snaps   []server.BoardSnapshot
+	index   int
+	playing bool
+	speed   int     // replaySpeeds のインデックス
+	elapsed float64 // 前回コマを進めてからの経過（コマ単位）
+}
+
+// NewReplay は snaps を再生する Replay を作成し、先頭のスナップショットを state に設定する
+// snaps は空であってはならない
+func NewReplay(state *GameState, snaps []server.BoardSnapshot) *Replay {
+	r := &Replay{state: state, snaps: snaps, speed: defaultReplaySpeed}
+	r.seek(0)
+	return r
+}
+
+// seek は再生位置を i に移動する（範囲外は端に丸める）
+func (r *Replay) seek(i int) {
+	r.index = max(0, min(i, len(r.snaps)-1))
+	r.elapsed = 0
+	snap := r.snaps[r.index]
+	r.state.ptr.Store(&snap)
+}
+
+// Update はキー・マウス入力を処理し、再生中であれば位置を進める
+func (r *Replay) Update() {
+	switch {
+	case inpututil.IsKeyJustPressed(ebiten.KeySpace):
+		if !r.playing && r.index == len(r.snaps)-1 {
+			// 末尾で再生した場合は先頭から
+			r.seek(0)
+		}
+		r.playing = !r.playing
+	case isKeyRepeated(ebiten.KeyRight):
+		r.playing = false
+		r.seek(r.index + 1)
+	case isKeyRepeated(ebiten.KeyLeft):
+		r.playing = false
+		r.seek(r.index - 1)
+	case inpututil.IsKeyJustPressed(ebiten.KeyHome):
+		r.seek(0)
+	case inpututil.IsKeyJustPressed(ebiten.KeyEnd):
+		r.seek(len(r.snaps) - 1)
+	case inpututil.IsKeyJustPressed(ebiten.KeyUp):
+		r.speed = min(r.speed+1, len(replaySpeeds)-1)
+	case inpututil.IsKeyJustPressed(ebiten.KeyDown):
+		r.speed = max(r.speed-1, 0)
+	}
+
+	if frac, ok := scrubBarClicked(); ok {
+		r.seek(int(frac*float64(len(r.snaps)-1) + 0.5))
+	}
+
+	if !r.playing {
+		return
+	}
+	r.elapsed += replaySpeeds[r.speed] / float64(ebiten.TPS())
+	for r.elapsed >= 1 {
+		if r.index == len(r.snaps)-1 {
+			r.playing = false
+			return
+		}
+		elapsed := r.elapsed - 1
+		r.seek(r.index + 1)
+		r.elapsed = elapsed
+	}
+}
+
+// isKeyRepeated は押した直後と、押し続けた場合は一定間隔で true を返す
+func isKeyRepeated(key ebiten.Key) bool {
+	d := inpututil.KeyPressDuration(key)
+	return d == 1 || (d > 30 && d%4 == 0)
+}
+
+// replayHelp は HUD 右側に表示する操作方法
+var replayHelp = []string{
+	"Space:Play  Left/Right:Step",
+	"Up/Down:Speed  Click bar:Seek",
+}
+
+func (r *Replay) statusLine(index int, playing bool, speed float64) string {
+	status := "PAUSE"
+	if playing {
+		status = "PLAY"
+	}
+	return fmt.Sprintf("REPLAY %d/%d  %s x%g", index+1, len(r.snaps), status, speed)
+}
+
+// PanelWidth は HUD 右側の再生状態の欄の幅を返す
+// 再生位置や速度で欄が動かないよう、最も長くなる場合の幅にする
+func (r *Replay) PanelWidth() int {
+	w := r.text.Width(r.statusLine(len(r.snaps)-1, false, replaySpeeds[len(replaySpeeds)-1]))
+	for _, s := range replayHelp {
+		w = max(w, r.text.Width(s))
+	}
+	return w
+}
+
+// Draw は HUD 右側（HUD の上端が hudTop）に再生状態と操作方法を、最下部にスクラブバーを描画する
+func (r *Replay) Draw(screen *ebiten.Image, hudTop int) {
+	y := hudTop + int(hudPadTop*r.text.Scale())
+	x := ScreenWidth - 10 - r.PanelWidth()
+	lh := r.text.LineHeight()
+
+	r.text.Draw(screen, r.statusLine(r.index, r.playing, replaySpeeds[r.speed]), x, y, textColor)
+	for i, s := range replayHelp {
+		r.text.Draw(screen, s, x, y+(i+1)*lh, textColor)
+	}
+
+	drawScrubBar(screen, r.index, len(r.snaps))
+}
+
+// scrubBarClicked はスクラブバーがクリック（ドラッグ）されていれば、その位置（0〜1）を返す
+func scrubBarClicked() (float64, bool) {
+	if !ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft) {
+		return 0, false
+	}
+	x, y := ebiten.CursorPosition()
+	if y < scrubBarY-scrubBarSlop || y > scrubBarY+scrubBarHeight+scrubBarSlop {
+		return 0, false
+	}
+	return min(max(float64(x-scrubBarX)/scrubBarWidth, 0), 1), true
+}
+
+// drawScrubBar は n 件中 index 番目の位置を示すスクラブバーを描画する
+func drawScrubBar(screen *ebiten.Image, index, n int) {
+	vector.FillRect(screen, scrubBarX, scrubBarY, scrubBarWidth, scrubBarHeight, scrubBarBgColor, false)
+	frac := float32(1)
+	if n > 1 {
+		frac = float32(index) / float32(n-1)
+	}
+	vector.FillRect(screen, scrubBarX, scrubBarY, scrubBarWidth*frac, scrubBarHeight, scrubBarFillColor, false)
+}
+
+// historyUpTo は Revision が rev 以下のスナップショットを返す（統計パネル用）
+func (r *Replay) historyUpTo(rev uint64) []server.BoardSnapshot {
+	i := sort.Search(len(r.snaps), func(i int) bool { return r.snaps[i].Revision > rev })
+	return r.snaps[:i]
+}
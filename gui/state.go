@@ -1,17 +1,39 @@
 package gui
 
 import (
+	"sort"
+	"sync"
 	"sync/atomic"
 
 	"github.com/kqnade/CHaserGo/server"
 )
 
-// GameState はサーバーgoroutineとEbitengineメインスレッド間で
-// 共有されるロックフリーなゲーム状態
+// DefaultHistoryLimit は GameState が保持するスナップショットの既定の上限
+const DefaultHistoryLimit = 4096
+
+// GameState はサーバーgoroutineとEbitengineメインスレッド間で共有されるゲーム状態
+//
+// 最新のスナップショットは atomic.Pointer で（Run が SnapshotCh から受け取る）、
+// 巻き戻し用の履歴は mu で保護して保持する。履歴は server.ServerConfig.Recorder に
+// GameState を設定して発行された全てのスナップショットを Record で受け取り、
+// Revision の昇順で最大 Limit 件保持する（古いものから捨てる）。
+// 取りこぼしのある SnapshotCh からは作らないため、途中のスナップショットは欠けない。
+//
+// 履歴は末尾への追加だけで既存の要素を書き換えない（捨てるときは新しい配列に移す）ため、
+// HistoryUpTo はコピーせずに読み取り専用の部分スライスを返せる。
 type GameState struct {
 	ptr atomic.Pointer[server.BoardSnapshot]
+
+	// Limit は履歴の上限（0 以下で DefaultHistoryLimit）。Run の開始前に設定する
+	Limit int
+
+	mu      sync.Mutex
+	history []server.BoardSnapshot
 }
 
+// compile-time check
+var _ server.SnapshotRecorder = (*GameState)(nil)
+
 // Run は ch からスナップショットを受信して ptr を更新するループ
 // cmd/chaser-server-gui/main.go から goroutine として起動する
 func (g *GameState) Run(ch <-chan server.BoardSnapshot) {
@@ -21,7 +43,64 @@ func (g *GameState) Run(ch <-chan server.BoardSnapshot) {
 	}
 }
 
+// Record は snap を履歴に追加する（server.SnapshotRecorder）
+// Revision が戻った場合は別のゲームが始まったとみなし、履歴を捨てる
+func (g *GameState) Record(snap server.BoardSnapshot) {
+	limit := g.Limit
+	if limit <= 0 {
+		limit = DefaultHistoryLimit
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if n := len(g.history); n > 0 && snap.Revision <= g.history[n-1].Revision {
+		g.history = nil // 既に返した部分スライスが書き換わらないよう、新しい配列にする
+	}
+	g.history = append(g.history, snap)
+	if len(g.history) > limit {
+		g.history = append(g.history[:0:0], g.history[len(g.history)-limit:]...)
+	}
+}
+
 // Load は現在のスナップショットを返す（nil = 未初期化）
 func (g *GameState) Load() *server.BoardSnapshot {
 	return g.ptr.Load()
 }
+
+// HistoryLen は履歴のスナップショット数を返す
+func (g *GameState) HistoryLen() int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return len(g.history)
+}
+
+// HistoryAt は履歴の i 番目（0 が最も古い）のスナップショットを返す（範囲外は端に丸める）
+// 履歴が空の場合は nil を返す
+func (g *GameState) HistoryAt(i int) *server.BoardSnapshot {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if len(g.history) == 0 {
+		return nil
+	}
+	s := g.history[max(0, min(i, len(g.history)-1))]
+	return &s
+}
+
+// HistoryIndex は Revision が rev 以下で最も新しいスナップショットの履歴上の位置を返す
+// rev が履歴のどれよりも古い場合は 0 を返す
+func (g *GameState) HistoryIndex(rev uint64) int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	i := sort.Search(len(g.history), func(i int) bool { return g.history[i].Revision > rev })
+	return max(i-1, 0)
+}
+
+// HistoryUpTo は Revision が rev 以下のスナップショットの履歴を返す
+// コピーせずに履歴を共有するため、返したスライスの要素は変更しないこと
+// （容量を長さに揃えてあるので append しても履歴は書き換わらない）
+func (g *GameState) HistoryUpTo(rev uint64) []server.BoardSnapshot {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	i := sort.Search(len(g.history), func(i int) bool { return g.history[i].Revision > rev })
+	return g.history[:i:i]
+}
@@ -0,0 +1,177 @@
+package gui
+
+import (
+	"fmt"
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"github.com/kqnade/CHaserGo/server"
+	"github.com/kqnade/CHaserGo/stats"
+)
+
+var (
+	statsBgColor   = color.RGBA{R: 20, G: 20, B: 20, A: 230}
+	statsAxisColor = color.RGBA{R: 110, G: 110, B: 110, A: 255}
+)
+
+// statsActionLabels は統計パネルの行動の表示名
+var statsActionLabels = map[string]string{
+	"wk": "Walk",
+	"lk": "Look",
+	"sc": "Search",
+	"pt": "Put",
+}
+
+// StatsPanel はアイテム数の推移のグラフと行動の集計を盤面に重ねて表示する
+// 集計は表示中のスナップショットまでの履歴から行い、パネルの表示中に Revision が変わったときだけやり直す
+// 対戦が終わると勝敗の理由を含むまとめとして自動的に表示する
+type StatsPanel struct {
+	text    *Text
+	visible bool
+
+	revision  uint64 // 集計に使ったスナップショットの Revision
+	computed  bool
+	last      uint64 // 前回の update のスナップショットの Revision（非表示の間も更新する）
+	stats     stats.Stats
+	summarize bool // 対戦終了時に自動表示したか
+}
+
+// NewStatsPanel creates a new StatsPanel
+func NewStatsPanel(t *Text) *StatsPanel {
+	return &StatsPanel{text: t}
+}
+
+// Toggle は統計パネルの表示・非表示を切り替える
+func (p *StatsPanel) Toggle() {
+	p.visible = !p.visible
+}
+
+// update は snap までの履歴で集計をやり直す（非表示の間と、snap が前回と同じなら何もしない）
+func (p *StatsPanel) update(snap *server.BoardSnapshot, history func(rev uint64) []server.BoardSnapshot) {
+	if snap.Revision < p.last {
+		// 巻き戻しや次のゲームで過去に戻った場合は、終了時のまとめを出し直せるようにする
+		p.summarize = false
+	}
+	p.last = snap.Revision
+	if snap.Phase == server.PhaseGameOver && !p.summarize {
+		p.summarize = true
+		p.visible = true
+	}
+	if !p.visible || (p.computed && snap.Revision == p.revision) {
+		return
+	}
+	p.revision = snap.Revision
+	p.computed = true
+	p.stats = stats.FromSnapshots(history(snap.Revision))
+}
+
+// Draw は盤面の領域（高さ boardAreaH）の中央に統計パネルを描画する
+func (p *StatsPanel) Draw(screen *ebiten.Image, snap *server.BoardSnapshot, boardAreaH int, history func(rev uint64) []server.BoardSnapshot) {
+	if snap == nil {
+		return
+	}
+	p.update(snap, history)
+	if !p.visible {
+		return
+	}
+
+	t := p.text
+	lh := t.LineHeight()
+	pad := lh / 2
+	rows := 1 + len(stats.Actions) + 3 // 見出し・行動・移動距離・ブロック・アイテム
+	titleLines := 2
+	w := ScreenWidth - 40
+	h := pad*2 + (titleLines+rows)*lh
+	x0, y0 := 20, max(4, (boardAreaH-h)/2)
+	vector.FillRect(screen, float32(x0), float32(y0), float32(w), float32(h), statsBgColor, false)
+
+	// 見出し（対戦終了時は勝敗と理由）
+	title := fmt.Sprintf("STATS  Turn %d / %d  [S]", snap.Turn, snap.MaxTurns)
+	sub := ""
+	if p.stats.Partial {
+		sub = "partial history: counts may be low"
+	}
+	if snap.Phase == server.PhaseGameOver {
+		title = "GAME OVER  DRAW"
+		if snap.WinnerName != "" {
+			title = "GAME OVER  WINNER: " + snap.WinnerName
+		}
+		sub = fmt.Sprintf("%s  (Turn %d / %d)", snap.Reason, snap.Turn, snap.MaxTurns)
+		if p.stats.Partial {
+			sub += "  partial history"
+		}
+	}
+	t.Draw(screen, t.Fit(title, w-2*pad), x0+pad, y0+pad, textColor)
+	t.Draw(screen, t.Fit(sub, w-2*pad), x0+pad, y0+pad+lh, textColor)
+
+	// 左半分にアイテム数のグラフ、右半分に集計表
+	top := y0 + pad + titleLines*lh
+	half := (w - 3*pad) / 2
+	p.drawGraph(screen, x0+pad, top, half, rows*lh-pad)
+	p.drawTable(screen, snap, x0+2*pad+half, top, half)
+}
+
+// drawGraph は (x, y) を左上とする幅 w・高さ h の領域にアイテム数の推移を描く
+func (p *StatsPanel) drawGraph(screen *ebiten.Image, x, y, w, h int) {
+	st := &p.stats
+	maxItems := 1
+	for _, pt := range st.Items {
+		maxItems = max(maxItems, pt.Hot, pt.Cool)
+	}
+	maxTurns := max(st.MaxTurns, 1)
+
+	label := fmt.Sprintf("items %d", maxItems)
+	p.text.Draw(screen, label, x, y, statsAxisColor)
+	gy := y + p.text.LineHeight()
+	gh := h - p.text.LineHeight()
+	if gh <= 0 || len(st.Items) == 0 {
+		return
+	}
+	fx, fy, fw, fh := float32(x), float32(gy), float32(w), float32(gh)
+	vector.StrokeLine(screen, fx, fy, fx, fy+fh, 1, statsAxisColor, false)
+	vector.StrokeLine(screen, fx, fy+fh, fx+fw, fy+fh, 1, statsAxisColor, false)
+
+	px := func(turn int) float32 { return fx + fw*float32(turn)/float32(maxTurns) }
+	py := func(items int) float32 { return fy + fh - fh*float32(items)/float32(maxItems) }
+	series := []struct {
+		value func(stats.Point) int
+		c     color.Color
+	}{
+		{func(pt stats.Point) int { return pt.Hot }, hudHotColor},
+		{func(pt stats.Point) int { return pt.Cool }, hudCoolColor},
+	}
+	for _, s := range series {
+		// 階段状に描く（アイテム数はターンの途中で変わる）
+		for i := 1; i < len(st.Items); i++ {
+			a, b := st.Items[i-1], st.Items[i]
+			vector.StrokeLine(screen, px(a.Turn), py(s.value(a)), px(b.Turn), py(s.value(a)), 2, s.c, false)
+			vector.StrokeLine(screen, px(b.Turn), py(s.value(a)), px(b.Turn), py(s.value(b)), 2, s.c, false)
+		}
+	}
+}
+
+// drawTable は (x, y) を左上とする幅 w の領域に行動などの集計表を描く
+func (p *StatsPanel) drawTable(screen *ebiten.Image, snap *server.BoardSnapshot, x, y, w int) {
+	t := p.text
+	lh := t.LineHeight()
+	st := &p.stats
+	colW := w / 3
+	hotX, coolX := x+colW, x+2*colW
+
+	t.Draw(screen, t.Fit(snap.HotName, colW-4), hotX, y, hudHotColor)
+	t.Draw(screen, t.Fit(snap.CoolName, colW-4), coolX, y, hudCoolColor)
+	row := func(i int, label string, hot, cool int) {
+		ry := y + (i+1)*lh
+		t.Draw(screen, t.Fit(label, colW-4), x, ry, textColor)
+		t.Draw(screen, fmt.Sprint(hot), hotX, ry, textColor)
+		t.Draw(screen, fmt.Sprint(cool), coolX, ry, textColor)
+	}
+	for i, a := range stats.Actions {
+		row(i, statsActionLabels[a], st.Hot.Actions[a], st.Cool.Actions[a])
+	}
+	n := len(stats.Actions)
+	row(n, "Distance", st.Hot.Distance, st.Cool.Distance)
+	row(n+1, "Blocks", st.Hot.BlocksPlaced, st.Cool.BlocksPlaced)
+	row(n+2, "Items", snap.HotItems, snap.CoolItems)
+}
@@ -0,0 +1,109 @@
+package gui
+
+import (
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/bitmapfont/v3"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+)
+
+// fontFace は画面の文字に使う日本語対応のビットマップフォント（12px）
+// 「Ⅲ」などの East Asian Ambiguous な文字も全角で描く
+var fontFace = text.NewGoXFace(bitmapfont.FaceEA)
+
+// TextScales は文字の倍率の候補（プロジェクター投影時などに +/- キーで切り替える）
+var TextScales = []float64{1, 1.5, 2}
+
+// 1倍のときの行送りと HUD の余白
+const (
+	lineHeight   = 20
+	hudPadTop    = 8
+	hudPadBottom = 12 // リプレイのスクラブバーと重ならないよう下に空ける
+	hudLines     = 4  // ターン・Hot・Cool・勝敗
+)
+
+var textColor = color.RGBA{R: 230, G: 230, B: 230, A: 255}
+
+// Text は文字の倍率を管理し、文字の描画と幅の計算を行う
+// App が1つ作成し、HUD・BoardRenderer・Replay で共有する
+type Text struct {
+	scale int // TextScales のインデックス
+}
+
+// NewText は倍率 scale の Text を作成する
+func NewText(scale float64) *Text {
+	t := &Text{}
+	t.SetScale(scale)
+	return t
+}
+
+// SetScale は倍率を設定する（TextScales にない倍率は最も近い候補に丸める）
+func (t *Text) SetScale(scale float64) {
+	t.scale = 0
+	for i, s := range TextScales {
+		if math.Abs(s-scale) < math.Abs(TextScales[t.scale]-scale) {
+			t.scale = i
+		}
+	}
+}
+
+// Scale は現在の倍率を返す
+func (t *Text) Scale() float64 {
+	return TextScales[t.scale]
+}
+
+// Larger は倍率を1段階上げる（最大なら何もしない）
+func (t *Text) Larger() {
+	t.scale = min(t.scale+1, len(TextScales)-1)
+}
+
+// Smaller は倍率を1段階下げる（最小なら何もしない）
+func (t *Text) Smaller() {
+	t.scale = max(t.scale-1, 0)
+}
+
+// LineHeight は1行の高さ（px）を返す
+func (t *Text) LineHeight() int {
+	return int(math.Ceil(lineHeight * t.Scale()))
+}
+
+// HUDHeight は現在の倍率で HUD に必要な高さを返す（HUDHeight 未満にはしない）
+func (t *Text) HUDHeight() int {
+	s := t.Scale()
+	return max(HUDHeight, int(math.Ceil(hudPadTop*s))+hudLines*t.LineHeight()+hudPadBottom)
+}
+
+// Width は s を描いたときの幅（px）を返す
+func (t *Text) Width(s string) int {
+	return int(math.Ceil(text.Advance(s, fontFace) * t.Scale()))
+}
+
+// Draw は (x, y) を左上として s を描く
+func (t *Text) Draw(screen *ebiten.Image, s string, x, y int, clr color.Color) {
+	op := &text.DrawOptions{}
+	op.GeoM.Scale(t.Scale(), t.Scale())
+	op.GeoM.Translate(float64(x), float64(y))
+	op.ColorScale.ScaleWithColor(clr)
+	text.Draw(screen, s, fontFace, op)
+}
+
+// DrawCentered は横方向の中心を cx として s を描く
+func (t *Text) DrawCentered(screen *ebiten.Image, s string, cx, y int, clr color.Color) {
+	t.Draw(screen, s, cx-t.Width(s)/2, y, clr)
+}
+
+// Fit は s が幅 width に収まらない場合、末尾を "…" にして切り詰める
+func (t *Text) Fit(s string, width int) string {
+	if t.Width(s) <= width {
+		return s
+	}
+	rs := []rune(s)
+	for n := len(rs) - 1; n > 0; n-- {
+		if c := string(rs[:n]) + "…"; t.Width(c) <= width {
+			return c
+		}
+	}
+	return ""
+}
@@ -0,0 +1,144 @@
+package gui
+
+import (
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"github.com/kqnade/CHaserGo/server"
+	"github.com/kqnade/CHaserGo/stats"
+)
+
+// trailLength は軌跡として表示する位置の数
+const trailLength = 24
+
+// 軌跡の不透明度（古い端から新しい端へ濃くする）と、ヒートマップの不透明度の範囲
+const (
+	trailMinAlpha = 0.1
+	trailMaxAlpha = 0.8
+	heatMinAlpha  = 0.15
+	heatMaxAlpha  = 0.7
+)
+
+// movementOverlay は各プレイヤーの軌跡と、マスの訪問回数のヒートマップ
+// 表示中のスナップショットまでの履歴から作り、表示中の Revision が変わったときだけ作り直す
+type movementOverlay struct {
+	showTrails bool
+	heatIndex  int            // 0 は非表示、i は heatLayers の i-1 番目の層
+	static     *stats.Heatmap // SetHeatmap で設定した複数の対戦のヒートマップ（nil なら表示中の対戦の履歴から作る）
+
+	revision  uint64
+	computed  bool
+	heat      *stats.Heatmap
+	hotTrail  []server.Position
+	coolTrail []server.Position
+}
+
+// update は snap までの履歴で軌跡とヒートマップを作り直す（snap が前回と同じなら何もしない）
+func (m *movementOverlay) update(snap *server.BoardSnapshot, history func(rev uint64) []server.BoardSnapshot) {
+	if !m.showTrails && m.heatIndex == 0 {
+		m.computed = false
+		return
+	}
+	if history == nil || (m.computed && snap.Revision == m.revision) {
+		return
+	}
+	m.revision, m.computed = snap.Revision, true
+	snaps := history(snap.Revision)
+	m.hotTrail = stats.Trail(snaps, true, trailLength)
+	m.coolTrail = stats.Trail(snaps, false, trailLength)
+	m.heat = m.static
+	if m.heat == nil {
+		m.heat = stats.NewHeatmap(snap.Width, snap.Height)
+		_ = m.heat.Add(snaps) // 同じ対戦の履歴なので大きさは一致する
+	}
+}
+
+// heatLayers はヒートマップで切り替える層（先攻・後攻とプレイヤー名の組）を返す
+func (m *movementOverlay) heatLayers(snap *server.BoardSnapshot) []stats.Layer {
+	if m.static != nil {
+		return m.static.Layers()
+	}
+	return []stats.Layer{{Hot: true, Name: snap.HotName}, {Name: snap.CoolName}}
+}
+
+// heatLayer は表示中のヒートマップの層を返す（非表示なら false）
+func (m *movementOverlay) heatLayer(snap *server.BoardSnapshot) (stats.Layer, bool) {
+	layers := m.heatLayers(snap)
+	if m.heatIndex == 0 || m.heatIndex > len(layers) {
+		return stats.Layer{}, false
+	}
+	return layers[m.heatIndex-1], true
+}
+
+// layerColor は層 l の表示色（Hot・Cool の色）を返す
+func layerColor(l stats.Layer) color.RGBA {
+	if l.Hot {
+		return hudHotColor
+	}
+	return hudCoolColor
+}
+
+// drawHeatmap は表示中のプレイヤーの訪問回数を、多いマスほど濃く塗る
+func (m *movementOverlay) drawHeatmap(screen *ebiten.Image, v boardView, snap *server.BoardSnapshot) {
+	layer, ok := m.heatLayer(snap)
+	if !ok || m.heat == nil || m.heat.Width != v.width || m.heat.Height != v.height {
+		return
+	}
+	visits, most := m.heat.Visits[layer], m.heat.Max(layer)
+	if most == 0 {
+		return
+	}
+	c := layerColor(layer)
+	for i, n := range visits {
+		if n == 0 {
+			continue
+		}
+		a := heatMinAlpha + (heatMaxAlpha-heatMinAlpha)*float32(n)/float32(most)
+		v.fillCell(screen, server.Position{X: i % v.width, Y: i / v.width}, withAlpha(c, a))
+	}
+}
+
+// drawTrail は trail（古い順）を、新しい区間ほど濃い線で結ぶ
+func drawTrail(screen *ebiten.Image, v boardView, trail []server.Position, c color.RGBA) {
+	width := max(2, float32(v.tileSize)/6)
+	for i := 1; i < len(trail); i++ {
+		a := trailMinAlpha + (trailMaxAlpha-trailMinAlpha)*float32(i)/float32(len(trail)-1)
+		x0, y0 := v.center(trail[i-1])
+		x1, y1 := v.center(trail[i])
+		vector.StrokeLine(screen, x0, y0, x1, y1, width, withAlpha(c, a), true)
+		vector.FillCircle(screen, x1, y1, width, withAlpha(c, a), true)
+	}
+}
+
+// ToggleTrails は軌跡の表示・非表示を切り替える
+func (r *BoardRenderer) ToggleTrails() {
+	r.movement.showTrails = !r.movement.showTrails
+}
+
+// NextHeatmap はヒートマップを 非表示 → Hot・Cool（複数の対戦では各層）→ 非表示 の順に切り替える
+func (r *BoardRenderer) NextHeatmap() {
+	r.movement.heatIndex++
+}
+
+// SetHeatmap は表示中の対戦の代わりに使うヒートマップ（複数の対戦を積み上げたもの）を設定する
+func (r *BoardRenderer) SetHeatmap(h *stats.Heatmap) {
+	r.movement.static = h
+	r.movement.computed = false
+}
+
+// drawMovement は軌跡とヒートマップを描く
+func (r *BoardRenderer) drawMovement(screen *ebiten.Image, v boardView, snap *server.BoardSnapshot, history func(rev uint64) []server.BoardSnapshot) {
+	m := &r.movement
+	if layers := m.heatLayers(snap); m.heatIndex > len(layers) {
+		m.heatIndex = 0
+	}
+	m.update(snap, history)
+	if m.heatIndex > 0 {
+		m.drawHeatmap(screen, v, snap)
+	}
+	if m.showTrails {
+		drawTrail(screen, v, m.hotTrail, hudHotColor)
+		drawTrail(screen, v, m.coolTrail, hudCoolColor)
+	}
+}
@@ -0,0 +1,251 @@
+package mapgen
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Stats はマップの到達可能性と公平性の統計
+type Stats struct {
+	Blocks int
+	Items  int
+	// Unreachable はどちらかのスタート位置から到達できない空きマス・アイテムの数
+	Unreachable      int
+	UnreachableItems int
+	// HotEnclosed, CoolEnclosed はスタート位置の四方が壁（盤面外を含む）で塞がれていることを示す
+	HotEnclosed  bool
+	CoolEnclosed bool
+	// StartDistance は Hot と Cool の間の最短距離（到達できない場合は -1）
+	StartDistance int
+	// HotScore, CoolScore は各アイテムを 1/(1+距離) で重み付けした合計
+	HotScore  float64
+	CoolScore float64
+	// Advantage は (HotScore-CoolScore)/(HotScore+CoolScore)。正なら Hot 有利（-1〜1）
+	Advantage float64
+}
+
+// Fairness はマップ生成時の到達可能性・公平性の条件
+type Fairness struct {
+	// Repair が true の場合、到達できないマスがあればブロックを取り除いて修復する
+	// false の場合はそのマップを破棄して作り直す
+	Repair bool
+	// MaxAdvantage は許容する |Stats.Advantage| の上限（0 以下で無制限）
+	MaxAdvantage float64
+	// MaxAttempts は条件を満たすまでに生成を試す回数（0 以下で 100）
+	MaxAttempts int
+}
+
+func (s Stats) String() string {
+	return fmt.Sprintf("blocks=%d items=%d unreachable=%d (items %d) start-distance=%d hot=%.2f cool=%.2f advantage=%+.3f",
+		s.Blocks, s.Items, s.Unreachable, s.UnreachableItems, s.StartDistance, s.HotScore, s.CoolScore, s.Advantage)
+}
+
+// Check は統計が条件を満たすか確認し、満たさない理由をまとめて返す
+func (s Stats) Check(maxAdvantage float64) error {
+	var errs []error
+	if s.HotEnclosed {
+		errs = append(errs, errors.New("hot start is enclosed"))
+	}
+	if s.CoolEnclosed {
+		errs = append(errs, errors.New("cool start is enclosed"))
+	}
+	if s.Unreachable > 0 {
+		errs = append(errs, fmt.Errorf("%d cells (%d items) are unreachable from a start", s.Unreachable, s.UnreachableItems))
+	}
+	if maxAdvantage > 0 && (s.Advantage > maxAdvantage || s.Advantage < -maxAdvantage) {
+		errs = append(errs, fmt.Errorf("item advantage %+.3f exceeds %.3f", s.Advantage, maxAdvantage))
+	}
+	return errors.Join(errs...)
+}
+
+// GenerateFairMap は f の条件を満たすマップが得られるまで GenerateMapSymmetric を繰り返す
+func (g *Generator) GenerateFairMap(width, height, maxBlocks, maxItems int, sym Symmetry, f Fairness) (*Map, error) {
+	return g.GenerateFair(legacyOptions(width, height, maxBlocks, maxItems, sym), f)
+}
+
+// GenerateFair は f の条件を満たすマップが得られるまで Generate を繰り返す
+func (g *Generator) GenerateFair(opts Options, f Fairness) (*Map, error) {
+	attempts := f.MaxAttempts
+	if attempts <= 0 {
+		attempts = 100
+	}
+	var lastErr error
+	for i := 0; i < attempts; i++ {
+		m, err := g.Generate(opts)
+		if err != nil {
+			return nil, err
+		}
+		if f.Repair {
+			m.Repair()
+		}
+		if lastErr = m.Stats().Check(f.MaxAdvantage); lastErr == nil {
+			return m, nil
+		}
+	}
+	return nil, fmt.Errorf("no map satisfied the fairness conditions in %d attempts: %w", attempts, lastErr)
+}
+
+// Stats はマップの到達可能性と公平性を計算する
+func (m *Map) Stats() Stats {
+	hotDist := m.distances(m.Hot)
+	coolDist := m.distances(m.Cool)
+
+	s := Stats{
+		HotEnclosed:   m.enclosed(m.Hot),
+		CoolEnclosed:  m.enclosed(m.Cool),
+		StartDistance: hotDist[m.Cool.Y][m.Cool.X],
+	}
+	for y := 0; y < m.Height; y++ {
+		for x := 0; x < m.Width; x++ {
+			cell := m.Data[y][x]
+			if cell == Block {
+				s.Blocks++
+				continue
+			}
+			hd, cd := hotDist[y][x], coolDist[y][x]
+			if hd < 0 || cd < 0 {
+				s.Unreachable++
+				if cell == Item {
+					s.UnreachableItems++
+				}
+			}
+			if cell != Item {
+				continue
+			}
+			s.Items++
+			if hd >= 0 {
+				s.HotScore += 1 / float64(1+hd)
+			}
+			if cd >= 0 {
+				s.CoolScore += 1 / float64(1+cd)
+			}
+		}
+	}
+	if total := s.HotScore + s.CoolScore; total > 0 {
+		s.Advantage = (s.HotScore - s.CoolScore) / total
+	}
+	return s
+}
+
+// Repair は両スタートから到達できないマスがなくなるまでブロックを取り除き、取り除いた数を返す
+// 対称性を保つため、Symmetry で対応する位置のブロックも同時に取り除く
+func (m *Map) Repair() int {
+	removed := 0
+	for {
+		target, ok := m.repairTarget()
+		if !ok {
+			return removed
+		}
+		for _, p := range orbit(m.Symmetry, m.Width, m.Height, target) {
+			if m.Data[p.Y][p.X] == Block {
+				m.Data[p.Y][p.X] = Empty
+				removed++
+			}
+		}
+	}
+}
+
+var neighborOffsets = []Position{{X: 0, Y: -1}, {X: 0, Y: 1}, {X: -1, Y: 0}, {X: 1, Y: 0}}
+
+func (m *Map) inBounds(p Position) bool {
+	return p.X >= 0 && p.X < m.Width && p.Y >= 0 && p.Y < m.Height
+}
+
+// enclosed は pos の四方が全てブロックまたは盤面外かを返す
+func (m *Map) enclosed(pos Position) bool {
+	for _, d := range neighborOffsets {
+		n := Position{X: pos.X + d.X, Y: pos.Y + d.Y}
+		if m.inBounds(n) && m.Data[n.Y][n.X] != Block {
+			return false
+		}
+	}
+	return true
+}
+
+// distances は from からブロック以外のマスを通る最短距離を返す（到達不可は -1）
+func (m *Map) distances(from Position) [][]int {
+	dist := make([][]int, m.Height)
+	for y := range dist {
+		dist[y] = make([]int, m.Width)
+		for x := range dist[y] {
+			dist[y][x] = -1
+		}
+	}
+	if !m.inBounds(from) || m.Data[from.Y][from.X] == Block {
+		return dist
+	}
+
+	dist[from.Y][from.X] = 0
+	queue := []Position{from}
+	for len(queue) > 0 {
+		p := queue[0]
+		queue = queue[1:]
+		for _, d := range neighborOffsets {
+			n := Position{X: p.X + d.X, Y: p.Y + d.Y}
+			if m.inBounds(n) && m.Data[n.Y][n.X] != Block && dist[n.Y][n.X] < 0 {
+				dist[n.Y][n.X] = dist[p.Y][p.X] + 1
+				queue = append(queue, n)
+			}
+		}
+	}
+	return dist
+}
+
+// repairTarget は Hot から到達できる領域を広げるために取り除くべきブロックを返す
+//
+// 到達可能領域からブロックを通り抜けてよい BFS を行い、最も近い到達不能な
+// マスへの経路上で最初に通るブロックを選ぶ。
+func (m *Map) repairTarget() (Position, bool) {
+	reach := m.distances(m.Hot)
+	parent := make(map[Position]Position)
+	var queue []Position
+	for y := 0; y < m.Height; y++ {
+		for x := 0; x < m.Width; x++ {
+			if reach[y][x] >= 0 {
+				p := Position{X: x, Y: y}
+				parent[p] = p
+				queue = append(queue, p)
+			}
+		}
+	}
+
+	for len(queue) > 0 {
+		p := queue[0]
+		queue = queue[1:]
+		if reach[p.Y][p.X] < 0 && m.Data[p.Y][p.X] != Block {
+			// 経路を遡り、到達可能領域に接する最初のブロックを探す
+			for {
+				prev := parent[p]
+				if reach[prev.Y][prev.X] >= 0 {
+					return p, true
+				}
+				p = prev
+			}
+		}
+		for _, d := range neighborOffsets {
+			n := Position{X: p.X + d.X, Y: p.Y + d.Y}
+			if _, seen := parent[n]; !seen && m.inBounds(n) {
+				parent[n] = p
+				queue = append(queue, n)
+			}
+		}
+	}
+	return Position{}, false
+}
+
+// orbit は sym のもとで pos と同じ扱いになる位置の一覧を返す（pos を含む）
+func orbit(sym Symmetry, width, height int, pos Position) []Position {
+	if sym == SymmetryRotate4 && width == height {
+		ps := []Position{pos}
+		p := pos
+		for i := 0; i < 3; i++ {
+			p = Position{X: width - 1 - p.Y, Y: p.X}
+			ps = append(ps, p)
+		}
+		return ps
+	}
+	if p, ok := counterpart(sym, width, height, pos); ok {
+		return []Position{pos, p}
+	}
+	return []Position{pos}
+}
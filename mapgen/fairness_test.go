@@ -0,0 +1,147 @@
+package mapgen
+
+import (
+	"strings"
+	"testing"
+)
+
+// parseTestMap は String() と同じ記号（# * . H C）で書いたマップを読む
+func parseTestMap(rows ...string) *Map {
+	m := &Map{Width: len(rows[0]), Height: len(rows), Data: make([][]CellType, len(rows)), Turns: 120}
+	for y, row := range rows {
+		m.Data[y] = make([]CellType, len(row))
+		for x, c := range row {
+			switch c {
+			case '#':
+				m.Data[y][x] = Block
+			case '*':
+				m.Data[y][x] = Item
+			case 'H':
+				m.Hot = Position{X: x, Y: y}
+			case 'C':
+				m.Cool = Position{X: x, Y: y}
+			}
+		}
+	}
+	return m
+}
+
+func TestStats(t *testing.T) {
+	m := parseTestMap(
+		"H....",
+		"..#..",
+		".#*#.",
+		"..#..",
+		"....C",
+	)
+	s := m.Stats()
+	if s.Blocks != 4 || s.Items != 1 {
+		t.Errorf("blocks=%d items=%d, want 4 and 1", s.Blocks, s.Items)
+	}
+	if s.Unreachable != 1 || s.UnreachableItems != 1 {
+		t.Errorf("unreachable=%d items=%d, want 1 and 1", s.Unreachable, s.UnreachableItems)
+	}
+	if s.StartDistance != 8 {
+		t.Errorf("start distance = %d, want 8", s.StartDistance)
+	}
+	if s.HotEnclosed || s.CoolEnclosed {
+		t.Error("starts reported as enclosed")
+	}
+	if err := s.Check(0); err == nil || !strings.Contains(err.Error(), "unreachable") {
+		t.Errorf("Check = %v, want unreachable error", err)
+	}
+}
+
+func TestStatsEnclosedAndAdvantage(t *testing.T) {
+	m := parseTestMap(
+		"H#...",
+		"#....",
+		"...*.",
+		"....*",
+		"....C",
+	)
+	s := m.Stats()
+	if !s.HotEnclosed || s.CoolEnclosed {
+		t.Errorf("enclosed = %v,%v, want true,false", s.HotEnclosed, s.CoolEnclosed)
+	}
+	if s.StartDistance != -1 {
+		t.Errorf("start distance = %d, want -1", s.StartDistance)
+	}
+	if s.HotScore != 0 || s.Advantage != -1 {
+		t.Errorf("hot score = %v advantage = %v, want 0 and -1", s.HotScore, s.Advantage)
+	}
+	err := s.Check(0.5)
+	for _, want := range []string{"hot start is enclosed", "advantage"} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Check = %v, want error containing %q", err, want)
+		}
+	}
+}
+
+func TestRepair(t *testing.T) {
+	m := parseTestMap(
+		"H.....",
+		".####.",
+		".#*.#.",
+		".#.*#.",
+		".####.",
+		".....C",
+	)
+	m.Symmetry = SymmetryPoint
+	removed := m.Repair()
+	if removed != 2 {
+		t.Errorf("removed %d blocks, want 2 (one and its counterpart)", removed)
+	}
+	if err := m.Stats().Check(0); err != nil {
+		t.Errorf("after repair: %v", err)
+	}
+	for y := 0; y < m.Height; y++ {
+		for x := 0; x < m.Width; x++ {
+			if m.Data[y][x] != m.Data[m.Height-1-y][m.Width-1-x] {
+				t.Fatalf("repair broke point symmetry at (%d,%d):\n%s", x, y, m)
+			}
+		}
+	}
+}
+
+func TestRepairEnclosedStart(t *testing.T) {
+	m := parseTestMap(
+		"......",
+		".###..",
+		".#H#..",
+		".###..",
+		"......",
+		".....C",
+	)
+	if !m.Stats().HotEnclosed {
+		t.Fatal("hot should be enclosed before repair")
+	}
+	if m.Repair() != 1 {
+		t.Errorf("want 1 block removed with no symmetry")
+	}
+	if s := m.Stats(); s.HotEnclosed || s.Check(0) != nil {
+		t.Errorf("after repair: %v", s.Check(0))
+	}
+}
+
+func TestGenerateFairMap(t *testing.T) {
+	for _, sym := range Symmetries {
+		gen := NewGeneratorWithSeed(7)
+		m, err := gen.GenerateFairMap(15, 17, 30, 10, sym, Fairness{Repair: true, MaxAdvantage: 0.2})
+		if err != nil {
+			t.Errorf("%s: %v", sym, err)
+			continue
+		}
+		s := m.Stats()
+		if err := s.Check(0.2); err != nil {
+			t.Errorf("%s: generated map fails check: %v", sym, err)
+		}
+		t.Logf("%s: %v", sym, s)
+	}
+
+	// 到達不能なマスを修復せず、ありえない条件を課すと失敗する
+	gen := NewGeneratorWithSeed(7)
+	if _, err := gen.GenerateFairMap(15, 17, 9, 10, SymmetryNone, Fairness{MaxAdvantage: 1e-9, MaxAttempts: 3}); err == nil {
+		t.Error("expected error for unsatisfiable fairness")
+	}
+}
@@ -1,27 +1,26 @@
 package mapgen
 
 import (
+	"errors"
 	"fmt"
 	"math/rand"
-	"os"
 	"path/filepath"
 	"time"
+
+	"github.com/kqnade/CHaserGo/chasermap"
 )
 
 // CellType represents a cell on the map
-type CellType int
+type CellType = chasermap.CellType
 
 const (
-	Empty CellType = 0
-	Block CellType = 2
-	Item  CellType = 3
+	Empty = chasermap.Empty
+	Block = chasermap.Block
+	Item  = chasermap.Item
 )
 
 // Position represents a coordinate
-type Position struct {
-	X int
-	Y int
-}
+type Position = chasermap.Position
 
 // Map represents a game map
 type Map struct {
@@ -31,6 +30,10 @@ type Map struct {
 	Hot    Position
 	Cool   Position
 	Turns  int
+	// Symmetry は生成時の対称性（N 行に記録される。空の場合は記録しない）
+	Symmetry Symmetry
+	// Style は生成時のスタイル（StyleScatter 以外の場合は N 行に記録される）
+	Style Style
 }
 
 // Generator generates CHaser maps
@@ -52,40 +55,108 @@ func NewGeneratorWithSeed(seed int64) *Generator {
 	}
 }
 
+// 既定のマップサイズ（GenerateMap が生成するサイズ）
+const (
+	DefaultWidth  = 15
+	DefaultHeight = 17
+)
+
+// MinSize は GenerateMapSize が受け付ける幅・高さの最小値
+// 小マップは最外周を除いてブロックを置くため、一辺が3マス以上必要になる
+const MinSize = 6
+
 // GenerateMap generates a random map
+// maxBlocks, maxItems は小マップ1枚あたりの上限（0〜上限の間でランダムに決まり、
+// 小マップに置ききれない上限は置ける数に丸める）
 func (g *Generator) GenerateMap(maxBlocks, maxItems int) *Map {
-	if maxBlocks < 0 {
-		maxBlocks = 0
+	// 既定サイズで上限を丸めるため失敗しない
+	m, _ := g.GenerateMapSymmetric(DefaultWidth, DefaultHeight, maxBlocks, maxItems, SymmetryRotate4)
+	return m
+}
+
+// GenerateMapSize は width×height のランダムマップを生成する
+//
+// 正方形では左上の小マップを90°ずつ回転させた4枚を四隅に配置して90°回転対称に、
+// それ以外では上半分の小マップ2枚を180°回転させて点対称にする。小マップで
+// 覆われない中央の列・行（奇数辺の場合）は空白になる。
+func (g *Generator) GenerateMapSize(width, height, maxBlocks, maxItems int) (*Map, error) {
+	return g.GenerateMapSymmetric(width, height, maxBlocks, maxItems, SymmetryRotate4)
+}
+
+// GenerateMapSymmetric は対称性 sym を指定して width×height のランダムマップを生成する
+// エージェントも sym に従って対応する位置に配置する（SymmetryNone ではそれぞれランダム）
+func (g *Generator) GenerateMapSymmetric(width, height, maxBlocks, maxItems int, sym Symmetry) (*Map, error) {
+	return g.Generate(legacyOptions(width, height, maxBlocks, maxItems, sym))
+}
+
+// legacyOptions は上限だけを指定する従来の引数を Options に変換する
+func legacyOptions(width, height, maxBlocks, maxItems int, sym Symmetry) Options {
+	opts := DefaultOptions()
+	opts.Width, opts.Height, opts.Symmetry = width, height, sym
+	opts.Blocks, opts.Items = UpTo(maxBlocks), UpTo(maxItems)
+	opts.ClampCounts()
+	return opts
+}
+
+// ValidateSize はマップサイズが生成可能か確認する
+func ValidateSize(width, height int) error {
+	if width < MinSize || height < MinSize {
+		return fmt.Errorf("invalid map size %dx%d: width and height must be at least %d", width, height, MinSize)
 	}
-	if maxItems < 0 {
-		maxItems = 0
+	return nil
+}
+
+// quadrantSize は正方形の盤面で SymmetryRotate4 の左上に置く小マップの幅と高さを返す
+// （15×15 では 7×8。回転した小マップと噛み合って盤面全体を覆う）
+func quadrantSize(n int) (qw, qh int) {
+	return n / 2, n - n/2
+}
+
+// quadrantDims は sym で置く小マップ1枚の幅と高さを返す
+// 回転しないモードでは奇数辺の中央列・行が隙間になる
+func quadrantDims(width, height int, sym Symmetry) (qw, qh int) {
+	if layoutSymmetry(width, height, sym) == SymmetryRotate4 {
+		return quadrantSize(width)
 	}
-	// 小マップ（7×8）を生成
-	smallMap := g.generateSmallMap(7, 8, maxBlocks, maxItems)
+	return width / 2, height / 2
+}
 
-	// 4つの回転バリエーションを作成
-	map0 := smallMap
-	map1 := g.rotateMap(map0)
-	map2 := g.rotateMap(map1)
-	map3 := g.rotateMap(map2)
+// layoutSymmetry は width×height の盤面で小マップを並べるときの対称性を返す
+// 90°回転した小マップは正方形の盤面にしか収まらないため、正方形でない盤面の
+// SymmetryRotate4 は SymmetryPoint と同じく上半分の2枚を180°回転して並べる
+func layoutSymmetry(width, height int, sym Symmetry) Symmetry {
+	if sym == SymmetryRotate4 && width != height {
+		return SymmetryPoint
+	}
+	return sym
+}
 
-	// 隙間用マップ（8×2）を生成
-	gapMap1 := g.generateGapMap(8, 2)
-	gapMap2 := g.generateGapMap(8, 2)
+// generate は検証済みの opts でマップを生成する
+func (g *Generator) generate(opts Options) (*Map, error) {
+	// 対称性に応じた4つの小マップを作成
+	tl, tr, br, bl, err := g.quadrants(opts)
+	if err != nil {
+		return nil, err
+	}
 
-	// 大マップ（15×17）に結合
-	largeMap := g.jointMaps(map0, map1, map2, map3, gapMap1, gapMap2)
+	// 大マップに結合
+	largeMap := g.jointMaps(opts.Width, opts.Height, tl, tr, br, bl)
+	largeMap.Symmetry = opts.Symmetry
+	largeMap.Style = opts.Style
 
-	// エージェントを対角配置
-	g.placeAgents(largeMap)
+	// エージェントを対称な位置に配置
+	if err := g.placeAgents(largeMap, opts.Symmetry, opts.MinStartDistance); err != nil {
+		return nil, err
+	}
 
-	largeMap.Turns = 120
+	largeMap.Turns = opts.Turns
 
-	return largeMap
+	return largeMap, nil
 }
 
 // generateSmallMap generates a small map with random blocks and items
-func (g *Generator) generateSmallMap(width, height, maxBlocks, maxItems int) *Map {
+// 数は Options.Validate で小マップに収まることを確認済みであること
+func (g *Generator) generateSmallMap(width, height int, blocks, items Range) *Map {
 	m := &Map{
 		Width:  width,
 		Height: height,
@@ -97,52 +168,47 @@ func (g *Generator) generateSmallMap(width, height, maxBlocks, maxItems int) *Ma
 	}
 
 	// ブロックをランダム配置（最外周を除く）
-	blockCount := g.rng.Intn(maxBlocks + 1)
+	blockCount := g.pick(blocks)
 	for i := 0; i < blockCount; i++ {
-		for attempts := 0; attempts < 100; attempts++ {
-			x := g.rng.Intn(width-2) + 1
-			y := g.rng.Intn(height-2) + 1
-
-			if m.Data[y][x] == Empty {
-				m.Data[y][x] = Block
-				break
-			}
-		}
+		g.placeCell(m, Block, 1, 1, width-2, height-2)
 	}
 
 	// アイテムをランダム配置
-	itemCount := g.rng.Intn(maxItems + 1)
+	itemCount := g.pick(items)
 	for i := 0; i < itemCount; i++ {
-		for attempts := 0; attempts < 100; attempts++ {
-			x := g.rng.Intn(width)
-			y := g.rng.Intn(height)
-
-			if m.Data[y][x] == Empty {
-				m.Data[y][x] = Item
-				break
-			}
-		}
+		g.placeCell(m, Item, 0, 0, width, height)
 	}
 
 	return m
 }
 
-// generateGapMap generates a gap map
-func (g *Generator) generateGapMap(width, height int) *Map {
-	m := &Map{
-		Width:  width,
-		Height: height,
-		Data:   make([][]CellType, height),
-	}
+// placeCell は (x0,y0) から w×h の範囲の空白マスを1つ選んで cell にする
+// ランダムに選んで埋まっていれば選び直し、100回続けて外れた場合は残りの空白マスから選ぶ
+// 範囲に空白マスが残っていることは呼び出し元が保証する
+func (g *Generator) placeCell(m *Map, cell CellType, x0, y0, w, h int) {
+	for attempts := 0; attempts < 100; attempts++ {
+		x := g.rng.Intn(w) + x0
+		y := g.rng.Intn(h) + y0
 
-	for y := 0; y < height; y++ {
-		m.Data[y] = make([]CellType, width)
+		if m.Data[y][x] == Empty {
+			m.Data[y][x] = cell
+			return
+		}
 	}
 
-	return m
+	var free []Position
+	for y := y0; y < y0+h; y++ {
+		for x := x0; x < x0+w; x++ {
+			if m.Data[y][x] == Empty {
+				free = append(free, Position{X: x, Y: y})
+			}
+		}
+	}
+	p := free[g.rng.Intn(len(free))]
+	m.Data[p.Y][p.X] = cell
 }
 
-// rotateMap rotates a map 90 degrees clockwise
+// rotateMap rotates a map 90 degrees counterclockwise
 func (g *Generator) rotateMap(m *Map) *Map {
 	// 転置（transpose）
 	transposed := &Map{
@@ -175,36 +241,30 @@ func (g *Generator) rotateMap(m *Map) *Map {
 	return rotated
 }
 
-// jointMaps combines 4 small maps and 2 gap maps into a large map
-func (g *Generator) jointMaps(m0, m1, m2, m3, gap1, gap2 *Map) *Map {
-	// 15×17の大マップを作成
+// jointMaps は小マップ4枚を width×height の大マップの四隅に配置する
+// 小マップで覆われない中央の隙間は空白のまま
+func (g *Generator) jointMaps(width, height int, tl, tr, br, bl *Map) *Map {
 	largeMap := &Map{
-		Width:  15,
-		Height: 17,
-		Data:   make([][]CellType, 17),
+		Width:  width,
+		Height: height,
+		Data:   make([][]CellType, height),
 	}
 
-	for y := 0; y < 17; y++ {
-		largeMap.Data[y] = make([]CellType, 15)
+	for y := 0; y < height; y++ {
+		largeMap.Data[y] = make([]CellType, width)
 	}
 
-	// 左上（m0: 7×8）
-	g.copyRegion(largeMap, m0, 0, 0)
-
-	// 右上（m1: 8×7）
-	g.copyRegion(largeMap, m1, 7, 0)
-
-	// 中央隙間1（8×2）
-	g.copyRegion(largeMap, gap1, 0, 7)
+	// 左上
+	g.copyRegion(largeMap, tl, 0, 0)
 
-	// 中央隙間2（8×2）
-	g.copyRegion(largeMap, gap2, 7, 7)
+	// 右上
+	g.copyRegion(largeMap, tr, width-tr.Width, 0)
 
-	// 左下（m2: 7×8）
-	g.copyRegion(largeMap, m2, 0, 9)
+	// 右下
+	g.copyRegion(largeMap, br, width-br.Width, height-br.Height)
 
-	// 右下（m3: 8×7）
-	g.copyRegion(largeMap, m3, 7, 9)
+	// 左下
+	g.copyRegion(largeMap, bl, 0, height-bl.Height)
 
 	return largeMap
 }
@@ -222,93 +282,119 @@ func (g *Generator) copyRegion(dst, src *Map, offsetX, offsetY int) {
 	}
 }
 
-// placeAgents places agents diagonally
-func (g *Generator) placeAgents(m *Map) {
-	// Coolをランダムに配置
-	for attempts := 0; attempts < 1000; attempts++ {
+// placeAgents places agents at positions corresponding under sym
+// SymmetryRotate4/SymmetryPoint では対角、ミラーでは反転した位置、SymmetryNone では独立にランダム
+// minDistance > 0 の場合は Hot と Cool の最短経路がそれ以上になる位置を選び、見つからなければエラーを返す
+func (g *Generator) placeAgents(m *Map, sym Symmetry, minDistance int) error {
+	randomEmpty := func() (Position, bool) {
 		x := g.rng.Intn(m.Width)
 		y := g.rng.Intn(m.Height)
+		return Position{X: x, Y: y}, m.Data[y][x] == Empty
+	}
+	farEnough := func() bool {
+		return minDistance <= 0 || m.distances(m.Cool)[m.Hot.Y][m.Hot.X] >= minDistance
+	}
 
-		if m.Data[y][x] == Empty {
-			m.Cool = Position{X: x, Y: y}
+	// Coolをランダムに配置
+	for attempts := 0; attempts < 1000; attempts++ {
+		cool, ok := randomEmpty()
+		if !ok {
+			continue
+		}
+		m.Cool = cool
 
-			// Hotを対角に配置
-			m.Hot = Position{
-				X: m.Width - 1 - x,
-				Y: m.Height - 1 - y,
-			}
+		// Hotを対応する位置に配置
+		hot, ok := counterpart(sym, m.Width, m.Height, cool)
+		if !ok {
+			hot, ok = randomEmpty()
+		}
+		m.Hot = hot
 
-			// Hotの位置が空いているか確認
-			if m.Data[m.Hot.Y][m.Hot.X] == Empty {
-				return
-			}
+		// Hotの位置が空いているか確認（対称軸・中心上では同じ位置になる）
+		if ok && m.Data[m.Hot.Y][m.Hot.X] == Empty && m.Hot != m.Cool && farEnough() {
+			return nil
 		}
 	}
 
-	// フォールバック: 対角の角に配置
-	m.Cool = Position{X: 0, Y: 0}
-	m.Hot = Position{X: m.Width - 1, Y: m.Height - 1}
-}
-
-// SaveToFile saves the map to a file in CHaser format
-func (m *Map) SaveToFile(filename string) error {
-	// ディレクトリを作成
-	dir := filepath.Dir(filename)
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		return fmt.Errorf("failed to create directory: %w", err)
+	// フォールバック: 左上から順に空きマスを調べ、最初に条件を満たす組に配置
+	if m.scanAgents(sym, farEnough) {
+		return nil
 	}
-
-	file, err := os.Create(filename)
-	if err != nil {
-		return fmt.Errorf("failed to create file: %w", err)
+	if minDistance > 0 {
+		return fmt.Errorf("could not place agents at least %d steps apart", minDistance)
 	}
+	return errors.New("could not place agents: no pair of empty cells")
+}
 
-	// エラーハンドリング用のヘルパー関数
-	writeErr := func() error {
-		// ヘッダー情報
-		basename := filepath.Base(filename)
-		if _, err := fmt.Fprintf(file, "N generated%s\n", basename); err != nil {
-			return fmt.Errorf("failed to write name: %w", err)
-		}
-		if _, err := fmt.Fprintf(file, "T %d\n", m.Turns); err != nil {
-			return fmt.Errorf("failed to write turns: %w", err)
-		}
-		if _, err := fmt.Fprintf(file, "S %d,%d\n", m.Height, m.Width); err != nil {
-			return fmt.Errorf("failed to write size: %w", err)
+// scanAgents は左上のマスから行順に Cool の位置を探し、対応する Hot の位置と合わせて
+// 両方が空きマスで ok を満たす最初の組に配置する（見つからなければ false を返し m は変えない）
+// SymmetryNone では Cool から最も遠い空きマスを Hot にする
+func (m *Map) scanAgents(sym Symmetry, ok func() bool) bool {
+	cool, hot := m.Cool, m.Hot
+	for y := 0; y < m.Height; y++ {
+		for x := 0; x < m.Width; x++ {
+			if m.Data[y][x] != Empty {
+				continue
+			}
+			m.Cool = Position{X: x, Y: y}
+			var found bool
+			if m.Hot, found = counterpart(sym, m.Width, m.Height, m.Cool); !found {
+				m.Hot, found = m.farthestEmpty(m.Cool)
+			}
+			if found && m.Data[m.Hot.Y][m.Hot.X] == Empty && m.Hot != m.Cool && ok() {
+				return true
+			}
 		}
+	}
+	m.Cool, m.Hot = cool, hot
+	return false
+}
 
-		// マップデータ
-		for y := 0; y < m.Height; y++ {
-			for x := 0; x < m.Width; x++ {
-				if _, err := fmt.Fprintf(file, "D %d,%d,%d\n", y, x, m.Data[y][x]); err != nil {
-					return fmt.Errorf("failed to write map data at (%d,%d): %w", y, x, err)
-				}
+// farthestEmpty は from 以外の空きマスのうち from から最も遠いものを返す
+// （到達できないマスは到達できるマスより後回しにする）
+func (m *Map) farthestEmpty(from Position) (Position, bool) {
+	dist := m.distances(from)
+	best, found := Position{}, false
+	for y := 0; y < m.Height; y++ {
+		for x := 0; x < m.Width; x++ {
+			p := Position{X: x, Y: y}
+			if m.Data[y][x] != Empty || p == from {
+				continue
+			}
+			if !found || dist[y][x] > dist[best.Y][best.X] {
+				best, found = p, true
 			}
 		}
+	}
+	return best, found
+}
 
-		// エージェント位置
-		if _, err := fmt.Fprintf(file, "H %d,%d\n", m.Hot.Y, m.Hot.X); err != nil {
-			return fmt.Errorf("failed to write hot position: %w", err)
-		}
-		if _, err := fmt.Fprintf(file, "C %d,%d\n", m.Cool.Y, m.Cool.X); err != nil {
-			return fmt.Errorf("failed to write cool position: %w", err)
-		}
+// SaveToFile saves the map to a file in CHaser format
+func (m *Map) SaveToFile(filename string) error {
+	return m.SaveToFileFormat(filename, chasermap.FormatSpace)
+}
 
-		return nil
+// SaveToFileFormat は format の書式でマップをファイルに保存する
+func (m *Map) SaveToFileFormat(filename string, format chasermap.Format) error {
+	name := "generated" + filepath.Base(filename)
+	if m.Symmetry != "" {
+		name += " symmetry=" + string(m.Symmetry)
 	}
-
-	// 書き込み実行
-	if err := writeErr(); err != nil {
-		file.Close() // エラー時も明示的にクローズ
-		return err
+	if m.Style != "" && m.Style != StyleScatter {
+		name += " style=" + string(m.Style)
 	}
+	return m.ChaserMap(name).SaveFormat(filename, format)
+}
 
-	// ファイルを明示的にクローズしてエラーチェック
-	if err := file.Close(); err != nil {
-		return fmt.Errorf("failed to close file: %w", err)
+// ChaserMap は name を付けたマップファイル用の chasermap.Map に変換する（マスは共有しない）
+func (m *Map) ChaserMap(name string) *chasermap.Map {
+	cm := chasermap.New(m.Width, m.Height)
+	cm.Name, cm.Turns = name, m.Turns
+	cm.Hot, cm.Cool = m.Hot, m.Cool
+	for y := range cm.Cells {
+		copy(cm.Cells[y], m.Data[y])
 	}
-
-	return nil
+	return cm
 }
 
 // String returns a string representation of the map
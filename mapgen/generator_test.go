@@ -76,9 +76,69 @@ func TestGenerateMap(t *testing.T) {
 	}
 }
 
+func TestGenerateMapSize(t *testing.T) {
+	tests := []struct {
+		width, height int
+	}{
+		{15, 17},
+		{21, 17},
+		{9, 9},
+		{6, 6},
+		{30, 8},
+	}
+
+	for _, tt := range tests {
+		gen := NewGeneratorWithSeed(42)
+		m, err := gen.GenerateMapSize(tt.width, tt.height, 9, 10)
+		if err != nil {
+			t.Errorf("GenerateMapSize(%d, %d) error: %v", tt.width, tt.height, err)
+			continue
+		}
+
+		if m.Width != tt.width || m.Height != tt.height || len(m.Data) != tt.height || len(m.Data[0]) != tt.width {
+			t.Errorf("Expected map size %dx%d, got %dx%d", tt.width, tt.height, m.Width, m.Height)
+			continue
+		}
+
+		// 点対称であることを確認
+		for y := 0; y < m.Height; y++ {
+			for x := 0; x < m.Width; x++ {
+				if m.Data[y][x] != m.Data[m.Height-1-y][m.Width-1-x] {
+					t.Errorf("%dx%d: not point symmetric at (%d,%d)", tt.width, tt.height, x, y)
+				}
+			}
+		}
+
+		// 正方形の場合は90°回転対称であることを確認
+		if m.Width == m.Height {
+			for y := 0; y < m.Height; y++ {
+				for x := 0; x < m.Width; x++ {
+					if m.Data[y][x] != m.Data[x][m.Width-1-y] {
+						t.Errorf("%dx%d: not rotationally symmetric at (%d,%d)", tt.width, tt.height, x, y)
+					}
+				}
+			}
+		}
+
+		// エージェントが点対称の位置にいることを確認
+		if m.Hot != (Position{X: m.Width - 1 - m.Cool.X, Y: m.Height - 1 - m.Cool.Y}) || m.Hot == m.Cool {
+			t.Errorf("%dx%d: agents not point symmetric: Hot %v, Cool %v", tt.width, tt.height, m.Hot, m.Cool)
+		}
+	}
+}
+
+func TestGenerateMapSizeInvalid(t *testing.T) {
+	gen := NewGenerator()
+	for _, size := range [][2]int{{5, 17}, {15, 5}, {0, 0}, {-1, 9}} {
+		if _, err := gen.GenerateMapSize(size[0], size[1], 9, 10); err == nil {
+			t.Errorf("GenerateMapSize(%d, %d) expected error", size[0], size[1])
+		}
+	}
+}
+
 func TestGenerateSmallMap(t *testing.T) {
 	gen := NewGenerator()
-	m := gen.generateSmallMap(7, 8, 9, 10)
+	m := gen.generateSmallMap(7, 8, UpTo(9), UpTo(10))
 
 	// サイズが正しいことを確認
 	if m.Width != 7 || m.Height != 8 {
@@ -228,3 +288,56 @@ func TestMapString(t *testing.T) {
 
 	t.Log(str) // デバッグ用に出力
 }
+
+func TestPlaceAgentsFallback(t *testing.T) {
+	// 空きマスは対称な1組だけで、角はブロック
+	m := parseTestMap(
+		"#####",
+		"#.###",
+		"#####",
+		"###.#",
+		"#####",
+	)
+	if !m.scanAgents(SymmetryPoint, func() bool { return true }) {
+		t.Fatal("scanAgents found no pair")
+	}
+	if m.Cool != (Position{X: 1, Y: 1}) || m.Hot != (Position{X: 3, Y: 3}) {
+		t.Errorf("Cool %v, Hot %v, want (1,1), (3,3)", m.Cool, m.Hot)
+	}
+	if err := NewGeneratorWithSeed(1).placeAgents(m, SymmetryPoint, 0); err != nil {
+		t.Fatal(err)
+	}
+	if m.Data[m.Cool.Y][m.Cool.X] != Empty || m.Data[m.Hot.Y][m.Hot.X] != Empty {
+		t.Errorf("agents placed on blocks: Cool %v, Hot %v", m.Cool, m.Hot)
+	}
+
+	// 対称な位置が埋まっている場合は配置せずエラーにする
+	m = parseTestMap(
+		"#####",
+		"#.###",
+		"#####",
+		"#####",
+		"#####",
+	)
+	if m.scanAgents(SymmetryPoint, func() bool { return true }) {
+		t.Errorf("scanAgents placed Cool %v, Hot %v without an empty pair", m.Cool, m.Hot)
+	}
+	if err := NewGeneratorWithSeed(1).placeAgents(m, SymmetryPoint, 0); err == nil {
+		t.Error("placeAgents without an empty pair succeeded")
+	}
+
+	// SymmetryNone では Cool から最も遠い空きマスを Hot にする
+	m = parseTestMap(
+		"#####",
+		"#...#",
+		"###.#",
+		"#...#",
+		"#####",
+	)
+	if !m.scanAgents(SymmetryNone, func() bool { return true }) {
+		t.Fatal("scanAgents found no cells")
+	}
+	if m.Cool != (Position{X: 1, Y: 1}) || m.Hot != (Position{X: 1, Y: 3}) {
+		t.Errorf("Cool %v, Hot %v, want (1,1), (1,3)", m.Cool, m.Hot)
+	}
+}
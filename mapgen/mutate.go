@@ -0,0 +1,45 @@
+package mapgen
+
+// Clone はマップの複製を返す（マスは共有しない）
+func (m *Map) Clone() *Map {
+	c := *m
+	c.Data = make([][]CellType, m.Height)
+	for y, row := range m.Data {
+		c.Data[y] = append([]CellType(nil), row...)
+	}
+	return &c
+}
+
+var mutationCells = []CellType{Empty, Block, Item}
+
+// Mutate は m の複製の n 箇所のマスを別の種類（空き・ブロック・アイテム）に書き換えて返す
+// 対称性を保つため、Symmetry で対応する位置も同じ種類にする。スタート位置とその対応位置は変更しない
+// 到達できないマスができることがあるため、必要に応じて Repair や Stats で確認すること
+func (g *Generator) Mutate(m *Map, n int) *Map {
+	c := m.Clone()
+	for i := 0; i < n; i++ {
+		pos := Position{X: g.rng.Intn(c.Width), Y: g.rng.Intn(c.Height)}
+		ps := orbit(c.Symmetry, c.Width, c.Height, pos)
+		if containsStart(c, ps) {
+			continue
+		}
+		// 現在と異なる種類を選ぶ
+		cell := mutationCells[g.rng.Intn(len(mutationCells))]
+		for cell == c.Data[pos.Y][pos.X] {
+			cell = mutationCells[g.rng.Intn(len(mutationCells))]
+		}
+		for _, p := range ps {
+			c.Data[p.Y][p.X] = cell
+		}
+	}
+	return c
+}
+
+func containsStart(m *Map, ps []Position) bool {
+	for _, p := range ps {
+		if p == m.Hot || p == m.Cool {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,34 @@
+package mapgen
+
+import "testing"
+
+func TestMutate(t *testing.T) {
+	for _, sym := range Symmetries {
+		gen := NewGeneratorWithSeed(3)
+		m, err := gen.GenerateMapSymmetric(15, 17, 9, 10, sym)
+		if err != nil {
+			t.Fatal(err)
+		}
+		before := m.String()
+		c := gen.Mutate(m, 10)
+
+		if m.String() != before {
+			t.Fatalf("%s: Mutate changed the original map", sym)
+		}
+		if c.String() == before {
+			t.Errorf("%s: Mutate changed nothing", sym)
+		}
+		if c.Hot != m.Hot || c.Cool != m.Cool || c.Data[c.Hot.Y][c.Hot.X] != Empty || c.Data[c.Cool.Y][c.Cool.X] != Empty {
+			t.Errorf("%s: starts changed: %v %v", sym, c.Hot, c.Cool)
+		}
+		for y := 0; y < c.Height; y++ {
+			for x := 0; x < c.Width; x++ {
+				for _, p := range orbit(sym, c.Width, c.Height, Position{X: x, Y: y}) {
+					if c.Data[p.Y][p.X] != c.Data[y][x] {
+						t.Fatalf("%s: (%d,%d) and %v differ after Mutate", sym, x, y, p)
+					}
+				}
+			}
+		}
+	}
+}
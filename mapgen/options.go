@@ -0,0 +1,170 @@
+package mapgen
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// DefaultTurns は Options.Turns を省略した場合のターン数
+const DefaultTurns = 120
+
+// Range は Min 以上 Max 以下の整数の範囲（Min == Max で固定の数）
+type Range struct {
+	Min int
+	Max int
+}
+
+// Exact は n 固定の Range を返す
+func Exact(n int) Range {
+	return Range{Min: n, Max: n}
+}
+
+// UpTo は 0 以上 n 以下の Range を返す（従来の maxBlocks/maxItems と同じ意味）
+func UpTo(n int) Range {
+	return Range{Min: 0, Max: max(n, 0)}
+}
+
+// ParseRange は "5"（固定）または "3-8"（範囲）の形式の文字列から Range を得る
+func ParseRange(s string) (Range, error) {
+	lo, hi, ranged := strings.Cut(s, "-")
+	if !ranged {
+		hi = lo
+	}
+	a, err1 := strconv.Atoi(strings.TrimSpace(lo))
+	b, err2 := strconv.Atoi(strings.TrimSpace(hi))
+	if err1 != nil || err2 != nil {
+		return Range{}, fmt.Errorf("invalid range %q (want N or MIN-MAX)", s)
+	}
+	r := Range{Min: a, Max: b}
+	return r, r.validate()
+}
+
+func (r Range) String() string {
+	if r.Min == r.Max {
+		return strconv.Itoa(r.Min)
+	}
+	return fmt.Sprintf("%d-%d", r.Min, r.Max)
+}
+
+func (r Range) validate() error {
+	if r.Min < 0 || r.Max < r.Min {
+		return fmt.Errorf("invalid range %d-%d (want 0 <= min <= max)", r.Min, r.Max)
+	}
+	return nil
+}
+
+// pick は範囲内の数を一様に選ぶ
+func (g *Generator) pick(r Range) int {
+	return r.Min + g.rng.Intn(r.Max-r.Min+1)
+}
+
+// Options はマップ生成の設定
+type Options struct {
+	Width    int
+	Height   int
+	Symmetry Symmetry
+	// Style は小マップのブロック配置のアルゴリズム（空で StyleScatter）
+	Style Style
+	// Turns はマップのターン数（0 で DefaultTurns）
+	Turns int
+	// Blocks, Items は小マップ1枚あたりに置くブロック・アイテムの数
+	// 対称性によって小マップが複製されるため、盤面全体ではおおむね4倍になる
+	// StyleScatter 以外ではブロックの数はスタイルで決まり、Blocks は使わない
+	Blocks Range
+	Items  Range
+	// MinStartDistance は Hot と Cool の間に必要な最短経路の長さ（0 で制限なし）
+	MinStartDistance int
+}
+
+// DefaultOptions は chaser-mapgen の既定値と同じ設定を返す
+func DefaultOptions() Options {
+	return Options{
+		Width:    DefaultWidth,
+		Height:   DefaultHeight,
+		Symmetry: SymmetryRotate4,
+		Style:    StyleScatter,
+		Turns:    DefaultTurns,
+		Blocks:   UpTo(9),
+		Items:    UpTo(10),
+	}
+}
+
+// Validate は設定で生成できるか確認し、見つかった問題をまとめて返す
+//
+// ブロックは小マップの最外周を除いた範囲に、アイテムは残りの空きマスに置くため、
+// 指定した数がそこに収まらない場合はエラーになる。
+func (o Options) Validate() error {
+	if err := ValidateSize(o.Width, o.Height); err != nil {
+		return err
+	}
+	if _, err := ParseSymmetry(string(o.Symmetry)); err != nil {
+		return err
+	}
+	if _, err := ParseStyle(string(o.Style)); err != nil {
+		return err
+	}
+
+	var errs []error
+	if o.Turns < 0 {
+		errs = append(errs, fmt.Errorf("invalid turns %d (must be non-negative; 0 for the default)", o.Turns))
+	}
+	if o.MinStartDistance < 0 {
+		errs = append(errs, fmt.Errorf("invalid minimum start distance %d", o.MinStartDistance))
+	}
+	if err := o.Blocks.validate(); err != nil {
+		errs = append(errs, fmt.Errorf("blocks: %w", err))
+	}
+	if err := o.Items.validate(); err != nil {
+		errs = append(errs, fmt.Errorf("items: %w", err))
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	if o.Style != StyleScatter && o.Style != "" {
+		// ブロックの数はスタイルで決まるため、アイテムが置けるかは生成時に確認する
+		return nil
+	}
+	qw, qh := quadrantDims(o.Width, o.Height, o.Symmetry)
+	if inner := (qw - 2) * (qh - 2); o.Blocks.Max > inner {
+		errs = append(errs, fmt.Errorf("blocks %v do not fit in the %d inner cells of a %dx%d quadrant", o.Blocks, inner, qw, qh))
+	}
+	if o.Blocks.Max+o.Items.Max > qw*qh {
+		errs = append(errs, fmt.Errorf("blocks %v and items %v do not fit in the %d cells of a %dx%d quadrant", o.Blocks, o.Items, qw*qh, qw, qh))
+	}
+	return errors.Join(errs...)
+}
+
+// ClampCounts は Blocks と Items の上限（Max）を小マップに置ける数まで下げる
+// 上限だけを指定する従来の maxBlocks/maxItems のように、置ける分だけ置けばよい場合に使う
+// サイズや対称性が不正な場合は何もしない（Validate でエラーになる）
+func (o *Options) ClampCounts() {
+	if ValidateSize(o.Width, o.Height) != nil {
+		return
+	}
+	if _, err := ParseSymmetry(string(o.Symmetry)); err != nil {
+		return
+	}
+	qw, qh := quadrantDims(o.Width, o.Height, o.Symmetry)
+	o.Blocks.Max = min(o.Blocks.Max, (qw-2)*(qh-2))
+	o.Items.Max = min(o.Items.Max, qw*qh-o.Blocks.Max)
+}
+
+// Generate は opts に従ってランダムマップを生成する
+func (g *Generator) Generate(opts Options) (*Map, error) {
+	if opts.Symmetry == "" {
+		opts.Symmetry = SymmetryRotate4
+	}
+	if opts.Style == "" {
+		opts.Style = StyleScatter
+	}
+	if opts.Turns == 0 {
+		opts.Turns = DefaultTurns
+	}
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	return g.generate(opts)
+}
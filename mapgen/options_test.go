@@ -0,0 +1,96 @@
+package mapgen
+
+import "testing"
+
+func TestParseRange(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Range
+		wantErr bool
+	}{
+		{"5", Exact(5), false},
+		{"3-8", Range{Min: 3, Max: 8}, false},
+		{" 2 - 4 ", Range{Min: 2, Max: 4}, false},
+		{"8-3", Range{}, true},
+		{"-1", Range{}, true},
+		{"many", Range{}, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseRange(tt.in)
+		if (err != nil) != tt.wantErr || (!tt.wantErr && got != tt.want) {
+			t.Errorf("ParseRange(%q) = %v, %v; want %v (error %v)", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestGenerateExactCounts(t *testing.T) {
+	gen := NewGeneratorWithSeed(7)
+	for _, sym := range []Symmetry{SymmetryRotate4, SymmetryNone} {
+		opts := DefaultOptions()
+		opts.Symmetry = sym
+		opts.Turns = 300
+		opts.Blocks, opts.Items = Exact(30), Exact(26) // 7x8 の小マップを埋め尽くす
+		m, err := gen.Generate(opts)
+		if err != nil {
+			t.Fatalf("%s: Generate: %v", sym, err)
+		}
+		if m.Turns != 300 {
+			t.Errorf("%s: Turns = %d, want 300", sym, m.Turns)
+		}
+		if s := m.Stats(); s.Blocks != 4*30 {
+			t.Errorf("%s: %d blocks, want %d", sym, s.Blocks, 4*30)
+		}
+	}
+}
+
+func TestOptionsValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*Options)
+	}{
+		{"too many blocks", func(o *Options) { o.Blocks = Exact(31) }},
+		{"too many items", func(o *Options) { o.Blocks, o.Items = Exact(30), Range{Min: 0, Max: 27} }},
+		{"negative turns", func(o *Options) { o.Turns = -1 }},
+		{"bad range", func(o *Options) { o.Items = Range{Min: 5, Max: 2} }},
+		{"bad size", func(o *Options) { o.Width = 3 }},
+	}
+	for _, tt := range tests {
+		opts := DefaultOptions()
+		tt.modify(&opts)
+		if _, err := NewGeneratorWithSeed(1).Generate(opts); err == nil {
+			t.Errorf("%s: Generate succeeded, want error", tt.name)
+		}
+	}
+}
+
+func TestClampCounts(t *testing.T) {
+	opts := DefaultOptions()
+	opts.Width, opts.Height = 6, 6 // 3x3 の小マップ（内側1マス）
+	opts.ClampCounts()
+	if opts.Blocks != UpTo(1) || opts.Items != UpTo(8) {
+		t.Errorf("ClampCounts = blocks %v items %v, want 0-1 and 0-8", opts.Blocks, opts.Items)
+	}
+	if _, err := NewGeneratorWithSeed(1).Generate(opts); err != nil {
+		t.Errorf("Generate after ClampCounts: %v", err)
+	}
+}
+
+func TestMinStartDistance(t *testing.T) {
+	gen := NewGeneratorWithSeed(3)
+	opts := DefaultOptions()
+	opts.MinStartDistance = 20
+	for i := 0; i < 10; i++ {
+		m, err := gen.Generate(opts)
+		if err != nil {
+			t.Fatalf("Generate: %v", err)
+		}
+		if d := m.Stats().StartDistance; d < 20 {
+			t.Errorf("start distance = %d, want >= 20", d)
+		}
+	}
+
+	opts.MinStartDistance = 1000
+	if _, err := gen.Generate(opts); err == nil {
+		t.Error("Generate with an impossible start distance succeeded")
+	}
+}
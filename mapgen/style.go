@@ -0,0 +1,234 @@
+package mapgen
+
+import "fmt"
+
+// Style は小マップのブロック配置のアルゴリズム
+//
+// どのスタイルでも小マップの最外周にはブロックを置かないため、四隅に並べた
+// 小マップ同士は最外周を通ってつながる。
+type Style string
+
+const (
+	// StyleScatter はブロックをランダムに散らばらせる（既定）
+	StyleScatter Style = "scatter"
+	// StyleMaze は穴掘り法（再帰的バックトラック）で迷路を作る
+	StyleMaze Style = "maze"
+	// StyleRooms は矩形の部屋を通路でつなぐ
+	StyleRooms Style = "rooms"
+	// StyleCaves はセル・オートマトンで洞窟を作る
+	StyleCaves Style = "caves"
+)
+
+// Styles は指定可能なスタイルの一覧
+var Styles = []Style{StyleScatter, StyleMaze, StyleRooms, StyleCaves}
+
+// ParseStyle はフラグ等の文字列から Style を得る（空文字は StyleScatter）
+func ParseStyle(s string) (Style, error) {
+	if s == "" {
+		return StyleScatter, nil
+	}
+	for _, style := range Styles {
+		if string(style) == s {
+			return style, nil
+		}
+	}
+	return "", fmt.Errorf("unknown style %q (want one of %v)", s, Styles)
+}
+
+// smallMap は opts.Style に従って width×height の小マップを生成する
+//
+// StyleScatter 以外ではブロックの数はアルゴリズムで決まり、opts.Blocks は使わない。
+// アイテムは残った空白マスに opts.Items の数だけ置き、置ききれなければエラーを返す。
+func (g *Generator) smallMap(width, height int, opts Options) (*Map, error) {
+	if opts.Style == StyleScatter {
+		return g.generateSmallMap(width, height, opts.Blocks, opts.Items), nil
+	}
+
+	m := &Map{
+		Width:  width,
+		Height: height,
+		Data:   make([][]CellType, height),
+	}
+	for y := 0; y < height; y++ {
+		m.Data[y] = make([]CellType, width)
+	}
+
+	switch opts.Style {
+	case StyleMaze:
+		g.carveMaze(m)
+	case StyleRooms:
+		g.carveRooms(m)
+	case StyleCaves:
+		g.growCaves(m)
+	}
+
+	free := 0
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			if m.Data[y][x] == Empty {
+				free++
+			}
+		}
+	}
+	itemCount := g.pick(opts.Items)
+	if itemCount > free {
+		return nil, fmt.Errorf("%s: %d items do not fit in the %d empty cells of a %dx%d quadrant", opts.Style, itemCount, free, width, height)
+	}
+	for i := 0; i < itemCount; i++ {
+		g.placeCell(m, Item, 0, 0, width, height)
+	}
+	return m, nil
+}
+
+// fillInner は小マップの最外周を除く範囲をブロックで埋める
+func fillInner(m *Map) {
+	for y := 1; y < m.Height-1; y++ {
+		for x := 1; x < m.Width-1; x++ {
+			m.Data[y][x] = Block
+		}
+	}
+}
+
+// carveMaze は最外周の内側を迷路にする
+//
+// 内側の偶数座標（内側の左上を 0 とする）のマスを部屋、その間を壁とみなし、
+// ランダムな深さ優先探索で壁を壊して全ての部屋を1本の木でつなぐ。
+// 内側の左端・上端の部屋は最外周に接するため、迷路全体が最外周とつながる。
+func (g *Generator) carveMaze(m *Map) {
+	fillInner(m)
+	cols, rows := (m.Width-1)/2, (m.Height-1)/2 // 内側 (w-2)×(h-2) に収まる部屋の数
+	if cols == 0 || rows == 0 {
+		return
+	}
+	at := func(cx, cy int) Position { return Position{X: 1 + 2*cx, Y: 1 + 2*cy} }
+
+	visited := make([][]bool, rows)
+	for i := range visited {
+		visited[i] = make([]bool, cols)
+	}
+	start := Position{X: g.rng.Intn(cols), Y: g.rng.Intn(rows)}
+	visited[start.Y][start.X] = true
+	p := at(start.X, start.Y)
+	m.Data[p.Y][p.X] = Empty
+
+	// 再帰の代わりに明示的なスタックで辿る
+	stack := []Position{start}
+	for len(stack) > 0 {
+		c := stack[len(stack)-1]
+		var next []Position
+		for _, d := range neighborOffsets {
+			n := Position{X: c.X + d.X, Y: c.Y + d.Y}
+			if n.X >= 0 && n.X < cols && n.Y >= 0 && n.Y < rows && !visited[n.Y][n.X] {
+				next = append(next, n)
+			}
+		}
+		if len(next) == 0 {
+			stack = stack[:len(stack)-1]
+			continue
+		}
+		n := next[g.rng.Intn(len(next))]
+		visited[n.Y][n.X] = true
+		from, to := at(c.X, c.Y), at(n.X, n.Y)
+		m.Data[(from.Y+to.Y)/2][(from.X+to.X)/2] = Empty
+		m.Data[to.Y][to.X] = Empty
+		stack = append(stack, n)
+	}
+}
+
+// carveRooms は最外周の内側に矩形の部屋をいくつか掘り、L字の通路でつなぐ
+// 最初の部屋は最外周まで通路を伸ばし、以降の部屋は直前の部屋につなぐ
+func (g *Generator) carveRooms(m *Map) {
+	fillInner(m)
+	iw, ih := m.Width-2, m.Height-2
+	if iw <= 0 || ih <= 0 {
+		return
+	}
+	carve := func(x, y int) { m.Data[y][x] = Empty }
+
+	var prev Position
+	rooms := 0
+	for attempts := 0; attempts < 20 && rooms < 1+iw*ih/12; attempts++ {
+		w := 1 + g.rng.Intn(min(3, iw))
+		h := 1 + g.rng.Intn(min(3, ih))
+		x0 := 1 + g.rng.Intn(iw-w+1)
+		y0 := 1 + g.rng.Intn(ih-h+1)
+		for y := y0; y < y0+h; y++ {
+			for x := x0; x < x0+w; x++ {
+				carve(x, y)
+			}
+		}
+		center := Position{X: x0 + w/2, Y: y0 + h/2}
+		if rooms == 0 {
+			// 左の最外周まで通路を伸ばす
+			for x := 1; x < center.X; x++ {
+				carve(x, center.Y)
+			}
+		} else {
+			// 横→縦の L 字通路で直前の部屋とつなぐ
+			for x := min(prev.X, center.X); x <= max(prev.X, center.X); x++ {
+				carve(x, prev.Y)
+			}
+			for y := min(prev.Y, center.Y); y <= max(prev.Y, center.Y); y++ {
+				carve(center.X, y)
+			}
+		}
+		prev = center
+		rooms++
+	}
+}
+
+// growCaves はセル・オートマトンで最外周の内側を洞窟にする
+//
+// 内側の約40%をブロックで埋め、周囲8マス（最外周は壁とみなす）のうち5マス以上が
+// ブロックならブロック、3マス以下なら空白にする規則を4回適用する。最後に最外周から到達できない
+// 空白をブロックで埋め、閉じた空洞が残らないようにする。
+func (g *Generator) growCaves(m *Map) {
+	for y := 1; y < m.Height-1; y++ {
+		for x := 1; x < m.Width-1; x++ {
+			if g.rng.Intn(100) < 40 {
+				m.Data[y][x] = Block
+			}
+		}
+	}
+
+	for step := 0; step < 4; step++ {
+		next := make([][]CellType, m.Height)
+		for y := range m.Data {
+			next[y] = append([]CellType(nil), m.Data[y]...)
+		}
+		for y := 1; y < m.Height-1; y++ {
+			for x := 1; x < m.Width-1; x++ {
+				blocks := 0
+				for dy := -1; dy <= 1; dy++ {
+					for dx := -1; dx <= 1; dx++ {
+						nx, ny := x+dx, y+dy
+						if dx == 0 && dy == 0 {
+							continue
+						}
+						// 最外周は壁とみなして数える（内側の縁にも洞窟の壁が残るように）
+						if nx == 0 || ny == 0 || nx == m.Width-1 || ny == m.Height-1 || m.Data[ny][nx] == Block {
+							blocks++
+						}
+					}
+				}
+				switch {
+				case blocks >= 5:
+					next[y][x] = Block
+				case blocks <= 3:
+					next[y][x] = Empty
+				}
+			}
+		}
+		m.Data = next
+	}
+
+	// 最外周（常に空白）から到達できない空白を埋める
+	reach := m.distances(Position{X: 0, Y: 0})
+	for y := 1; y < m.Height-1; y++ {
+		for x := 1; x < m.Width-1; x++ {
+			if m.Data[y][x] == Empty && reach[y][x] < 0 {
+				m.Data[y][x] = Block
+			}
+		}
+	}
+}
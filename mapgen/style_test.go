@@ -0,0 +1,97 @@
+package mapgen
+
+import (
+	"math"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kqnade/CHaserGo/chasermap"
+)
+
+func TestParseStyle(t *testing.T) {
+	if style, err := ParseStyle(""); err != nil || style != StyleScatter {
+		t.Errorf("ParseStyle(\"\") = %q, %v; want scatter", style, err)
+	}
+	for _, want := range Styles {
+		if style, err := ParseStyle(string(want)); err != nil || style != want {
+			t.Errorf("ParseStyle(%q) = %q, %v", want, style, err)
+		}
+	}
+	if _, err := ParseStyle("islands"); err == nil {
+		t.Error("ParseStyle(\"islands\") expected error")
+	}
+}
+
+func TestGenerateStyles(t *testing.T) {
+	for _, style := range Styles[1:] {
+		for _, sym := range Symmetries {
+			for _, size := range [][2]int{{15, 17}, {21, 21}, {6, 7}} {
+				for seed := int64(1); seed <= 3; seed++ {
+					opts := DefaultOptions()
+					opts.Width, opts.Height = size[0], size[1]
+					opts.Symmetry, opts.Style = sym, style
+					opts.Items = Exact(2)
+					m, err := NewGeneratorWithSeed(seed).Generate(opts)
+					if err != nil {
+						t.Fatalf("%s %s %dx%d: %v", style, sym, size[0], size[1], err)
+					}
+					if m.Style != style {
+						t.Errorf("Style = %q, want %q", m.Style, style)
+					}
+
+					s := m.Stats()
+					if s.Unreachable > 0 || s.HotEnclosed || s.CoolEnclosed {
+						t.Errorf("%s %s %dx%d seed %d: %v\n%v", style, sym, size[0], size[1], seed, s, m)
+					}
+					if sym != SymmetryNone && math.Abs(s.Advantage) > 1e-9 {
+						t.Errorf("%s %s seed %d: advantage %v on a symmetric map", style, sym, seed, s.Advantage)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestMazeIsCorridors(t *testing.T) {
+	opts := DefaultOptions()
+	opts.Style = StyleMaze
+	opts.Items = Exact(0)
+	m, err := NewGeneratorWithSeed(1).Generate(opts)
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	// 7×8 の小マップの内側 5×6 のうち、3×3 の部屋と8本の通路以外はブロック
+	if blocks := m.Stats().Blocks; blocks != 4*(30-9-8) {
+		t.Errorf("%d blocks, want %d\n%v", blocks, 4*(30-9-8), m)
+	}
+}
+
+func TestStyleItemsDoNotFit(t *testing.T) {
+	opts := DefaultOptions()
+	opts.Style = StyleMaze
+	opts.Items = Exact(56)
+	if _, err := NewGeneratorWithSeed(1).Generate(opts); err == nil {
+		t.Error("Generate succeeded with more items than empty cells")
+	}
+}
+
+func TestSaveToFileStyle(t *testing.T) {
+	opts := DefaultOptions()
+	opts.Style = StyleRooms
+	m, err := NewGeneratorWithSeed(1).Generate(opts)
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	filename := filepath.Join(t.TempDir(), "rooms.map")
+	if err := m.SaveToFile(filename); err != nil {
+		t.Fatalf("SaveToFile: %v", err)
+	}
+	cm, err := chasermap.Load(filename)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if !strings.HasSuffix(cm.Name, "symmetry=rotate4 style=rooms") {
+		t.Errorf("name = %q, want symmetry and style recorded", cm.Name)
+	}
+}
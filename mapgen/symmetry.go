@@ -0,0 +1,113 @@
+package mapgen
+
+import "fmt"
+
+// Symmetry はマップの対称性の種類
+type Symmetry string
+
+const (
+	// SymmetryRotate4 は小マップを90°ずつ回転させて四隅に配置する（既定）
+	// 正方形では90°回転対称になる。それ以外では SymmetryPoint と同じ配置で点対称になる
+	SymmetryRotate4 Symmetry = "rotate4"
+	// SymmetryPoint は上半分の小マップ2枚を180°回転させて下半分に配置する（点対称）
+	SymmetryPoint Symmetry = "point"
+	// SymmetryMirrorLR は左半分を左右反転して右半分に配置する（左右対称）
+	SymmetryMirrorLR Symmetry = "mirror-lr"
+	// SymmetryMirrorTB は上半分を上下反転して下半分に配置する（上下対称）
+	SymmetryMirrorTB Symmetry = "mirror-tb"
+	// SymmetryNone は四隅の小マップをそれぞれ独立に生成する（対称性なし）
+	SymmetryNone Symmetry = "none"
+)
+
+// Symmetries は指定可能な対称性の一覧
+var Symmetries = []Symmetry{SymmetryRotate4, SymmetryPoint, SymmetryMirrorLR, SymmetryMirrorTB, SymmetryNone}
+
+// ParseSymmetry はフラグ等の文字列から Symmetry を得る（空文字は SymmetryRotate4）
+func ParseSymmetry(s string) (Symmetry, error) {
+	if s == "" {
+		return SymmetryRotate4, nil
+	}
+	for _, sym := range Symmetries {
+		if string(sym) == s {
+			return sym, nil
+		}
+	}
+	return "", fmt.Errorf("unknown symmetry %q (want one of %v)", s, Symmetries)
+}
+
+// quadrants は opts.Symmetry に応じて四隅に置く小マップ（左上・右上・右下・左下）を生成する
+func (g *Generator) quadrants(opts Options) (tl, tr, br, bl *Map, err error) {
+	// 90°回転するモード（正方形のみ）では、回転した小マップと噛み合うよう辺を2分割する
+	// それ以外では4枚とも同じサイズ。奇数辺の中央列・行は隙間として空白になる
+	sym := layoutSymmetry(opts.Width, opts.Height, opts.Symmetry)
+	qw, qh := quadrantDims(opts.Width, opts.Height, opts.Symmetry)
+
+	// 対称性で決まらない小マップだけを独立に生成する
+	n := 2
+	switch sym {
+	case SymmetryRotate4:
+		n = 1
+	case SymmetryNone:
+		n = 4
+	}
+	ms := make([]*Map, n)
+	for i := range ms {
+		if ms[i], err = g.smallMap(qw, qh, opts); err != nil {
+			return nil, nil, nil, nil, err
+		}
+	}
+
+	switch sym {
+	case SymmetryRotate4:
+		m1 := g.rotateMap(ms[0])
+		m2 := g.rotateMap(m1)
+		m3 := g.rotateMap(m2)
+		// rotateMap は反時計回りなので、右上には270°（時計回りに90°）回転したものを置く
+		return ms[0], m3, m2, m1, nil
+	case SymmetryPoint:
+		tl, tr = ms[0], ms[1]
+		return tl, tr, g.rotateMap(g.rotateMap(tl)), g.rotateMap(g.rotateMap(tr)), nil
+	case SymmetryMirrorLR:
+		tl, bl = ms[0], ms[1]
+		return tl, flipMap(tl, true), flipMap(bl, true), bl, nil
+	case SymmetryMirrorTB:
+		tl, tr = ms[0], ms[1]
+		return tl, tr, flipMap(tr, false), flipMap(tl, false), nil
+	default:
+		return ms[0], ms[1], ms[2], ms[3], nil
+	}
+}
+
+// flipMap は horizontal が true なら左右、false なら上下に反転したマップを返す
+func flipMap(m *Map, horizontal bool) *Map {
+	flipped := &Map{
+		Width:  m.Width,
+		Height: m.Height,
+		Data:   make([][]CellType, m.Height),
+	}
+	for y := 0; y < m.Height; y++ {
+		flipped.Data[y] = make([]CellType, m.Width)
+		for x := 0; x < m.Width; x++ {
+			if horizontal {
+				flipped.Data[y][x] = m.Data[y][m.Width-1-x]
+			} else {
+				flipped.Data[y][x] = m.Data[m.Height-1-y][x]
+			}
+		}
+	}
+	return flipped
+}
+
+// counterpart は sym のもとで pos に対応する位置を返す（SymmetryNone では ok=false）
+func counterpart(sym Symmetry, width, height int, pos Position) (p Position, ok bool) {
+	switch sym {
+	case SymmetryRotate4, SymmetryPoint:
+		return Position{X: width - 1 - pos.X, Y: height - 1 - pos.Y}, true
+	case SymmetryMirrorLR:
+		return Position{X: width - 1 - pos.X, Y: pos.Y}, true
+	case SymmetryMirrorTB:
+		return Position{X: pos.X, Y: height - 1 - pos.Y}, true
+	default:
+		return Position{}, false
+	}
+}
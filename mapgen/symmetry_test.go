@@ -0,0 +1,123 @@
+package mapgen
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseSymmetry(t *testing.T) {
+	if sym, err := ParseSymmetry(""); err != nil || sym != SymmetryRotate4 {
+		t.Errorf("ParseSymmetry(\"\") = %q, %v; want rotate4", sym, err)
+	}
+	for _, want := range Symmetries {
+		if sym, err := ParseSymmetry(string(want)); err != nil || sym != want {
+			t.Errorf("ParseSymmetry(%q) = %q, %v", want, sym, err)
+		}
+	}
+	if _, err := ParseSymmetry("diagonal"); err == nil {
+		t.Error("ParseSymmetry(\"diagonal\") expected error")
+	}
+}
+
+func TestGenerateMapSymmetric(t *testing.T) {
+	// 各セルについて対応するセルを返す（SymmetryNone は検査しない）
+	mirrors := map[Symmetry]func(w, h, x, y int) (int, int){
+		SymmetryRotate4:  func(w, h, x, y int) (int, int) { return w - 1 - x, h - 1 - y },
+		SymmetryPoint:    func(w, h, x, y int) (int, int) { return w - 1 - x, h - 1 - y },
+		SymmetryMirrorLR: func(w, h, x, y int) (int, int) { return w - 1 - x, y },
+		SymmetryMirrorTB: func(w, h, x, y int) (int, int) { return x, h - 1 - y },
+	}
+
+	for _, sym := range Symmetries {
+		for _, size := range [][2]int{{15, 17}, {21, 17}, {9, 9}, {6, 7}} {
+			for seed := int64(1); seed <= 5; seed++ {
+				gen := NewGeneratorWithSeed(seed)
+				m, err := gen.GenerateMapSymmetric(size[0], size[1], 9, 10, sym)
+				if err != nil {
+					t.Fatalf("%s %dx%d: %v", sym, size[0], size[1], err)
+				}
+				if m.Width != size[0] || m.Height != size[1] || m.Symmetry != sym {
+					t.Fatalf("%s: got %dx%d symmetry %q", sym, m.Width, m.Height, m.Symmetry)
+				}
+
+				if m.Hot == m.Cool || m.Data[m.Hot.Y][m.Hot.X] != Empty || m.Data[m.Cool.Y][m.Cool.X] != Empty {
+					t.Errorf("%s %dx%d seed %d: bad agents Hot %v, Cool %v", sym, m.Width, m.Height, seed, m.Hot, m.Cool)
+				}
+
+				mirror, ok := mirrors[sym]
+				if !ok {
+					continue
+				}
+				for y := 0; y < m.Height; y++ {
+					for x := 0; x < m.Width; x++ {
+						mx, my := mirror(m.Width, m.Height, x, y)
+						if m.Data[y][x] != m.Data[my][mx] {
+							t.Errorf("%s %dx%d seed %d: (%d,%d) and (%d,%d) differ", sym, m.Width, m.Height, seed, x, y, mx, my)
+						}
+					}
+				}
+				if hx, hy := mirror(m.Width, m.Height, m.Cool.X, m.Cool.Y); m.Hot != (Position{X: hx, Y: hy}) {
+					t.Errorf("%s %dx%d seed %d: Hot %v does not mirror Cool %v", sym, m.Width, m.Height, seed, m.Hot, m.Cool)
+				}
+			}
+		}
+	}
+}
+
+func TestGenerateRotate4NonSquare(t *testing.T) {
+	// 30×8 の盤面は 15×4 の小マップ4枚で隙間なく覆われ、どの小マップにも同じ数だけ置かれる
+	opts := DefaultOptions()
+	opts.Width, opts.Height = 30, 8
+	opts.Blocks, opts.Items = Range{Min: 10, Max: 10}, Range{Min: 10, Max: 10}
+	for seed := int64(1); seed <= 5; seed++ {
+		m, err := NewGeneratorWithSeed(seed).Generate(opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var blocks, items [3]int // 左・中央・右の 10 列ずつ
+		for y := 0; y < m.Height; y++ {
+			for x := 0; x < m.Width; x++ {
+				switch m.Data[y][x] {
+				case Block:
+					blocks[x/10]++
+				case Item:
+					items[x/10]++
+				}
+			}
+		}
+		if b, i := blocks[0]+blocks[1]+blocks[2], items[0]+items[1]+items[2]; b != 40 || i != 40 {
+			t.Errorf("seed %d: %d blocks and %d items, want 40 each", seed, b, i)
+		}
+		if blocks[1] == 0 && items[1] == 0 {
+			t.Errorf("seed %d: middle columns are empty", seed)
+		}
+	}
+}
+
+func TestGenerateMapSymmetricInvalid(t *testing.T) {
+	gen := NewGenerator()
+	if _, err := gen.GenerateMapSymmetric(15, 17, 9, 10, "spiral"); err == nil {
+		t.Error("expected error for unknown symmetry")
+	}
+}
+
+func TestSaveToFileSymmetry(t *testing.T) {
+	m, err := NewGeneratorWithSeed(1).GenerateMapSymmetric(15, 17, 9, 10, SymmetryMirrorLR)
+	if err != nil {
+		t.Fatalf("GenerateMapSymmetric: %v", err)
+	}
+	filename := filepath.Join(t.TempDir(), "mirror.map")
+	if err := m.SaveToFile(filename); err != nil {
+		t.Fatalf("SaveToFile: %v", err)
+	}
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	first, _, _ := strings.Cut(string(content), "\n")
+	if first != "N generatedmirror.map symmetry=mirror-lr" {
+		t.Errorf("N line = %q", first)
+	}
+}
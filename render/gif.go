@@ -0,0 +1,181 @@
+package render
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/color/palette"
+	"image/draw"
+	"image/gif"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/kqnade/CHaserGo/server"
+)
+
+// 既定のフレームの表示時間
+const (
+	DefaultFrameDelay = 150 * time.Millisecond
+	DefaultEndDelay   = 3 * time.Second
+)
+
+// 情報欄の色（GUI の HUD と同じ背景）
+var (
+	hudBackground = color.RGBA{R: 20, G: 20, B: 20, A: 255}
+	hudText       = color.RGBA{R: 230, G: 230, B: 230, A: 255}
+	hudHot        = color.RGBA{R: 255, G: 110, B: 90, A: 255}
+	hudCool       = color.RGBA{R: 110, G: 170, B: 255, A: 255}
+)
+
+// GIFOptions はアニメーション GIF の設定
+type GIFOptions struct {
+	// Delay は1フレームの表示時間（0 以下で DefaultFrameDelay）
+	Delay time.Duration
+	// EndDelay は最後のフレーム（勝敗表示）の表示時間（0 以下で DefaultEndDelay）
+	EndDelay time.Duration
+}
+
+// textScale は盤面の大きさに合わせた情報欄の文字の倍率
+func (r *Renderer) textScale() int {
+	return max(1, r.tileSize/16)
+}
+
+// Frame は盤面の下に GUI の HUD と同じ情報（ターン数・各プレイヤーのアイテム数・勝敗）を加えた画像を描画する
+func (r *Renderer) Frame(s *server.BoardSnapshot) *image.RGBA {
+	board := r.Snapshot(s)
+	scale := r.textScale()
+	lineHeight := textHeight()*scale + 2*scale
+	pad := 4 * scale
+	b := board.Bounds()
+
+	lines := []hudLine{
+		{fmt.Sprintf("Turn: %d / %d", s.Turn, s.MaxTurns), hudText},
+		{playerLine("[HOT]  ", s.HotName, s.HotItems, s.HotAlive), hudHot},
+		{playerLine("[COOL] ", s.CoolName, s.CoolItems, s.CoolAlive), hudCool},
+	}
+	if s.Phase == server.PhaseGameOver {
+		result := fmt.Sprintf("DRAW  (%s)", s.Reason)
+		if s.WinnerName != "" {
+			result = fmt.Sprintf("WINNER: %s  (%s)", s.WinnerName, s.Reason)
+		}
+		lines = append(lines, hudLine{result, hudText})
+	}
+
+	// 情報欄の高さは勝敗の行を含めて固定し、フレームの大きさを揃える
+	img := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()+4*lineHeight+2*pad))
+	draw.Draw(img, img.Bounds(), image.NewUniform(hudBackground), image.Point{}, draw.Src)
+	draw.Draw(img, b, board, image.Point{}, draw.Src)
+	for i, l := range lines {
+		drawText(img, pad, b.Dy()+pad+i*lineHeight, fitText(l.text, b.Dx()-2*pad, scale), l.c, scale)
+	}
+	return img
+}
+
+// hudLine は情報欄の1行
+type hudLine struct {
+	text string
+	c    color.Color
+}
+
+func playerLine(label, name string, items int, alive bool) string {
+	s := fmt.Sprintf("%s%s: %d items", label, name, items)
+	if !alive {
+		s += " (DEAD)"
+	}
+	return s
+}
+
+// WriteGIF は snaps を順にフレームにしたアニメーション GIF を w に書き出す
+// 表示が直前と同じになるスナップショットは省く
+func (r *Renderer) WriteGIF(w io.Writer, snaps []server.BoardSnapshot, opts GIFOptions) error {
+	if len(snaps) == 0 {
+		return errors.New("no snapshots to render")
+	}
+	delay := centiseconds(opts.Delay, DefaultFrameDelay)
+	endDelay := centiseconds(opts.EndDelay, DefaultEndDelay)
+
+	anim := &gif.GIF{}
+	q := newQuantizer(palette.Plan9)
+	var prev *server.BoardSnapshot
+	for i := range snaps {
+		s := &snaps[i]
+		if prev != nil && sameView(prev, s) {
+			continue
+		}
+		prev = s
+		anim.Image = append(anim.Image, q.paletted(r.Frame(s)))
+		anim.Delay = append(anim.Delay, delay)
+	}
+	anim.Delay[len(anim.Delay)-1] = endDelay
+	return gif.EncodeAll(w, anim)
+}
+
+// SaveGIF は WriteGIF の結果をファイルに保存する（ディレクトリがなければ作成する）
+func (r *Renderer) SaveGIF(filename string, snaps []server.BoardSnapshot, opts GIFOptions) error {
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	file, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+	if err := r.WriteGIF(file, snaps, opts); err != nil {
+		file.Close() // エラー時も明示的にクローズ
+		return fmt.Errorf("failed to encode gif: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to close file: %w", err)
+	}
+	return nil
+}
+
+// quantizer はフレームをパレット画像にする
+// タイルの色は少ないため、ディザリングせず最も近い色に置き換え、色ごとの結果を使い回す
+type quantizer struct {
+	palette color.Palette
+	cache   map[[4]uint8]uint8
+}
+
+func newQuantizer(p color.Palette) *quantizer {
+	return &quantizer{palette: p, cache: make(map[[4]uint8]uint8)}
+}
+
+func (q *quantizer) paletted(img *image.RGBA) *image.Paletted {
+	b := img.Bounds()
+	p := image.NewPaletted(b, q.palette)
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			i := img.PixOffset(x, y)
+			c := [4]uint8(img.Pix[i : i+4])
+			idx, ok := q.cache[c]
+			if !ok {
+				idx = uint8(q.palette.Index(color.RGBA{R: c[0], G: c[1], B: c[2], A: c[3]}))
+				q.cache[c] = idx
+			}
+			p.SetColorIndex(x, y, idx)
+		}
+	}
+	return p
+}
+
+// centiseconds は d を GIF の表示時間の単位（1/100 秒）にする（0 以下なら def）
+func centiseconds(d, def time.Duration) int {
+	if d <= 0 {
+		d = def
+	}
+	return max(1, int(d/(10*time.Millisecond)))
+}
+
+// sameView は Frame で描く内容が a と b で同じかを返す
+func sameView(a, b *server.BoardSnapshot) bool {
+	return a.Turn == b.Turn && a.MaxTurns == b.MaxTurns && a.Phase == b.Phase &&
+		a.WinnerName == b.WinnerName && a.Reason == b.Reason &&
+		a.HotName == b.HotName && a.HotX == b.HotX && a.HotY == b.HotY && a.HotItems == b.HotItems && a.HotAlive == b.HotAlive &&
+		a.CoolName == b.CoolName && a.CoolX == b.CoolX && a.CoolY == b.CoolY && a.CoolItems == b.CoolItems && a.CoolAlive == b.CoolAlive &&
+		slices.Equal(a.MapFlat, b.MapFlat)
+}
@@ -0,0 +1,123 @@
+package render
+
+import (
+	"bytes"
+	"image"
+	"image/gif"
+	"testing"
+	"time"
+
+	"github.com/kqnade/CHaserGo/server"
+)
+
+// testSnapshots は初期盤面・Hot が1歩右に動いた盤面・終了時の3つのスナップショットを返す
+func testSnapshots(t *testing.T) []server.BoardSnapshot {
+	t.Helper()
+	b := server.NewBoardFromMap(testMap(t))
+	b.Hot.Name, b.Cool.Name = "hot-bot", "テストⅢ"
+	first := server.SnapshotFromBoard(b, server.KindConnected, server.TurnStepFirst, server.PhaseRunning, 1, "", "")
+	if err := b.Walk(b.Hot, server.Right); err != nil {
+		t.Fatal(err)
+	}
+	moved := server.SnapshotFromBoard(b, server.KindActionEnd, server.TurnStepFirst, server.PhaseRunning, 2, "", "")
+	b.Cool.IsAlive = false
+	end := server.SnapshotFromBoard(b, server.KindGameOver, server.TurnStepSecond, server.PhaseGameOver, 3, "hot-bot", "cool died")
+	return []server.BoardSnapshot{first, moved, end}
+}
+
+func TestWriteGIF(t *testing.T) {
+	r := New(builtin(t, "Light"), 16)
+	snaps := testSnapshots(t)
+	// 表示が同じスナップショット（Revision だけ違う）は1フレームにまとめる
+	dup := snaps[1]
+	dup.Revision = 10
+	snaps = append(snaps[:2], dup, snaps[2])
+
+	var buf bytes.Buffer
+	if err := r.WriteGIF(&buf, snaps, GIFOptions{Delay: 200 * time.Millisecond}); err != nil {
+		t.Fatal(err)
+	}
+	g, err := gif.DecodeAll(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(g.Image) != 3 {
+		t.Fatalf("got %d frames, want 3", len(g.Image))
+	}
+	if g.Delay[0] != 20 || g.Delay[1] != 20 || g.Delay[2] != int(DefaultEndDelay/(10*time.Millisecond)) {
+		t.Errorf("delays = %v", g.Delay)
+	}
+	for i, img := range g.Image {
+		if img.Bounds() != g.Image[0].Bounds() {
+			t.Errorf("frame %d bounds %v differ from %v", i, img.Bounds(), g.Image[0].Bounds())
+		}
+	}
+	if string(g.Image[0].Pix) == string(g.Image[1].Pix) {
+		t.Error("frames before and after the move are identical")
+	}
+
+	if err := r.WriteGIF(&buf, nil, GIFOptions{}); err == nil {
+		t.Error("WriteGIF with no snapshots expected error")
+	}
+}
+
+func TestFrame(t *testing.T) {
+	r := New(builtin(t, "Heavy"), 32)
+	snaps := testSnapshots(t)
+	frame := r.Frame(&snaps[2])
+	board := r.Snapshot(&snaps[2])
+
+	// 盤面の下に情報欄を加える
+	if frame.Bounds().Dx() != board.Bounds().Dx() || frame.Bounds().Dy() <= board.Bounds().Dy() {
+		t.Fatalf("frame %v, board %v", frame.Bounds(), board.Bounds())
+	}
+	top := frame.SubImage(board.Bounds()).(*image.RGBA)
+	for y := 0; y < board.Bounds().Dy(); y++ {
+		if !bytes.Equal(top.Pix[y*top.Stride:y*top.Stride+board.Bounds().Dx()*4], board.Pix[y*board.Stride:(y+1)*board.Stride]) {
+			t.Fatalf("frame row %d differs from the board image", y)
+		}
+	}
+	// 情報欄に文字が描かれている
+	hud := frame.SubImage(image.Rect(0, board.Bounds().Dy(), frame.Bounds().Dx(), frame.Bounds().Dy())).(*image.RGBA)
+	lit := 0
+	for i := 0; i < len(hud.Pix); i += 4 {
+		if hud.Pix[i] > 200 {
+			lit++
+		}
+	}
+	if lit == 0 {
+		t.Error("no text drawn in the HUD area")
+	}
+}
+
+func TestFitText(t *testing.T) {
+	if got := fitText("short", 100, 1); got != "short" {
+		t.Errorf("fitText = %q", got)
+	}
+	// 幅 36px（1倍で6文字）に収める
+	if got := fitText("a very long name", 36, 1); got != "a ve.." {
+		t.Errorf("fitText = %q, want \"a ve..\"", got)
+	}
+	if got := fitText("テストⅢ", 12, 2); got != "" {
+		t.Errorf("fitText = %q, want empty", got)
+	}
+}
+
+func TestDrawTextNonASCII(t *testing.T) {
+	drawn := func(s string) *image.RGBA {
+		img := image.NewRGBA(image.Rect(0, 0, 64, 20))
+		drawText(img, 0, 0, s, hudText, 1)
+		return img
+	}
+	name, unknown := drawn("テストⅢ"), drawn("????")
+	if string(name.Pix) == string(image.NewRGBA(name.Rect).Pix) {
+		t.Fatal("no text drawn")
+	}
+	if string(name.Pix) == string(unknown.Pix) {
+		t.Error("Japanese name drawn as ????")
+	}
+	// 全角の文字は半角の2文字分の幅で描く
+	if w := textWidth("テストⅢ", 1); w != 2*textWidth("????", 1) {
+		t.Errorf("width of テストⅢ = %d, want %d", w, 2*textWidth("????", 1))
+	}
+}
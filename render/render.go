@@ -0,0 +1,177 @@
+// Package render は盤面を画像に描画する
+//
+// GUI と同じテーマのタイル（theme パッケージの組み込み・独自テーマ）を使うが、Ebitengine には依存せず
+// 標準ライブラリの image パッケージだけで描画するため、ウィンドウのない環境でも
+// マップファイルや BoardSnapshot のプレビュー画像（PNG）を作成できる。
+package render
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"os"
+	"path/filepath"
+
+	"github.com/kqnade/CHaserGo/chasermap"
+	"github.com/kqnade/CHaserGo/server"
+	"github.com/kqnade/CHaserGo/theme"
+)
+
+// DefaultTileSize は1マスの既定の大きさ（ピクセル）
+const DefaultTileSize = 32
+
+// deadAlpha は倒れたキャラクターの不透明度（GUI と同じ）
+const deadAlpha = 0.4
+
+// background はタイルの透明な部分の下に見える色（GUI の背景と同じ）
+var background = color.RGBA{R: 30, G: 30, B: 30, A: 255}
+
+// Renderer は1つのテーマとマスの大きさで盤面を描画する
+// タイルは New で縮小済みのものを保持するため、同じ Renderer で多数の盤面を描画できる
+type Renderer struct {
+	theme    string
+	tileSize int
+
+	floor, block, item, hot, cool *image.RGBA
+}
+
+// New は t のタイルを tileSize ピクセルに縮小した Renderer を作成する
+// tileSize が 0 以下の場合は DefaultTileSize を使う
+func New(t *theme.Theme, tileSize int) *Renderer {
+	if tileSize <= 0 {
+		tileSize = DefaultTileSize
+	}
+	return &Renderer{
+		theme:    t.Name,
+		tileSize: tileSize,
+		floor:    scale(t.Floor, tileSize),
+		block:    scale(t.Block, tileSize),
+		item:     scale(t.Item, tileSize),
+		hot:      scale(t.Hot, tileSize),
+		cool:     scale(t.Cool, tileSize),
+	}
+}
+
+// Theme は描画に使うテーマ名を返す
+func (r *Renderer) Theme() string {
+	return r.theme
+}
+
+// TileSize は1マスの大きさ（ピクセル）を返す
+func (r *Renderer) TileSize() int {
+	return r.tileSize
+}
+
+// board は描画する盤面（マップファイルとスナップショットの共通形式）
+type board struct {
+	width, height       int
+	cell                func(x, y int) chasermap.CellType
+	hot, cool           chasermap.Position
+	hotAlive, coolAlive bool
+}
+
+// Map はマップファイルの初期盤面（スタート位置に Hot と Cool）を描画する
+func (r *Renderer) Map(m *chasermap.Map) *image.RGBA {
+	return r.draw(board{
+		width:    m.Width,
+		height:   m.Height,
+		cell:     func(x, y int) chasermap.CellType { return m.Cells[y][x] },
+		hot:      m.Hot,
+		cool:     m.Cool,
+		hotAlive: true, coolAlive: true,
+	})
+}
+
+// Snapshot は対戦中・終了時の盤面を描画する（倒れたキャラクターは GUI と同じく半透明）
+func (r *Renderer) Snapshot(s *server.BoardSnapshot) *image.RGBA {
+	return r.draw(board{
+		width:     s.Width,
+		height:    s.Height,
+		cell:      func(x, y int) chasermap.CellType { return chasermap.CellType(s.MapFlat[y*s.Width+x]) },
+		hot:       chasermap.Position{X: s.HotX, Y: s.HotY},
+		cool:      chasermap.Position{X: s.CoolX, Y: s.CoolY},
+		hotAlive:  s.HotAlive,
+		coolAlive: s.CoolAlive,
+	})
+}
+
+func (r *Renderer) draw(b board) *image.RGBA {
+	ts := r.tileSize
+	img := image.NewRGBA(image.Rect(0, 0, b.width*ts, b.height*ts))
+	draw.Draw(img, img.Bounds(), image.NewUniform(background), image.Point{}, draw.Src)
+	for y := 0; y < b.height; y++ {
+		for x := 0; x < b.width; x++ {
+			tile := r.floor
+			switch b.cell(x, y) {
+			case chasermap.Block:
+				tile = r.block
+			case chasermap.Item:
+				tile = r.item
+			}
+			draw.Draw(img, image.Rect(x*ts, y*ts, (x+1)*ts, (y+1)*ts), tile, image.Point{}, draw.Over)
+		}
+	}
+	r.drawCharacter(img, r.hot, b.hot, b.hotAlive)
+	r.drawCharacter(img, r.cool, b.cool, b.coolAlive)
+	return img
+}
+
+func (r *Renderer) drawCharacter(img *image.RGBA, tile *image.RGBA, pos chasermap.Position, alive bool) {
+	ts := r.tileSize
+	rect := image.Rect(pos.X*ts, pos.Y*ts, (pos.X+1)*ts, (pos.Y+1)*ts)
+	if !rect.In(img.Bounds()) {
+		return
+	}
+	var mask image.Image
+	if !alive {
+		mask = image.NewUniform(color.Alpha{A: uint8(deadAlpha * 255)})
+	}
+	draw.DrawMask(img, rect, tile, image.Point{}, mask, image.Point{}, draw.Over)
+}
+
+// scale は src を size×size ピクセルに縮小（拡大）する
+// 各出力ピクセルを、対応する入力範囲の平均（アルファ乗算済み）にする
+func scale(src image.Image, size int) *image.RGBA {
+	sb := src.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, size, size))
+	for dy := 0; dy < size; dy++ {
+		y0 := sb.Min.Y + dy*sb.Dy()/size
+		y1 := max(sb.Min.Y+(dy+1)*sb.Dy()/size, y0+1)
+		for dx := 0; dx < size; dx++ {
+			x0 := sb.Min.X + dx*sb.Dx()/size
+			x1 := max(sb.Min.X+(dx+1)*sb.Dx()/size, x0+1)
+			var r, g, b, a, n uint64
+			for y := y0; y < y1; y++ {
+				for x := x0; x < x1; x++ {
+					cr, cg, cb, ca := src.At(x, y).RGBA()
+					r, g, b, a = r+uint64(cr), g+uint64(cg), b+uint64(cb), a+uint64(ca)
+					n++
+				}
+			}
+			dst.Set(dx, dy, color.RGBA64{R: uint16(r / n), G: uint16(g / n), B: uint16(b / n), A: uint16(a / n)})
+		}
+	}
+	return dst
+}
+
+// Save は img を PNG としてファイルに保存する（ディレクトリがなければ作成する）
+func Save(filename string, img image.Image) error {
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	file, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+	if err := png.Encode(file, img); err != nil {
+		file.Close() // エラー時も明示的にクローズ
+		return fmt.Errorf("failed to encode png: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to close file: %w", err)
+	}
+	return nil
+}
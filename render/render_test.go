@@ -0,0 +1,153 @@
+package render
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kqnade/CHaserGo/chasermap"
+	"github.com/kqnade/CHaserGo/server"
+	"github.com/kqnade/CHaserGo/theme"
+)
+
+// testMap は次の 5×4 のマップを返す
+//
+//	#####
+//	#H*.#
+//	#..C#
+//	#####
+func testMap(t *testing.T) *chasermap.Map {
+	t.Helper()
+	m, err := chasermap.Parse(strings.NewReader("turns: 10\n#####\n#H*.#\n#..C#\n#####"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+// tileAt は img の (x,y) マスの中央のピクセルの色を返す
+func tileAt(img *image.RGBA, ts, x, y int) [4]uint8 {
+	i := img.PixOffset(x*ts+ts/2, y*ts+ts/2)
+	return [4]uint8(img.Pix[i : i+4])
+}
+
+// tilePix は img の (x,y) マスの全ピクセルを返す
+func tilePix(img *image.RGBA, ts, x, y int) string {
+	return string(img.SubImage(image.Rect(x*ts, y*ts, (x+1)*ts, (y+1)*ts)).(*image.RGBA).Pix)
+}
+
+// builtin は組み込みテーマ name を返す
+func builtin(t *testing.T, name string) *theme.Theme {
+	t.Helper()
+	th, err := theme.Named("", name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return th
+}
+
+// solid は size×size の単色の画像を返す
+func solid(size int, c color.RGBA) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, size, size))
+	draw.Draw(img, img.Bounds(), image.NewUniform(c), image.Point{}, draw.Src)
+	return img
+}
+
+func TestNewCustomTheme(t *testing.T) {
+	// ディスクから読み込んだテーマと同じく、theme.Theme の画像をそのまま使う
+	th := &theme.Theme{
+		Name:  "Mono",
+		Floor: solid(20, color.RGBA{R: 10, G: 10, B: 10, A: 255}),
+		Block: solid(20, color.RGBA{R: 200, G: 200, B: 200, A: 255}),
+		Item:  solid(20, color.RGBA{R: 250, G: 220, B: 0, A: 255}),
+		Hot:   solid(20, color.RGBA{R: 255, A: 255}),
+		Cool:  solid(20, color.RGBA{B: 255, A: 255}),
+	}
+	r := New(th, 10)
+	if r.Theme() != "Mono" || r.TileSize() != 10 {
+		t.Errorf("theme %q, tile size %d, want Mono, 10", r.Theme(), r.TileSize())
+	}
+	img := r.Map(testMap(t))
+	if got := tileAt(img, 10, 0, 0); got != [4]uint8{200, 200, 200, 255} {
+		t.Errorf("block = %v", got)
+	}
+	if got := tileAt(img, 10, 1, 1); got != [4]uint8{255, 0, 0, 255} {
+		t.Errorf("hot = %v", got)
+	}
+}
+
+func TestRenderMap(t *testing.T) {
+	m := testMap(t)
+	for _, name := range theme.BuiltinNames {
+		r := New(builtin(t, name), 16)
+		img := r.Map(m)
+		if b := img.Bounds(); b.Dx() != 5*16 || b.Dy() != 4*16 {
+			t.Fatalf("%s: image size %v, want 80x64", name, b)
+		}
+		floor, block, item := tileAt(img, 16, 3, 1), tileAt(img, 16, 0, 0), tileAt(img, 16, 2, 1)
+		if floor == block || floor == item || tileAt(img, 16, 1, 2) != floor {
+			t.Errorf("%s: floor %v, block %v, item %v not distinguishable", name, floor, block, item)
+		}
+		if hot := tileAt(img, 16, 1, 1); hot == floor {
+			t.Errorf("%s: hot not drawn", name)
+		}
+	}
+}
+
+func TestRenderSnapshot(t *testing.T) {
+	m := testMap(t)
+	r := New(builtin(t, "Light"), 12)
+
+	// 初期盤面のスナップショットはマップファイルと同じ画像になる
+	b := server.NewBoardFromMap(m)
+	snap := server.SnapshotFromBoard(b, server.KindTurnEnd, server.TurnStepFirst, server.PhaseRunning, 0, "", "")
+	if string(r.Snapshot(&snap).Pix) != string(r.Map(m).Pix) {
+		t.Error("snapshot of the initial board differs from the map image")
+	}
+
+	// 倒れたキャラクターは半透明で描く
+	alive := tilePix(r.Snapshot(&snap), 12, 3, 2)
+	snap.CoolAlive = false
+	if dead := tilePix(r.Snapshot(&snap), 12, 3, 2); dead == alive {
+		t.Error("dead cool drawn the same as alive")
+	}
+}
+
+func TestContactSheet(t *testing.T) {
+	small := image.NewRGBA(image.Rect(0, 0, 10, 20))
+	large := image.NewRGBA(image.Rect(0, 0, 30, 15))
+	sheet := ContactSheet([]image.Image{small, large, small}, 0)
+	// 3 枚なら 2 列 × 2 行、枠は 30×20
+	want := image.Rect(0, 0, 2*(30+SheetGap)+SheetGap, 2*(20+SheetGap)+SheetGap)
+	if sheet.Bounds() != want {
+		t.Errorf("ContactSheet bounds %v, want %v", sheet.Bounds(), want)
+	}
+	if sheet = ContactSheet([]image.Image{small, large, small}, 5); sheet.Bounds().Dx() != 3*(30+SheetGap)+SheetGap {
+		t.Errorf("columns are not limited to the image count: %v", sheet.Bounds())
+	}
+}
+
+func TestSave(t *testing.T) {
+	r := New(builtin(t, "Heavy"), 8)
+	filename := filepath.Join(t.TempDir(), "sub", "map.png")
+	if err := Save(filename, r.Map(testMap(t))); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	img, err := png.Decode(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if img.Bounds().Dx() != 40 || img.Bounds().Dy() != 32 {
+		t.Errorf("saved image size %v, want 40x32", img.Bounds())
+	}
+}
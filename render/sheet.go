@@ -0,0 +1,44 @@
+package render
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+)
+
+// SheetGap はコンタクトシートの画像同士・外周の間隔（ピクセル）
+const SheetGap = 8
+
+// sheetBackground はコンタクトシートの背景色
+var sheetBackground = color.RGBA{R: 0x30, G: 0x30, B: 0x30, A: 0xff}
+
+// ContactSheet は imgs を columns 列に並べた一覧画像（コンタクトシート）を作成する
+// columns が 0 以下の場合は正方形に近くなる列数にする。大きさの異なる画像は、
+// 最も大きい画像に合わせた枠の中央に置く
+func ContactSheet(imgs []image.Image, columns int) *image.RGBA {
+	if len(imgs) == 0 {
+		return image.NewRGBA(image.Rect(0, 0, 0, 0))
+	}
+	if columns <= 0 {
+		columns = int(math.Ceil(math.Sqrt(float64(len(imgs)))))
+	}
+	columns = min(columns, len(imgs))
+	rows := (len(imgs) + columns - 1) / columns
+
+	var cw, ch int
+	for _, img := range imgs {
+		cw = max(cw, img.Bounds().Dx())
+		ch = max(ch, img.Bounds().Dy())
+	}
+
+	sheet := image.NewRGBA(image.Rect(0, 0, columns*(cw+SheetGap)+SheetGap, rows*(ch+SheetGap)+SheetGap))
+	draw.Draw(sheet, sheet.Bounds(), image.NewUniform(sheetBackground), image.Point{}, draw.Src)
+	for i, img := range imgs {
+		b := img.Bounds()
+		x := SheetGap + (i%columns)*(cw+SheetGap) + (cw-b.Dx())/2
+		y := SheetGap + (i/columns)*(ch+SheetGap) + (ch-b.Dy())/2
+		draw.Draw(sheet, image.Rect(x, y, x+b.Dx(), y+b.Dy()), img, b.Min, draw.Over)
+	}
+	return sheet
+}
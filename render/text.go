@@ -0,0 +1,69 @@
+package render
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+
+	"github.com/hajimehoshi/bitmapfont/v3"
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+)
+
+// fontFace は情報欄の文字に使う日本語対応のビットマップフォント（12px、GUI と同じ）
+// 「Ⅲ」などの East Asian Ambiguous な文字も全角で描く
+var fontFace = bitmapfont.FaceEA
+
+// textHeight は1倍のときの1行の文字の高さ（px）を返す
+func textHeight() int {
+	m := fontFace.Metrics()
+	return (m.Ascent + m.Descent).Ceil()
+}
+
+// textWidth は s を scale 倍で描いたときの幅（px）を返す
+func textWidth(s string, scale int) int {
+	return font.MeasureString(fontFace, s).Ceil() * scale
+}
+
+// drawText は img の (x, y) を左上として s を scale 倍の大きさで描く
+// 1倍で描いた文字を整数倍に拡大するため、ドット絵のまま大きくなる（盤面外にはみ出した部分は描かない）
+func drawText(img *image.RGBA, x, y int, s string, c color.Color, scale int) {
+	w, h := textWidth(s, 1), textHeight()
+	if w == 0 {
+		return
+	}
+	mask := image.NewAlpha(image.Rect(0, 0, w, h))
+	d := font.Drawer{Dst: mask, Src: image.Opaque, Face: fontFace, Dot: fixed.P(0, fontFace.Metrics().Ascent.Ceil())}
+	d.DrawString(s)
+	if scale > 1 {
+		mask = scaleAlpha(mask, scale)
+	}
+	r := image.Rect(x, y, x+w*scale, y+h*scale)
+	draw.DrawMask(img, r, image.NewUniform(c), image.Point{}, mask, image.Point{}, draw.Over)
+}
+
+// scaleAlpha は src を scale 倍に拡大する（最近傍）
+func scaleAlpha(src *image.Alpha, scale int) *image.Alpha {
+	b := src.Bounds()
+	dst := image.NewAlpha(image.Rect(0, 0, b.Dx()*scale, b.Dy()*scale))
+	for y := 0; y < dst.Rect.Dy(); y++ {
+		for x := 0; x < dst.Rect.Dx(); x++ {
+			dst.Pix[dst.PixOffset(x, y)] = src.Pix[src.PixOffset(b.Min.X+x/scale, b.Min.Y+y/scale)]
+		}
+	}
+	return dst
+}
+
+// fitText は s が幅 width に収まらない場合、末尾を ".." にして切り詰める
+func fitText(s string, width, scale int) string {
+	if textWidth(s, scale) <= width {
+		return s
+	}
+	rs := []rune(s)
+	for n := len(rs) - 1; n > 0; n-- {
+		if c := string(rs[:n]) + ".."; textWidth(c, scale) <= width {
+			return c
+		}
+	}
+	return ""
+}
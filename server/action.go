@@ -0,0 +1,149 @@
+package server
+
+import "time"
+
+// ActionRecord は1プレイヤー分の半ターン（Ready 〜 行動 〜 '#' 確認）の記録
+//
+// processTurn が途中で失敗した場合も、そこまでに分かった内容と Error を保持する。
+// JSON Lines ダンプの "action" レコードはこの型をそのまま出力する。
+type ActionRecord struct {
+	Turn   int      `json:"turn"`
+	Step   TurnStep `json:"step"`
+	Player string   `json:"player"` // "hot" または "cool"
+	Name   string   `json:"name"`
+
+	// Ready は getReady への応答（周辺9マス）
+	Ready [10]int `json:"ready"`
+	// Command はクライアントから受信した生のコマンド（"wu" など）
+	Command string `json:"command,omitempty"`
+	// Action は ParseAction の結果（"wk"/"lk"/"sc"/"pt"）。パース前に失敗した場合は空
+	Action string `json:"action,omitempty"`
+	// Direction は Action が空でない場合のみ意味を持つ
+	Direction Direction `json:"direction"`
+	// Response は行動への応答
+	Response [10]int `json:"response"`
+
+	// ReadyWait は "Ready" 送信から "gr" 受信までの時間
+	ReadyWait time.Duration `json:"readyWaitNs"`
+	// ThinkTime は Ready 応答の送信から行動コマンド受信までの時間
+	ThinkTime time.Duration `json:"thinkTimeNs"`
+	// AckWait は行動への応答送信から '#' 受信までの時間
+	AckWait time.Duration `json:"ackWaitNs"`
+
+	// Changes は行動による盤面の変化
+	Changes []CellChange `json:"changes,omitempty"`
+	// Hot, Cool は行動後の両プレイヤーの状態
+	Hot      PlayerState `json:"hot"`
+	Cool     PlayerState `json:"cool"`
+	GameOver bool        `json:"gameOver"`
+	Error    string      `json:"error,omitempty"`
+}
+
+// PlayerAction はプレイヤーが直前に行った行動（GUI のオーバーレイ表示用）
+// BoardSnapshot 間で共有するため、作成後は変更しない
+type PlayerAction struct {
+	Turn int `json:"turn"`
+	// Action は "wk"/"lk"/"sc"/"pt" のいずれか
+	Action    string    `json:"action"`
+	Direction Direction `json:"direction"`
+	// From は行動前の位置
+	From Position `json:"from"`
+	// Cells は行動の対象のマス（盤面外を含むことがある）
+	// wk: 移動先、lk: 2マス先、sc: 直線上の9マス（近い順）、pt: ブロックを置くマス
+	Cells []Position `json:"cells"`
+	// Response は行動への応答
+	Response [10]int `json:"response"`
+}
+
+// clone は a のコピーを返す（a が nil なら nil）
+func (a *PlayerAction) clone() *PlayerAction {
+	if a == nil {
+		return nil
+	}
+	c := *a
+	c.Cells = append([]Position(nil), a.Cells...)
+	return &c
+}
+
+// NewPlayerAction は from にいたプレイヤーの半ターンの記録 rec から PlayerAction を作成する
+// 行動を受信できなかった記録（rec.Action が空）の場合は nil を返す
+func NewPlayerAction(rec *ActionRecord, from Position) *PlayerAction {
+	if rec.Action == "" {
+		return nil
+	}
+	a := &PlayerAction{
+		Turn:      rec.Turn,
+		Action:    rec.Action,
+		Direction: rec.Direction,
+		From:      from,
+		Response:  rec.Response,
+	}
+	n := 1
+	switch rec.Action {
+	case "lk":
+		n = 2
+	case "sc":
+		n = 9
+	}
+	pos := from
+	for i := 0; i < n; i++ {
+		pos = movePosition(pos, rec.Direction)
+		if rec.Action != "lk" || i == n-1 {
+			a.Cells = append(a.Cells, pos)
+		}
+	}
+	return a
+}
+
+// CellChange は1マス分の盤面差分
+type CellChange struct {
+	X    int      `json:"x"`
+	Y    int      `json:"y"`
+	From CellType `json:"from"`
+	To   CellType `json:"to"`
+}
+
+// PlayerState はある時点のプレイヤーの位置・アイテム数・生存
+type PlayerState struct {
+	X     int  `json:"x"`
+	Y     int  `json:"y"`
+	Items int  `json:"items"`
+	Alive bool `json:"alive"`
+}
+
+func playerState(c *Character) PlayerState {
+	return PlayerState{X: c.Position.X, Y: c.Position.Y, Items: c.Items, Alive: c.IsAlive}
+}
+
+// cloneCells は差分計算用に盤面をコピーする
+func (b *Board) cloneCells() [][]CellType {
+	cells := make([][]CellType, len(b.MapData))
+	for y, row := range b.MapData {
+		cells[y] = append([]CellType(nil), row...)
+	}
+	return cells
+}
+
+// diffCells は before から現在の盤面への変化を返す
+func (b *Board) diffCells(before [][]CellType) []CellChange {
+	var changes []CellChange
+	for y, row := range b.MapData {
+		for x, c := range row {
+			if before[y][x] != c {
+				changes = append(changes, CellChange{X: x, Y: y, From: before[y][x], To: c})
+			}
+		}
+	}
+	return changes
+}
+
+// finish は行動後の盤面から差分とプレイヤー状態を埋める
+func (r *ActionRecord) finish(b *Board, before [][]CellType, err error) {
+	r.Changes = b.diffCells(before)
+	r.Hot = playerState(b.Hot)
+	r.Cool = playerState(b.Cool)
+	r.GameOver = b.GameOver
+	if err != nil {
+		r.Error = err.Error()
+	}
+}
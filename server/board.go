@@ -1,21 +1,19 @@
 package server
 
 import (
-	"bufio"
 	"errors"
 	"fmt"
-	"os"
-	"strconv"
-	"strings"
+
+	"github.com/kqnade/CHaserGo/chasermap"
 )
 
 // CellType represents a cell on the board
-type CellType int
+type CellType = chasermap.CellType
 
 const (
-	Empty CellType = 0
-	Wall  CellType = 2
-	Item  CellType = 3
+	Empty = chasermap.Empty
+	Wall  = chasermap.Block
+	Item  = chasermap.Item
 )
 
 // Direction represents movement direction
@@ -28,12 +26,40 @@ const (
 	Right
 )
 
-// Position represents a coordinate on the board
-type Position struct {
-	X int
-	Y int
+func (d Direction) String() string {
+	switch d {
+	case Up:
+		return "up"
+	case Down:
+		return "down"
+	case Left:
+		return "left"
+	case Right:
+		return "right"
+	default:
+		return "unknown"
+	}
+}
+
+// MarshalText は JSON 出力で方向を文字列として表すために実装する
+func (d Direction) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
 }
 
+// UnmarshalText は MarshalText の逆変換（JSON Lines ダンプの読み込み用）
+func (d *Direction) UnmarshalText(text []byte) error {
+	for _, c := range []Direction{Up, Down, Left, Right} {
+		if c.String() == string(text) {
+			*d = c
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid direction: %q", text)
+}
+
+// Position represents a coordinate on the board
+type Position = chasermap.Position
+
 // Character represents a player character
 type Character struct {
 	Name     string
@@ -44,165 +70,42 @@ type Character struct {
 
 // Board manages the game state
 type Board struct {
-	MapData   [][]CellType
-	Width     int
-	Height    int
-	MaxTurns  int
-	Hot       *Character
-	Cool      *Character
-	Turn      int
-	GameOver  bool
-	mapPath   string
+	Name     string
+	MapData  [][]CellType
+	Width    int
+	Height   int
+	MaxTurns int
+	Hot      *Character
+	Cool     *Character
+	Turn     int
+	GameOver bool
 }
 
 // NewBoard creates a new board from a map file
 func NewBoard(mapPath string) (*Board, error) {
-	file, err := os.Open(mapPath)
+	m, err := chasermap.Load(mapPath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to open map file: %w", err)
+		return nil, err
 	}
-	defer file.Close()
+	return NewBoardFromMap(m), nil
+}
 
+// NewBoardFromMap は読み込み済みのマップから盤面を作成する
+// m は chasermap.Map.Validate を満たしている必要がある。盤面のマスは m と共有しない
+func NewBoardFromMap(m *chasermap.Map) *Board {
 	board := &Board{
-		mapPath: mapPath,
-		Hot:     &Character{IsAlive: true},
-		Cool:    &Character{IsAlive: true},
-		Turn:    0,
-	}
-
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		line := scanner.Text()
-		if len(line) < 2 {
-			continue
-		}
-
-		prefix := line[:2]
-		data := strings.TrimSpace(line[2:])
-
-		switch prefix {
-		case "N ":
-			// N行は未使用
-			continue
-		case "T ":
-			// ターン数
-			board.MaxTurns, err = strconv.Atoi(data)
-			if err != nil {
-				return nil, fmt.Errorf("invalid turn count: %w", err)
-			}
-		case "S ":
-			// サイズ（座標系を反転）
-			parts := strings.Split(data, ",")
-			if len(parts) != 2 {
-				return nil, errors.New("invalid size format")
-			}
-			board.Height, err = strconv.Atoi(parts[0])
-			if err != nil {
-				return nil, fmt.Errorf("invalid height: %w", err)
-			}
-			board.Width, err = strconv.Atoi(parts[1])
-			if err != nil {
-				return nil, fmt.Errorf("invalid width: %w", err)
-			}
-			// マップデータ初期化
-			board.MapData = make([][]CellType, board.Height)
-			for i := range board.MapData {
-				board.MapData[i] = make([]CellType, board.Width)
-			}
-		case "D ":
-			// マップデータ
-			parts := strings.Split(data, ",")
-			if len(parts) < 3 {
-				return nil, errors.New("invalid map data format")
-			}
-			y, err := strconv.Atoi(parts[0])
-			if err != nil {
-				return nil, fmt.Errorf("invalid y coordinate: %w", err)
-			}
-			x, err := strconv.Atoi(parts[1])
-			if err != nil {
-				return nil, fmt.Errorf("invalid x coordinate: %w", err)
-			}
-			value, err := strconv.Atoi(parts[2])
-			if err != nil {
-				return nil, fmt.Errorf("invalid cell value: %w", err)
-			}
-			if y >= 0 && y < board.Height && x >= 0 && x < board.Width {
-				board.MapData[y][x] = CellType(value)
-			}
-		case "H ":
-			// Hot(先攻)の初期位置
-			parts := strings.Split(data, ",")
-			if len(parts) != 2 {
-				return nil, errors.New("invalid hot position format")
-			}
-			board.Hot.Position.Y, err = strconv.Atoi(parts[0])
-			if err != nil {
-				return nil, fmt.Errorf("invalid hot y: %w", err)
-			}
-			board.Hot.Position.X, err = strconv.Atoi(parts[1])
-			if err != nil {
-				return nil, fmt.Errorf("invalid hot x: %w", err)
-			}
-		case "C ":
-			// Cool(後攻)の初期位置
-			parts := strings.Split(data, ",")
-			if len(parts) != 2 {
-				return nil, errors.New("invalid cool position format")
-			}
-			board.Cool.Position.Y, err = strconv.Atoi(parts[0])
-			if err != nil {
-				return nil, fmt.Errorf("invalid cool y: %w", err)
-			}
-			board.Cool.Position.X, err = strconv.Atoi(parts[1])
-			if err != nil {
-				return nil, fmt.Errorf("invalid cool x: %w", err)
-			}
-		}
-	}
-
-	if err := scanner.Err(); err != nil {
-		return nil, fmt.Errorf("error reading map file: %w", err)
-	}
-
-	// --- 入力値バリデーション ---
-	if board.MaxTurns <= 0 {
-		return nil, fmt.Errorf("invalid max turns: %d (must be > 0)", board.MaxTurns)
-	}
-	if board.Width <= 0 || board.Height <= 0 {
-		return nil, fmt.Errorf("invalid board size: %dx%d", board.Width, board.Height)
+		Name:     m.Name,
+		MapData:  make([][]CellType, m.Height),
+		Width:    m.Width,
+		Height:   m.Height,
+		MaxTurns: m.Turns,
+		Hot:      &Character{Position: m.Hot, IsAlive: true},
+		Cool:     &Character{Position: m.Cool, IsAlive: true},
 	}
-	// セル値の検証
-	for y := 0; y < board.Height; y++ {
-		for x := 0; x < board.Width; x++ {
-			v := board.MapData[y][x]
-			if v != Empty && v != Wall && v != Item {
-				return nil, fmt.Errorf("invalid cell value %d at (%d,%d)", v, y, x)
-			}
-		}
-	}
-	// Hot の初期位置検証
-	hp := board.Hot.Position
-	if hp.Y < 0 || hp.Y >= board.Height || hp.X < 0 || hp.X >= board.Width {
-		return nil, fmt.Errorf("hot start position (%d,%d) is out of bounds", hp.Y, hp.X)
-	}
-	if board.MapData[hp.Y][hp.X] == Wall {
-		return nil, fmt.Errorf("hot start position (%d,%d) is on a wall", hp.Y, hp.X)
-	}
-	// Cool の初期位置検証
-	cp := board.Cool.Position
-	if cp.Y < 0 || cp.Y >= board.Height || cp.X < 0 || cp.X >= board.Width {
-		return nil, fmt.Errorf("cool start position (%d,%d) is out of bounds", cp.Y, cp.X)
-	}
-	if board.MapData[cp.Y][cp.X] == Wall {
-		return nil, fmt.Errorf("cool start position (%d,%d) is on a wall", cp.Y, cp.X)
+	for y, row := range m.Cells {
+		board.MapData[y] = append([]CellType(nil), row...)
 	}
-	// Hot と Cool が同じ位置でないことを確認
-	if hp == cp {
-		return nil, fmt.Errorf("hot and cool start at the same position (%d,%d)", hp.Y, hp.X)
-	}
-
-	return board, nil
+	return board
 }
 
 // GetCell returns the cell type at the given position
@@ -230,18 +133,22 @@ func (b *Board) GetOpponent(char *Character) *Character {
 
 // Move calculates the new position based on direction
 func (b *Board) Move(pos Position, dir Direction) Position {
-	newPos := pos
+	return movePosition(pos, dir)
+}
+
+// movePosition は pos から dir に1マス進んだ位置を返す（盤面の範囲は考慮しない）
+func movePosition(pos Position, dir Direction) Position {
 	switch dir {
 	case Up:
-		newPos.Y--
+		pos.Y--
 	case Down:
-		newPos.Y++
+		pos.Y++
 	case Left:
-		newPos.X--
+		pos.X--
 	case Right:
-		newPos.X++
+		pos.X++
 	}
-	return newPos
+	return pos
 }
 
 // Walk moves the character and collects items
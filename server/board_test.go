@@ -61,6 +61,39 @@ func TestNewBoard(t *testing.T) {
 	}
 }
 
+func TestNewBoardColonFormat(t *testing.T) {
+	// 7×5 の盤面。コロン区切りの書式では S・H・C は x,y の順
+	//
+	//	#######
+	//	#..*C.#
+	//	#.#...#
+	//	#H...*#
+	//	#######
+	b, err := NewBoard("testdata/test_colon.map")
+	if err != nil {
+		t.Fatalf("NewBoard(colon): %v", err)
+	}
+	if b.Width != 7 || b.Height != 5 || b.MaxTurns != 100 {
+		t.Fatalf("board %dx%d, %d turns; want 7x5, 100 turns", b.Width, b.Height, b.MaxTurns)
+	}
+	if b.Hot.Position != (Position{X: 1, Y: 3}) || b.Cool.Position != (Position{X: 4, Y: 1}) {
+		t.Errorf("Hot %+v, Cool %+v; want Hot {X:1 Y:3}, Cool {X:4 Y:1}", b.Hot.Position, b.Cool.Position)
+	}
+	for _, tt := range []struct {
+		pos  Position
+		want CellType
+	}{
+		{Position{X: 3, Y: 1}, Item},
+		{Position{X: 1, Y: 3}, Empty},
+		{Position{X: 2, Y: 2}, Wall},
+		{Position{X: 5, Y: 3}, Item},
+	} {
+		if got := b.GetCell(tt.pos); got != tt.want {
+			t.Errorf("GetCell(%+v) = %v, want %v", tt.pos, got, tt.want)
+		}
+	}
+}
+
 func TestNewBoardFileNotFound(t *testing.T) {
 	_, err := NewBoard("testdata/nonexistent.map")
 	if err == nil {
@@ -0,0 +1,181 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// ErrAborted は制御APIの Abort によってゲームが中断されたことを示す
+var ErrAborted = errors.New("game aborted")
+
+// ErrNotRunning は Start 実行中でないサーバーへの操作を示す
+var ErrNotRunning = errors.New("server is not running")
+
+// PlayerStatus は制御APIで公開するプレイヤーの状態
+type PlayerStatus struct {
+	Name      string `json:"name"`
+	Connected bool   `json:"connected"`
+	Items     int    `json:"items"`
+	Alive     bool   `json:"alive"`
+}
+
+// Status は制御APIの GET /status が返すサーバーの状態
+type Status struct {
+	Phase    SnapshotPublicPhase `json:"phase"`
+	Revision uint64              `json:"revision"`
+	Turn     int                 `json:"turn"`
+	MaxTurns int                 `json:"maxTurns"`
+	Hot      PlayerStatus        `json:"hot"`
+	Cool     PlayerStatus        `json:"cool"`
+	Winner   string              `json:"winner,omitempty"`
+	Reason   string              `json:"reason,omitempty"`
+	NextMap  string              `json:"nextMap,omitempty"`
+}
+
+// Snapshot は最後に発行されたスナップショットを返す
+// SnapshotCh の有無にかかわらず、他の goroutine から安全に呼び出せる
+func (s *Server) Snapshot() BoardSnapshot {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.latest
+}
+
+// History はこれまでに発行された全てのスナップショットを発行順に返す（GIF への書き出しなど用）
+// ServerConfig.KeepHistory が false の場合は nil を返す。
+// SnapshotCh と違い取りこぼしはない。各スナップショットは deep copy なので呼び出し側で変更してよい
+func (s *Server) History() []BoardSnapshot {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.history == nil {
+		return nil
+	}
+	h := make([]BoardSnapshot, len(s.history))
+	for i := range s.history {
+		h[i] = s.history[i].Clone()
+	}
+	return h
+}
+
+// Status は現在のフェーズ・プレイヤー・ターン・アイテム数をまとめて返す
+func (s *Server) Status() Status {
+	next := s.NextMap()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	snap := s.latest
+	return Status{
+		Phase:    snap.Phase,
+		Revision: snap.Revision,
+		Turn:     snap.Turn,
+		MaxTurns: snap.MaxTurns,
+		Hot: PlayerStatus{
+			Name:      snap.HotName,
+			Connected: s.hotConnected,
+			Items:     snap.HotItems,
+			Alive:     snap.HotAlive,
+		},
+		Cool: PlayerStatus{
+			Name:      snap.CoolName,
+			Connected: s.coolConnected,
+			Items:     snap.CoolItems,
+			Alive:     snap.CoolAlive,
+		},
+		Winner:  snap.WinnerName,
+		Reason:  snap.Reason,
+		NextMap: next,
+	}
+}
+
+// Abort は実行中のゲーム（接続待ちを含む）を reason 付きで中断する
+// Start は ErrAborted をラップしたエラーを返す
+func (s *Server) Abort(reason string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cancel == nil || s.latest.Phase == PhaseGameOver || s.latest.Phase == PhaseError {
+		return ErrNotRunning
+	}
+	if reason == "" {
+		reason = "aborted by operator"
+	}
+	s.cancel(fmt.Errorf("%w: %s", ErrAborted, reason))
+	return nil
+}
+
+// NextMap は次のゲームで使用するマップのパスを返す（未選択なら空文字）
+// Controller に登録されている場合は Controller の選択を返す
+func (s *Server) NextMap() string {
+	s.mu.Lock()
+	c := s.controller
+	next := s.nextMap
+	s.mu.Unlock()
+	if c != nil {
+		return c.NextMap()
+	}
+	return next
+}
+
+// SetNextMap は次のゲームで使用するマップを選択する
+// マップは NewBoard で検証され、空文字を渡すと選択を解除する
+func (s *Server) SetNextMap(path string) error {
+	if path != "" {
+		if _, err := NewBoard(path); err != nil {
+			return err
+		}
+	}
+	s.mu.Lock()
+	c := s.controller
+	if c == nil {
+		s.nextMap = path
+	}
+	s.mu.Unlock()
+	if c != nil {
+		c.setNextMap(path)
+	}
+	return nil
+}
+
+// ControlHandler は運営者向けの制御APIを返す
+//
+//	GET  /status    フェーズ・プレイヤー名・ターン・アイテム数
+//	GET  /snapshot  最新の BoardSnapshot（JSON）
+//	POST /abort     ゲームを中断する（reason パラメータ）
+//	GET  /next-map  次のゲームのマップ
+//	POST /next-map  次のゲームのマップを選択する（path パラメータ、空で解除）
+func (s *Server) ControlHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /status", func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(w, http.StatusOK, s.Status())
+	})
+	mux.HandleFunc("GET /snapshot", func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(w, http.StatusOK, s.Snapshot())
+	})
+	mux.HandleFunc("POST /abort", func(w http.ResponseWriter, r *http.Request) {
+		if err := s.Abort(r.FormValue("reason")); err != nil {
+			writeError(w, http.StatusConflict, err)
+			return
+		}
+		writeJSON(w, http.StatusAccepted, s.Status())
+	})
+	mux.HandleFunc("GET /next-map", func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]string{"path": s.NextMap()})
+	})
+	mux.HandleFunc("POST /next-map", func(w http.ResponseWriter, r *http.Request) {
+		if err := s.SetNextMap(r.FormValue("path")); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]string{"path": s.NextMap()})
+	})
+	return mux
+}
+
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, code int, err error) {
+	writeJSON(w, code, map[string]string{"error": err.Error()})
+}
@@ -0,0 +1,254 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestServer(t *testing.T) *Server {
+	t.Helper()
+	s, err := NewServer(ServerConfig{MapPath: "testdata/test.map"})
+	if err != nil {
+		t.Fatalf("NewServer: %v", err)
+	}
+	return s
+}
+
+func TestControlStatus(t *testing.T) {
+	s := newTestServer(t)
+	rec := httptest.NewRecorder()
+	s.ControlHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/status", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status code = %d, want 200", rec.Code)
+	}
+	var st struct {
+		Phase    string `json:"phase"`
+		MaxTurns int    `json:"maxTurns"`
+		Hot      PlayerStatus
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&st); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if st.Phase != "waiting" {
+		t.Errorf("phase = %q, want \"waiting\"", st.Phase)
+	}
+	if st.MaxTurns != 100 {
+		t.Errorf("maxTurns = %d, want 100", st.MaxTurns)
+	}
+	if st.Hot.Connected {
+		t.Error("hot should not be connected yet")
+	}
+}
+
+func TestControlSnapshot(t *testing.T) {
+	s := newTestServer(t)
+	rec := httptest.NewRecorder()
+	s.ControlHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/snapshot", nil))
+
+	var snap struct {
+		Kind    string `json:"kind"`
+		Width   int    `json:"width"`
+		MapFlat []int  `json:"mapFlat"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&snap); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if snap.Kind != "initial" {
+		t.Errorf("kind = %q, want \"initial\"", snap.Kind)
+	}
+	if snap.Width != 5 || len(snap.MapFlat) != 25 {
+		t.Errorf("width = %d, len(mapFlat) = %d, want 5, 25", snap.Width, len(snap.MapFlat))
+	}
+}
+
+func TestHistory(t *testing.T) {
+	s, err := NewServer(ServerConfig{MapPath: "testdata/test.map", KeepHistory: true})
+	if err != nil {
+		t.Fatalf("NewServer: %v", err)
+	}
+	s.hotAction = &PlayerAction{Action: "wk", Cells: []Position{{X: 1, Y: 1}}}
+	s.publishSnapshot(KindActionEnd, TurnStepFirst, PhaseRunning, "", "")
+	s.publishSnapshot(KindTurnEnd, TurnStepSecond, PhaseRunning, "", "")
+
+	h := s.History()
+	if len(h) != 3 {
+		t.Fatalf("len(History()) = %d, want 3", len(h))
+	}
+	for i, want := range []SnapshotKind{KindInitial, KindActionEnd, KindTurnEnd} {
+		if h[i].Kind != want || h[i].Revision != uint64(i+1) {
+			t.Errorf("History()[%d] = %v rev %d, want %v rev %d", i, h[i].Kind, h[i].Revision, want, i+1)
+		}
+	}
+	h[0].Kind = KindError
+	h[0].MapFlat[0] = -1
+	h[1].HotAction.Cells[0].X = 9
+	h[1].HotView.Cells[0] = 9
+	again := s.History()
+	if again[0].Kind != KindInitial || again[0].MapFlat[0] == -1 {
+		t.Error("modifying the returned history changed the server's history")
+	}
+	if again[1].HotAction.Cells[0].X != 1 || again[1].HotView.Cells[0] == 9 {
+		t.Error("the returned history shares actions or views with the server")
+	}
+
+	// KeepHistory が false なら保持しない
+	if h := newTestServer(t).History(); h != nil {
+		t.Errorf("History() without KeepHistory = %d snapshots, want nil", len(h))
+	}
+}
+
+// recorder は Record で受け取ったスナップショットを保持する SnapshotRecorder
+type recorder struct{ snaps []BoardSnapshot }
+
+func (r *recorder) Record(snap BoardSnapshot) { r.snaps = append(r.snaps, snap) }
+
+func TestRecorder(t *testing.T) {
+	rec := &recorder{}
+	ch := make(chan BoardSnapshot, 1)
+	s, err := NewServer(ServerConfig{MapPath: "testdata/test.map", SnapshotCh: ch, Recorder: rec})
+	if err != nil {
+		t.Fatalf("NewServer: %v", err)
+	}
+	for range 5 {
+		s.publishSnapshot(KindActionEnd, TurnStepFirst, PhaseRunning, "", "")
+	}
+
+	// SnapshotCh には最新だけが残るが、Recorder には全て届く
+	if got := (<-ch).Revision; got != 6 {
+		t.Errorf("SnapshotCh revision = %d, want 6", got)
+	}
+	if len(rec.snaps) != 6 {
+		t.Fatalf("recorded %d snapshots, want 6", len(rec.snaps))
+	}
+	for i, snap := range rec.snaps {
+		if snap.Revision != uint64(i+1) {
+			t.Errorf("recorded[%d].Revision = %d, want %d", i, snap.Revision, i+1)
+		}
+	}
+}
+
+func TestControlNextMap(t *testing.T) {
+	s := newTestServer(t)
+	h := s.ControlHandler()
+
+	post := func(path string) *httptest.ResponseRecorder {
+		form := url.Values{"path": {path}}
+		req := httptest.NewRequest(http.MethodPost, "/next-map", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := post("testdata/nonexistent.map"); rec.Code != http.StatusBadRequest {
+		t.Errorf("invalid map: status code = %d, want 400", rec.Code)
+	}
+	if s.NextMap() != "" {
+		t.Errorf("NextMap = %q after invalid selection, want empty", s.NextMap())
+	}
+
+	if rec := post("testdata/test.map"); rec.Code != http.StatusOK {
+		t.Errorf("valid map: status code = %d, want 200", rec.Code)
+	}
+	if s.NextMap() != "testdata/test.map" {
+		t.Errorf("NextMap = %q, want testdata/test.map", s.NextMap())
+	}
+
+	if rec := post(""); rec.Code != http.StatusOK {
+		t.Errorf("clear: status code = %d, want 200", rec.Code)
+	}
+	if s.NextMap() != "" {
+		t.Errorf("NextMap = %q after clear, want empty", s.NextMap())
+	}
+}
+
+func TestController(t *testing.T) {
+	c := NewController()
+	get := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		c.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec
+	}
+	if rec := get("/status"); rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("before SetServer: status code = %d, want 503", rec.Code)
+	}
+
+	first := newTestServer(t)
+	c.SetServer(first)
+	form := url.Values{"path": {"testdata/test.map"}}
+	req := httptest.NewRequest(http.MethodPost, "/next-map", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec := httptest.NewRecorder()
+	c.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("POST /next-map: status code = %d, want 200", rec.Code)
+	}
+
+	// 次の対戦の Server に差し替えても選択は残る（対戦の合間に選んだ場合も同じ）
+	second := newTestServer(t)
+	second.publishSnapshot(KindActionEnd, TurnStepFirst, PhaseRunning, "", "")
+	c.SetServer(second)
+	var st struct {
+		Revision uint64 `json:"revision"`
+		NextMap  string `json:"nextMap"`
+	}
+	if err := json.NewDecoder(get("/status").Body).Decode(&st); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if st.Revision != 2 || st.NextMap != "testdata/test.map" {
+		t.Errorf("status = revision %d, next map %q, want the second server's revision 2 and testdata/test.map", st.Revision, st.NextMap)
+	}
+	if got := c.TakeNextMap(); got != "testdata/test.map" {
+		t.Errorf("TakeNextMap = %q, want testdata/test.map", got)
+	}
+	if c.NextMap() != "" || second.NextMap() != "" {
+		t.Error("TakeNextMap did not clear the selection")
+	}
+}
+
+func TestControlAbort(t *testing.T) {
+	s, err := NewServer(ServerConfig{MapPath: "testdata/test.map", HotPort: 0, CoolPort: 0})
+	if err != nil {
+		t.Fatalf("NewServer: %v", err)
+	}
+
+	if err := s.Abort("too early"); !errors.Is(err, ErrNotRunning) {
+		t.Fatalf("Abort before Start = %v, want ErrNotRunning", err)
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- s.Start(context.Background()) }()
+
+	// Start が中断可能になるまで待つ
+	deadline := time.Now().Add(2 * time.Second)
+	for s.Abort("stuck") != nil {
+		if time.Now().After(deadline) {
+			t.Fatal("server did not become abortable")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, ErrAborted) {
+			t.Fatalf("Start error = %v, want ErrAborted", err)
+		}
+		if !strings.Contains(err.Error(), "stuck") {
+			t.Errorf("Start error = %q, want reason \"stuck\"", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Start did not return after Abort")
+	}
+
+	if st := s.Status(); !strings.Contains(st.Reason, "stuck") {
+		t.Errorf("Status().Reason = %q, want abort reason", st.Reason)
+	}
+}
@@ -0,0 +1,93 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+)
+
+// ErrNoGame は Controller にまだ Server が登録されていないことを示す
+var ErrNoGame = errors.New("no game has been set up")
+
+// Controller は連続対戦のあいだ止まらずに制御APIを提供する
+//
+// 対戦ごとに作り直す Server を SetServer で差し替え、リクエストはその時点の Server の
+// ControlHandler に渡す。Server.Start が自前で立てる制御APIは対戦の終了とともに閉じるが、
+// Controller のリスナーは対戦の合間も開いたままなので、その間に次のマップを選べる。
+// 次のマップの選択は Controller が保持するため、Server を差し替えても失われない。
+type Controller struct {
+	mu      sync.Mutex
+	handler http.Handler // 現在の Server の ControlHandler
+	nextMap string
+}
+
+// compile-time check
+var _ http.Handler = (*Controller)(nil)
+
+// NewController creates a new Controller
+func NewController() *Controller {
+	return &Controller{}
+}
+
+// SetServer は制御APIの対象を s に切り替える
+// s の ServerConfig.ControlAddr は空にしておく（リスナーは Controller.Serve が持つ）
+func (c *Controller) SetServer(s *Server) {
+	s.mu.Lock()
+	s.controller = c
+	s.mu.Unlock()
+
+	c.mu.Lock()
+	c.handler = s.ControlHandler()
+	c.mu.Unlock()
+}
+
+// ServeHTTP はリクエストを現在の Server の制御APIに渡す
+func (c *Controller) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	c.mu.Lock()
+	h := c.handler
+	c.mu.Unlock()
+	if h == nil {
+		writeError(w, http.StatusServiceUnavailable, ErrNoGame)
+		return
+	}
+	h.ServeHTTP(w, r)
+}
+
+// Serve は addr で制御APIを提供し、実際に待ち受けるアドレスと停止用の関数を返す
+// ホスト部を省略した場合（":8080"）は ServerConfig.ControlAddr と同様に "127.0.0.1" にする
+func (c *Controller) Serve(addr string) (bound string, stop func(), err error) {
+	return serveHTTP(addr, c)
+}
+
+// NextMap は次のゲームで使用するマップのパスを返す（未選択なら空文字）
+func (c *Controller) NextMap() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.nextMap
+}
+
+// SetNextMap は次のゲームで使用するマップを選択する（Server.SetNextMap と同じく NewBoard で検証する）
+func (c *Controller) SetNextMap(path string) error {
+	if path != "" {
+		if _, err := NewBoard(path); err != nil {
+			return err
+		}
+	}
+	c.setNextMap(path)
+	return nil
+}
+
+func (c *Controller) setNextMap(path string) {
+	c.mu.Lock()
+	c.nextMap = path
+	c.mu.Unlock()
+}
+
+// TakeNextMap は次のマップの選択を返して解除する（次の対戦を作るときに呼ぶ）
+func (c *Controller) TakeNextMap() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	next := c.nextMap
+	c.nextMap = ""
+	return next
+}
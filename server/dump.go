@@ -2,48 +2,97 @@ package server
 
 import (
 	"bufio"
+	"encoding/json"
 	"fmt"
 	"os"
 	"strings"
+
+	"github.com/kqnade/CHaserGo/chasermap"
+)
+
+// DumpFormat はダンプファイルの形式
+type DumpFormat string
+
+const (
+	// DumpFormatViewer は CHaserViewer 互換の形式（1ターンごとに盤面全体を記録）
+	DumpFormatViewer DumpFormat = "viewer"
+	// DumpFormatJSONL は JSON Lines 形式（半ターンごとに行動と盤面差分を記録）
+	DumpFormatJSONL DumpFormat = "jsonl"
 )
 
+// ParseDumpFormat はフラグ等の文字列から DumpFormat を得る（空文字は viewer）
+func ParseDumpFormat(s string) (DumpFormat, error) {
+	switch DumpFormat(s) {
+	case "", DumpFormatViewer:
+		return DumpFormatViewer, nil
+	case DumpFormatJSONL:
+		return DumpFormatJSONL, nil
+	default:
+		return "", fmt.Errorf("unknown dump format %q (want %q or %q)", s, DumpFormatViewer, DumpFormatJSONL)
+	}
+}
+
 // DumpSystem records game history
 type DumpSystem struct {
 	enabled  bool
+	format   DumpFormat
 	filePath string
 	file     *os.File
 	writer   *bufio.Writer
-	mapData  []string
+	// mapData はダンプの先頭に書き出す初期盤面
+	mapData *chasermap.Map
+	// mapLines は viewer 形式のダンプに書き出すマップの行（"D " などのプレフィックスを除いたもの）
+	mapLines []string
 }
 
 // NewDumpSystem creates a new dump system
 func NewDumpSystem(filePath string, mapPath string, enabled bool) (*DumpSystem, error) {
+	return NewDumpSystemWithFormat(filePath, mapPath, enabled, DumpFormatViewer)
+}
+
+// NewDumpSystemWithFormat は形式を指定してダンプシステムを作成する
+func NewDumpSystemWithFormat(filePath string, mapPath string, enabled bool, format DumpFormat) (*DumpSystem, error) {
+	format, err := ParseDumpFormat(string(format))
+	if err != nil {
+		return nil, err
+	}
 	if !enabled {
-		return &DumpSystem{enabled: false}, nil
+		return &DumpSystem{enabled: false, format: format}, nil
 	}
 
+	d := &DumpSystem{enabled: true, format: format, filePath: filePath}
 	// マップデータを読み込む
-	mapData, err := readMapData(mapPath)
-	if err != nil {
+	var mapFormat chasermap.Format
+	if d.mapData, mapFormat, err = chasermap.LoadFormat(mapPath); err != nil {
 		return nil, fmt.Errorf("failed to read map data: %w", err)
 	}
+	// FormatSpace のマップファイルは元の行をそのまま書き出し、それ以外の書式は FormatSpace に変換する
+	if mapFormat == chasermap.FormatSpace {
+		if d.mapLines, err = readMapData(mapPath); err != nil {
+			return nil, fmt.Errorf("failed to read map data: %w", err)
+		}
+	} else {
+		for _, line := range d.mapData.Lines() {
+			d.mapLines = append(d.mapLines, line[2:])
+		}
+	}
 
 	// ダンプファイルを開く
 	file, err := os.Create(filePath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create dump file: %w", err)
 	}
+	d.file = file
+	d.writer = bufio.NewWriter(file)
+	return d, nil
+}
 
-	return &DumpSystem{
-		enabled:  true,
-		filePath: filePath,
-		file:     file,
-		writer:   bufio.NewWriter(file),
-		mapData:  mapData,
-	}, nil
+// Format はダンプの形式を返す
+func (d *DumpSystem) Format() DumpFormat {
+	return d.format
 }
 
-// readMapData reads map data from file
+// readMapData はマップファイルの各行から "D " などのプレフィックスを除いて返す
 func readMapData(mapPath string) ([]string, error) {
 	file, err := os.Open(mapPath)
 	if err != nil {
@@ -87,6 +136,20 @@ func (d *DumpSystem) SetNames(hotName, coolName string) error {
 	if !d.enabled {
 		return nil
 	}
+	if d.format == DumpFormatJSONL {
+		return d.writeRecord(jsonlStart{
+			Type:     "start",
+			HotName:  hotName,
+			CoolName: coolName,
+			Map:      d.mapData.Name,
+			MaxTurns: d.mapData.Turns,
+			Width:    d.mapData.Width,
+			Height:   d.mapData.Height,
+			Cells:    d.mapData.Cells,
+			Hot:      d.mapData.Hot,
+			Cool:     d.mapData.Cool,
+		})
+	}
 
 	// プレイヤー名を書き込み
 	_, err := d.writer.WriteString(fmt.Sprintf("%s,%s\n", sanitizeDumpField(hotName), sanitizeDumpField(coolName)))
@@ -95,7 +158,7 @@ func (d *DumpSystem) SetNames(hotName, coolName string) error {
 	}
 
 	// マップデータを書き込み
-	for _, line := range d.mapData {
+	for _, line := range d.mapLines {
 		_, err := d.writer.WriteString(line + "\n")
 		if err != nil {
 			return err
@@ -112,8 +175,9 @@ func (d *DumpSystem) SetNames(hotName, coolName string) error {
 }
 
 // Action records the current game state
+// JSON Lines 形式では半ターンごとに HalfTurn で記録するため何もしない
 func (d *DumpSystem) Action(board *Board) error {
-	if !d.enabled {
+	if !d.enabled || d.format == DumpFormatJSONL {
 		return nil
 	}
 
@@ -155,6 +219,13 @@ func (d *DumpSystem) Result(winner *Character, loser *Character, reason string)
 	if !d.enabled {
 		return nil
 	}
+	if d.format == DumpFormatJSONL {
+		rec := jsonlResult{Type: "result", Draw: winner == nil, Reason: reason}
+		if winner != nil {
+			rec.Winner = winner.Name
+		}
+		return d.writeRecord(rec)
+	}
 
 	// ゲーム終了マーカー
 	_, err := d.writer.WriteString("gameend\n")
@@ -177,6 +248,67 @@ func (d *DumpSystem) Result(winner *Character, loser *Character, reason string)
 	return d.writer.Flush()
 }
 
+// Alive は対戦終了時の各プレイヤーの生存を記録する（Result の後に呼ぶ）
+// CHaserViewer 形式では勝敗の行の次に "alive,<hot>,<cool>" の行を加える。
+// JSON Lines 形式では各 action レコードに生存が含まれるため何も書かない
+func (d *DumpSystem) Alive(hot, cool bool) error {
+	if !d.enabled || d.format == DumpFormatJSONL {
+		return nil
+	}
+	if _, err := fmt.Fprintf(d.writer, "alive,%t,%t\n", hot, cool); err != nil {
+		return err
+	}
+	return d.writer.Flush()
+}
+
+// HalfTurn は1プレイヤー分の行動を記録する（JSON Lines 形式のみ）
+// ゲームが途中で終了した場合の最後の半ターンもここで記録される
+func (d *DumpSystem) HalfTurn(rec *ActionRecord) error {
+	if !d.enabled || d.format != DumpFormatJSONL {
+		return nil
+	}
+	return d.writeRecord(jsonlAction{Type: "action", ActionRecord: rec})
+}
+
+// JSON Lines 形式のレコード。先頭に "start"、半ターンごとに "action"、
+// 終了時に "result" が1行ずつ並ぶ
+type jsonlStart struct {
+	Type     string       `json:"type"`
+	HotName  string       `json:"hotName"`
+	CoolName string       `json:"coolName"`
+	Map      string       `json:"map"`
+	MaxTurns int          `json:"maxTurns"`
+	Width    int          `json:"width"`
+	Height   int          `json:"height"`
+	Cells    [][]CellType `json:"cells"` // cells[y][x]
+	Hot      Position     `json:"hot"`
+	Cool     Position     `json:"cool"`
+}
+
+type jsonlAction struct {
+	Type string `json:"type"`
+	*ActionRecord
+}
+
+type jsonlResult struct {
+	Type   string `json:"type"`
+	Draw   bool   `json:"draw"`
+	Winner string `json:"winner,omitempty"`
+	Reason string `json:"reason"`
+}
+
+// writeRecord は v を JSON 1行として書き込む
+func (d *DumpSystem) writeRecord(v any) error {
+	line, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if _, err := d.writer.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	return d.writer.Flush()
+}
+
 // Close closes the dump file
 func (d *DumpSystem) Close() error {
 	if !d.enabled || d.file == nil {
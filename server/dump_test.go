@@ -0,0 +1,241 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// playScript は net.Pipe の client 側でコマンド列を順に送るテスト用クライアント
+// "#" を受信するか接続が閉じられると終了する
+func playScript(conn net.Conn, cmds []string) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	readLine := func() (string, bool) {
+		line, err := r.ReadString('\n')
+		return strings.TrimSpace(line), err == nil
+	}
+	for _, cmd := range cmds {
+		if msg, ok := readLine(); !ok || msg != "Ready" {
+			return
+		}
+		conn.Write([]byte("gr\n"))
+		if _, ok := readLine(); !ok {
+			return
+		}
+		conn.Write([]byte(cmd + "\n"))
+		if _, ok := readLine(); !ok {
+			return
+		}
+		conn.Write([]byte("#\n"))
+	}
+	for {
+		if _, ok := readLine(); !ok {
+			return
+		}
+	}
+}
+
+// runScriptedGame は format 形式のダンプを有効にして runGame を実行し、ダンプの内容を返す
+func runScriptedGame(t *testing.T, format DumpFormat, hotCmds, coolCmds []string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "game.dump")
+	s, err := NewServer(ServerConfig{
+		MapPath:    "testdata/test.map",
+		DumpPath:   path,
+		EnableDump: true,
+		DumpFormat: format,
+	})
+	if err != nil {
+		t.Fatalf("NewServer: %v", err)
+	}
+
+	hotClient, hotServer := net.Pipe()
+	coolClient, coolServer := net.Pipe()
+	go playScript(hotClient, hotCmds)
+	go playScript(coolClient, coolCmds)
+	s.HotConn, s.CoolConn = NewConnection(hotServer), NewConnection(coolServer)
+	s.Board.Hot.Name, s.Board.Cool.Name = "hot-bot", "cool-bot"
+
+	if err := s.DumpSystem.SetNames(s.Board.Hot.Name, s.Board.Cool.Name); err != nil {
+		t.Fatalf("SetNames: %v", err)
+	}
+	if err := s.runGame(context.Background()); err != nil {
+		t.Fatalf("runGame: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	return string(data)
+}
+
+// Turn 0: Hot が右へ移動、Cool が上にブロックを置く
+// Turn 1: Cool が上を look、Hot が上の壁に移動して死亡（ターン途中で終了）
+var (
+	scriptHot  = []string{"wr", "wu"}
+	scriptCool = []string{"pu", "lu"}
+)
+
+func TestParseDumpFormat(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    DumpFormat
+		wantErr bool
+	}{
+		{"", DumpFormatViewer, false},
+		{"viewer", DumpFormatViewer, false},
+		{"jsonl", DumpFormatJSONL, false},
+		{"json", "", true},
+	}
+	for _, tt := range tests {
+		got, err := ParseDumpFormat(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseDumpFormat(%q) = %q, %v; want %q (error %v)", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestDumpJSONL(t *testing.T) {
+	out := runScriptedGame(t, DumpFormatJSONL, scriptHot, scriptCool)
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 6 {
+		t.Fatalf("got %d records, want 6 (start, 4 actions, result):\n%s", len(lines), out)
+	}
+
+	var start jsonlStart
+	if err := json.Unmarshal([]byte(lines[0]), &start); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	if start.Type != "start" || start.HotName != "hot-bot" || start.Map != "test" || start.Width != 5 || start.Cells[2][2] != Item {
+		t.Errorf("start = %+v", start)
+	}
+
+	var recs []ActionRecord
+	for _, line := range lines[1:5] {
+		var rec struct {
+			Type string `json:"type"`
+			ActionRecord
+		}
+		if err := json.Unmarshal([]byte(line), &rec); err != nil {
+			t.Fatalf("action %s: %v", line, err)
+		}
+		if rec.Type != "action" {
+			t.Errorf("type = %q, want action", rec.Type)
+		}
+		recs = append(recs, rec.ActionRecord)
+	}
+
+	want := []struct {
+		turn   int
+		step   TurnStep
+		player string
+		cmd    string
+		action string
+		dir    Direction
+	}{
+		{0, TurnStepFirst, "hot", "wr", "wk", Right},
+		{0, TurnStepSecond, "cool", "pu", "pt", Up},
+		{1, TurnStepFirst, "cool", "lu", "lk", Up},
+		{1, TurnStepSecond, "hot", "wu", "wk", Up},
+	}
+	for i, w := range want {
+		r := recs[i]
+		if r.Turn != w.turn || r.Step != w.step || r.Player != w.player || r.Command != w.cmd || r.Action != w.action || r.Direction != w.dir {
+			t.Errorf("recs[%d] = turn %d %v %s %q %s %v, want turn %d %v %s %q %s %v", i,
+				r.Turn, r.Step, r.Player, r.Command, r.Action, r.Direction,
+				w.turn, w.step, w.player, w.cmd, w.action, w.dir)
+		}
+	}
+
+	if recs[0].Ready[0] != 1 || recs[0].Ready[2] != int(Wall) {
+		t.Errorf("recs[0].Ready = %v, want game continuing with wall above", recs[0].Ready)
+	}
+	if got := recs[0].Hot; got.X != 2 || got.Y != 1 || !got.Alive {
+		t.Errorf("recs[0].Hot = %+v, want alive at (2,1)", got)
+	}
+	if len(recs[1].Changes) != 1 || recs[1].Changes[0] != (CellChange{X: 3, Y: 2, From: Empty, To: Wall}) {
+		t.Errorf("recs[1].Changes = %+v, want put at (3,2)", recs[1].Changes)
+	}
+	if len(recs[2].Changes) != 0 {
+		t.Errorf("look changed the board: %+v", recs[2].Changes)
+	}
+
+	// ゲームが途中で終わった最後の半ターンも記録される
+	last := recs[3]
+	if !last.GameOver || last.Hot.Alive {
+		t.Errorf("last = gameOver %v hot alive %v, want game over with hot dead", last.GameOver, last.Hot.Alive)
+	}
+
+	var result jsonlResult
+	if err := json.Unmarshal([]byte(lines[5]), &result); err != nil {
+		t.Fatalf("result: %v", err)
+	}
+	if result.Type != "result" || result.Winner != "cool-bot" || result.Reason != "hot died" {
+		t.Errorf("result = %+v, want cool-bot wins by hot died", result)
+	}
+}
+
+func TestDumpViewerIgnoresHalfTurns(t *testing.T) {
+	out := runScriptedGame(t, DumpFormatViewer, scriptHot, scriptCool)
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+
+	// 名前 + マップ（N/T/S 3行・D 17行・H/C 2行）+ 初期スコア + 1ターン分（盤面5行・位置2行・スコア）+ 結果3行
+	if want := 1 + 3 + 17 + 2 + 1 + 8 + 3; len(lines) != want {
+		t.Fatalf("got %d lines, want %d:\n%s", len(lines), want, out)
+	}
+	if lines[len(lines)-2] != "cool-bot,win,hot died" {
+		t.Errorf("result line = %q", lines[len(lines)-2])
+	}
+	if lines[len(lines)-1] != "alive,false,true" {
+		t.Errorf("alive line = %q", lines[len(lines)-1])
+	}
+}
+
+func TestDumpViewerMapLines(t *testing.T) {
+	header := func(t *testing.T, mapPath string) []string {
+		t.Helper()
+		path := filepath.Join(t.TempDir(), "game.dump")
+		d, err := NewDumpSystem(path, mapPath, true)
+		if err != nil {
+			t.Fatalf("NewDumpSystem: %v", err)
+		}
+		if err := d.SetNames("hot-bot", "cool-bot"); err != nil {
+			t.Fatalf("SetNames: %v", err)
+		}
+		if err := d.Close(); err != nil {
+			t.Fatalf("Close: %v", err)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("ReadFile: %v", err)
+		}
+		lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+		return lines[1 : len(lines)-1] // 名前と初期スコアの行を除く
+	}
+
+	// FormatSpace のマップファイルは元の行（省略した D 行は省略したまま）を書き出す
+	src, err := os.ReadFile("testdata/test.map")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var want []string
+	for _, line := range strings.Split(strings.TrimSpace(string(src)), "\n") {
+		want = append(want, line[2:])
+	}
+	if got := header(t, "testdata/test.map"); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("map lines =\n%s\nwant the original lines\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	// それ以外の書式は FormatSpace に変換し、全マスの D 行を書き出す
+	got := header(t, "testdata/test_colon.map")
+	if len(got) != 3+7*5+2 || got[2] != "5,7" || got[len(got)-2] != "3,1" || got[len(got)-1] != "1,4" {
+		t.Errorf("converted map lines =\n%s", strings.Join(got, "\n"))
+	}
+}
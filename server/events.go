@@ -1,5 +1,7 @@
 package server
 
+import "fmt"
+
 // SnapshotKind はスナップショットの発火理由
 type SnapshotKind int
 
@@ -12,6 +14,30 @@ const (
 	KindError                         // 通信エラー等
 )
 
+func (k SnapshotKind) String() string {
+	switch k {
+	case KindInitial:
+		return "initial"
+	case KindConnected:
+		return "connected"
+	case KindActionEnd:
+		return "action_end"
+	case KindTurnEnd:
+		return "turn_end"
+	case KindGameOver:
+		return "game_over"
+	case KindError:
+		return "error"
+	default:
+		return "unknown"
+	}
+}
+
+// MarshalText は JSON 出力で種別を文字列として表すために実装する
+func (k SnapshotKind) MarshalText() ([]byte, error) {
+	return []byte(k.String()), nil
+}
+
 // TurnStep は半ターン単位の先攻/後攻を示す
 // KindActionEnd と組み合わせて「誰のアクション後か」を表す
 type TurnStep int
@@ -21,6 +47,35 @@ const (
 	TurnStepSecond                 // 後攻アクション後
 )
 
+func (s TurnStep) String() string {
+	switch s {
+	case TurnStepFirst:
+		return "first"
+	case TurnStepSecond:
+		return "second"
+	default:
+		return "unknown"
+	}
+}
+
+// MarshalText は JSON 出力で先攻/後攻を文字列として表すために実装する
+func (s TurnStep) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText は MarshalText の逆変換（JSON Lines ダンプの読み込み用）
+func (s *TurnStep) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "first":
+		*s = TurnStepFirst
+	case "second":
+		*s = TurnStepSecond
+	default:
+		return fmt.Errorf("invalid turn step: %q", text)
+	}
+	return nil
+}
+
 // SnapshotPublicPhase は公開ライフサイクルのフェーズ
 // Board.GameOver とは別管理（ルール状態は Board が持つ）
 type SnapshotPublicPhase int
@@ -32,36 +87,74 @@ const (
 	PhaseError                               // エラー
 )
 
+func (p SnapshotPublicPhase) String() string {
+	switch p {
+	case PhaseWaiting:
+		return "waiting"
+	case PhaseRunning:
+		return "running"
+	case PhaseGameOver:
+		return "game_over"
+	case PhaseError:
+		return "error"
+	default:
+		return "unknown"
+	}
+}
+
+// MarshalText は JSON 出力でフェーズを文字列として表すために実装する
+func (p SnapshotPublicPhase) MarshalText() ([]byte, error) {
+	return []byte(p.String()), nil
+}
+
 // BoardSnapshot はスレッド間で共有するための immutable な盤面スナップショット
 // Board への直接参照は一切持たない（全フィールドが値コピー）
 type BoardSnapshot struct {
-	Kind     SnapshotKind
-	Step     TurnStep            // KindActionEnd 時のみ意味を持つ
-	Phase    SnapshotPublicPhase
-	Revision uint64              // 単調増加、取りこぼし検知用
+	Kind     SnapshotKind        `json:"kind"`
+	Step     TurnStep            `json:"step"` // KindActionEnd 時のみ意味を持つ
+	Phase    SnapshotPublicPhase `json:"phase"`
+	Revision uint64              `json:"revision"` // 単調増加、取りこぼし検知用
 
 	// 盤面（1次元平坦化 deep copy）
 	// MapData[y][x] = MapFlat[y*Width+x]
-	MapFlat  []int
-	Width    int
-	Height   int
-	MaxTurns int
-	Turn     int
-
-	WinnerName string
-	Reason     string
-
-	HotName  string
-	HotX     int
-	HotY     int
-	HotItems int
-	HotAlive bool
-
-	CoolName  string
-	CoolX     int
-	CoolY     int
-	CoolItems int
-	CoolAlive bool
+	MapFlat  []int `json:"mapFlat"`
+	Width    int   `json:"width"`
+	Height   int   `json:"height"`
+	MaxTurns int   `json:"maxTurns"`
+	Turn     int   `json:"turn"`
+
+	WinnerName string `json:"winnerName"`
+	Reason     string `json:"reason"`
+
+	HotName  string `json:"hotName"`
+	HotX     int    `json:"hotX"`
+	HotY     int    `json:"hotY"`
+	HotItems int    `json:"hotItems"`
+	HotAlive bool   `json:"hotAlive"`
+
+	CoolName  string `json:"coolName"`
+	CoolX     int    `json:"coolX"`
+	CoolY     int    `json:"coolY"`
+	CoolItems int    `json:"coolItems"`
+	CoolAlive bool   `json:"coolAlive"`
+
+	// HotAction, CoolAction は各プレイヤーが最後に行った行動（まだ行動していなければ nil）
+	// 指す先は変更されないため、スナップショット間で共有してよい
+	HotAction  *PlayerAction `json:"hotAction,omitempty"`
+	CoolAction *PlayerAction `json:"coolAction,omitempty"`
+
+	// HotView, CoolView は各プレイヤーが応答で知らされた盤面（記録がなければ nil）
+	HotView  *PlayerView `json:"hotView,omitempty"`
+	CoolView *PlayerView `json:"coolView,omitempty"`
+}
+
+// Clone は s の deep copy を返す（盤面・行動・プレイヤーの視点を共有しない）
+func (s BoardSnapshot) Clone() BoardSnapshot {
+	c := s
+	c.MapFlat = append([]int(nil), s.MapFlat...)
+	c.HotAction, c.CoolAction = s.HotAction.clone(), s.CoolAction.clone()
+	c.HotView, c.CoolView = s.HotView.Clone(), s.CoolView.Clone()
+	return c
 }
 
 // SnapshotFromBoard は Board から BoardSnapshot を生成する
@@ -74,26 +167,26 @@ func SnapshotFromBoard(b *Board, kind SnapshotKind, step TurnStep, phase Snapsho
 		}
 	}
 	return BoardSnapshot{
-		Kind:     kind,
-		Step:     step,
-		Phase:    phase,
-		Revision: rev,
-		MapFlat:  flat,
-		Width:    b.Width,
-		Height:   b.Height,
-		MaxTurns: b.MaxTurns,
-		Turn:     b.Turn,
+		Kind:       kind,
+		Step:       step,
+		Phase:      phase,
+		Revision:   rev,
+		MapFlat:    flat,
+		Width:      b.Width,
+		Height:     b.Height,
+		MaxTurns:   b.MaxTurns,
+		Turn:       b.Turn,
 		WinnerName: winner,
 		Reason:     reason,
-		HotName:  b.Hot.Name,
-		HotX:     b.Hot.Position.X,
-		HotY:     b.Hot.Position.Y,
-		HotItems: b.Hot.Items,
-		HotAlive: b.Hot.IsAlive,
-		CoolName:  b.Cool.Name,
-		CoolX:     b.Cool.Position.X,
-		CoolY:     b.Cool.Position.Y,
-		CoolItems: b.Cool.Items,
-		CoolAlive: b.Cool.IsAlive,
+		HotName:    b.Hot.Name,
+		HotX:       b.Hot.Position.X,
+		HotY:       b.Hot.Position.Y,
+		HotItems:   b.Hot.Items,
+		HotAlive:   b.Hot.IsAlive,
+		CoolName:   b.Cool.Name,
+		CoolX:      b.Cool.Position.X,
+		CoolY:      b.Cool.Position.Y,
+		CoolItems:  b.Cool.Items,
+		CoolAlive:  b.Cool.IsAlive,
 	}
 }
@@ -1,6 +1,7 @@
 package server
 
 import (
+	"slices"
 	"testing"
 )
 
@@ -128,3 +129,35 @@ func TestSnapshotPublicPhaseConstants(t *testing.T) {
 		}
 	}
 }
+
+func TestNewPlayerAction(t *testing.T) {
+	from := Position{X: 3, Y: 4}
+	tests := []struct {
+		action string
+		dir    Direction
+		cells  []Position
+	}{
+		{"wk", Right, []Position{{X: 4, Y: 4}}},
+		{"lk", Up, []Position{{X: 3, Y: 2}}},
+		{"pt", Left, []Position{{X: 2, Y: 4}}},
+		{"sc", Down, []Position{{X: 3, Y: 5}, {X: 3, Y: 6}, {X: 3, Y: 7}, {X: 3, Y: 8}, {X: 3, Y: 9}, {X: 3, Y: 10}, {X: 3, Y: 11}, {X: 3, Y: 12}, {X: 3, Y: 13}}},
+	}
+	for _, tt := range tests {
+		rec := &ActionRecord{Turn: 7, Action: tt.action, Direction: tt.dir, Response: [10]int{1, 2}}
+		a := NewPlayerAction(rec, from)
+		if a == nil {
+			t.Fatalf("%s: got nil", tt.action)
+		}
+		if a.Turn != 7 || a.From != from || a.Direction != tt.dir || a.Response != rec.Response {
+			t.Errorf("%s: got %+v", tt.action, a)
+		}
+		if !slices.Equal(a.Cells, tt.cells) {
+			t.Errorf("%s: cells = %v, want %v", tt.action, a.Cells, tt.cells)
+		}
+	}
+
+	// 行動を受信できなかった記録には行動がない
+	if a := NewPlayerAction(&ActionRecord{Error: "timeout"}, from); a != nil {
+		t.Errorf("got %+v for a record without action, want nil", a)
+	}
+}
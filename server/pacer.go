@@ -0,0 +1,136 @@
+package server
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Pacer は対戦の進行速度を制御する
+// runGame は各半ターンの processTurn の前に Wait を呼び、戻るまで次の行動を始めない
+// Wait は ctx が終了した場合 ctx.Err() を返す
+type Pacer interface {
+	Wait(ctx context.Context) error
+}
+
+// Pace は一時停止・コマ送り・半ターンごとの待ち時間を持つ Pacer
+// GUI のキー操作など別 goroutine から操作してよい
+type Pace struct {
+	mu     sync.Mutex
+	delay  time.Duration
+	paused bool
+	steps  int           // 一時停止中に進めてよい半ターン数
+	last   time.Time     // 前回 Wait から戻った時刻
+	wake   chan struct{} // 状態が変わると close して待機中の Wait を起こす
+}
+
+// compile-time check
+var _ Pacer = (*Pace)(nil)
+
+// NewPace は半ターンごとに delay 待つ Pace を作成する
+func NewPace(delay time.Duration) *Pace {
+	return &Pace{delay: max(delay, 0), wake: make(chan struct{})}
+}
+
+// notify は待機中の Wait を起こす（p.mu を保持して呼ぶ）
+func (p *Pace) notify() {
+	close(p.wake)
+	p.wake = make(chan struct{})
+}
+
+// Delay は半ターンごとの待ち時間を返す
+func (p *Pace) Delay() time.Duration {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.delay
+}
+
+// SetDelay は半ターンごとの待ち時間を設定する（負の値は 0 とする）
+func (p *Pace) SetDelay(d time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.delay = max(d, 0)
+	p.notify()
+}
+
+// Paused は一時停止中かを返す
+func (p *Pace) Paused() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.paused
+}
+
+// SetPaused は一時停止・再開する（再開時は未消化のコマ送りを捨てる）
+func (p *Pace) SetPaused(paused bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.paused = paused
+	p.steps = 0
+	p.notify()
+}
+
+// TogglePause は一時停止と再開を切り替える
+func (p *Pace) TogglePause() {
+	p.SetPaused(!p.Paused())
+}
+
+// Step は一時停止中に半ターンを1つだけ進める（一時停止中でなければ一時停止する）
+func (p *Pace) Step() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.paused {
+		p.steps++
+	}
+	p.paused = true
+	p.notify()
+}
+
+// Wait は次の半ターンを始めてよくなるまで待つ
+// 一時停止中はコマ送りか再開まで、それ以外は前回戻ってから delay 経つまで待つ
+func (p *Pace) Wait(ctx context.Context) error {
+	for {
+		p.mu.Lock()
+		ready, wait := p.ready()
+		if ready {
+			p.last = time.Now()
+			p.mu.Unlock()
+			return nil
+		}
+		wake := p.wake
+		p.mu.Unlock()
+
+		// 一時停止中（wait == 0）は状態が変わるまで待つ
+		var timer *time.Timer
+		var timeout <-chan time.Time
+		if wait > 0 {
+			timer = time.NewTimer(wait)
+			timeout = timer.C
+		}
+		select {
+		case <-ctx.Done():
+		case <-wake:
+		case <-timeout:
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+	}
+}
+
+// ready は次の半ターンを始めてよいか、よくなければ待つべき時間を返す（p.mu を保持して呼ぶ）
+func (p *Pace) ready() (bool, time.Duration) {
+	if p.paused {
+		if p.steps > 0 {
+			p.steps--
+			return true, 0
+		}
+		return false, 0
+	}
+	if wait := p.delay - time.Since(p.last); wait > 0 {
+		return false, wait
+	}
+	return true, 0
+}
@@ -0,0 +1,94 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// waitAsync は p.Wait を別 goroutine で呼び、結果を返す channel を返す
+func waitAsync(ctx context.Context, p *Pace) <-chan error {
+	done := make(chan error, 1)
+	go func() { done <- p.Wait(ctx) }()
+	return done
+}
+
+func TestPaceDelay(t *testing.T) {
+	p := NewPace(50 * time.Millisecond)
+	ctx := context.Background()
+
+	// 最初の Wait は待たない
+	start := time.Now()
+	if err := p.Wait(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Wait(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if d := time.Since(start); d < 50*time.Millisecond {
+		t.Errorf("second Wait returned after %v, want >= 50ms", d)
+	}
+
+	// 待ち時間を縮めると待機中の Wait もすぐ戻る
+	p.SetDelay(time.Hour)
+	done := waitAsync(ctx, p)
+	p.SetDelay(0)
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Wait did not return after SetDelay(0)")
+	}
+}
+
+func TestPacePauseAndStep(t *testing.T) {
+	p := NewPace(0)
+	ctx := context.Background()
+
+	// 一時停止していないときの Step は一時停止だけ行う
+	p.Step()
+	if !p.Paused() {
+		t.Fatal("Step should pause")
+	}
+	done := waitAsync(ctx, p)
+	select {
+	case <-done:
+		t.Fatal("Wait returned while paused")
+	case <-time.After(30 * time.Millisecond):
+	}
+
+	// 一時停止中の Step で1回だけ進む
+	p.Step()
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	done = waitAsync(ctx, p)
+	select {
+	case <-done:
+		t.Fatal("Wait returned twice for one step")
+	case <-time.After(30 * time.Millisecond):
+	}
+
+	// 再開すると進む
+	p.TogglePause()
+	if p.Paused() {
+		t.Fatal("TogglePause should resume")
+	}
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPaceCancel(t *testing.T) {
+	p := NewPace(0)
+	p.SetPaused(true)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := waitAsync(ctx, p)
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("Wait = %v, want context.Canceled", err)
+	}
+}
@@ -6,7 +6,9 @@ import (
 	"fmt"
 	"log"
 	"net"
+	"net/http"
 	"sync"
+	"time"
 )
 
 // Server represents the CHaser game server
@@ -18,6 +20,23 @@ type Server struct {
 	CoolConn   *Connection
 	snapshotCh chan BoardSnapshot
 	revision   uint64
+	// hotAction, coolAction は各プレイヤーの最後の行動（runGame の goroutine のみが更新する）
+	hotAction  *PlayerAction
+	coolAction *PlayerAction
+	// hotView, coolView は各プレイヤーが応答で知らされた盤面（runGame の goroutine のみが更新する）
+	hotView  *PlayerView
+	coolView *PlayerView
+
+	// mu は制御API（別goroutine）から参照される状態を保護する
+	mu            sync.Mutex
+	latest        BoardSnapshot
+	history       []BoardSnapshot
+	hotConnected  bool
+	coolConnected bool
+	cancel        context.CancelCauseFunc
+	nextMap       string
+	controller    *Controller // SetServer で登録された場合、次のマップの選択はこちらが保持する
+	subscribers   map[chan BoardSnapshot]struct{}
 }
 
 // ServerConfig holds server configuration
@@ -27,6 +46,8 @@ type ServerConfig struct {
 	CoolPort   int
 	DumpPath   string
 	EnableDump bool
+	// DumpFormat はダンプの形式。空文字の場合は DumpFormatViewer
+	DumpFormat DumpFormat
 	// BindAddr はサーバーがリッスンするアドレス。
 	// 空文字の場合は "127.0.0.1"（ローカルのみ）にデフォルトする。
 	// 外部公開が必要な場合は "0.0.0.0" を明示指定する。
@@ -35,6 +56,29 @@ type ServerConfig struct {
 	// Must be nil (disables snapshots) or a buffered channel (cap >= 1).
 	// NewServer returns an error if an unbuffered channel is supplied.
 	SnapshotCh chan BoardSnapshot
+	// ControlAddr は制御用HTTP APIのリッスンアドレス（例: "127.0.0.1:8080"）。
+	// 空文字の場合は無効。ホスト部を省略した場合（":8080"）は BindAddr と同様に
+	// "127.0.0.1" にデフォルトする。
+	ControlAddr string
+	// ViewerAddr はブラウザ向け観戦ページのリッスンアドレス。
+	// 空文字の場合は無効。デフォルトホストの扱いは ControlAddr と同じ。
+	ViewerAddr string
+	// Pacer は各半ターンの前に待機して対戦の進行速度を制御する（一時停止・コマ送りなど）。
+	// nil の場合は待たずに進める。
+	Pacer Pacer
+	// Recorder は発行した全てのスナップショットを発行順に、取りこぼしなく受け取る（GUI の巻き戻し用など）。
+	// SnapshotCh と違い最新だけに上書きされない。nil の場合は使わない。
+	Recorder SnapshotRecorder
+	// KeepHistory が true の場合、発行した全てのスナップショットを History 用に保持する
+	// （対戦後の GIF 書き出しなど）。false の場合は保持せず、History は nil を返す。
+	KeepHistory bool
+}
+
+// SnapshotRecorder はスナップショットを1件ずつ受け取る
+// Record は runGame の goroutine から同期的に呼ばれるため、すぐに戻ること。
+// 受け取ったスナップショットは他と共有されているので変更しないこと
+type SnapshotRecorder interface {
+	Record(snap BoardSnapshot)
 }
 
 // NewServer creates a new CHaser server
@@ -44,13 +88,16 @@ func NewServer(config ServerConfig) (*Server, error) {
 	if config.SnapshotCh != nil && cap(config.SnapshotCh) == 0 {
 		return nil, fmt.Errorf("ServerConfig.SnapshotCh must be a buffered channel (cap >= 1); got unbuffered")
 	}
+	if config.ViewerAddr != "" && config.ViewerAddr == config.ControlAddr {
+		return nil, fmt.Errorf("ServerConfig.ViewerAddr and ControlAddr must differ; got %q for both", config.ViewerAddr)
+	}
 
 	board, err := NewBoard(config.MapPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load board: %w", err)
 	}
 
-	dumpSystem, err := NewDumpSystem(config.DumpPath, config.MapPath, config.EnableDump)
+	dumpSystem, err := NewDumpSystemWithFormat(config.DumpPath, config.MapPath, config.EnableDump, config.DumpFormat)
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize dump system: %w", err)
 	}
@@ -60,6 +107,8 @@ func NewServer(config ServerConfig) (*Server, error) {
 		Board:      board,
 		DumpSystem: dumpSystem,
 		snapshotCh: config.SnapshotCh,
+		hotView:    NewPlayerView(board.Width, board.Height),
+		coolView:   NewPlayerView(board.Width, board.Height),
 	}
 
 	s.publishSnapshot(KindInitial, TurnStepFirst, PhaseWaiting, "", "")
@@ -72,8 +121,36 @@ func (s *Server) Start(ctx context.Context) error {
 	log.Printf("Hot port: %d, Cool port: %d", s.config.HotPort, s.config.CoolPort)
 	log.Printf("Max turns: %d", s.Board.MaxTurns)
 
-	ctx, cancel := context.WithCancel(ctx)
-	defer cancel()
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	s.mu.Lock()
+	s.cancel = cancel
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		s.cancel = nil
+		s.mu.Unlock()
+	}()
+
+	if s.config.ControlAddr != "" {
+		addr, stop, err := serveHTTP(s.config.ControlAddr, s.ControlHandler())
+		if err != nil {
+			return fmt.Errorf("failed to start control API: %w", err)
+		}
+		defer stop()
+		log.Printf("Control API: http://%s", addr)
+	}
+	if s.config.ViewerAddr != "" {
+		addr, stop, err := serveHTTP(s.config.ViewerAddr, s.ViewerHandler())
+		if err != nil {
+			return fmt.Errorf("failed to start web viewer: %w", err)
+		}
+		defer stop()
+		// stop より先に実行され、最後のスナップショットを配信してから SSE を終える
+		defer s.closeSubscribers()
+		log.Printf("Web viewer: http://%s", addr)
+	}
 
 	var wg sync.WaitGroup
 	errChan := make(chan error, 2)
@@ -90,6 +167,7 @@ func (s *Server) Start(ctx context.Context) error {
 		}
 		s.HotConn = conn
 		s.Board.Hot.Name = name
+		s.setConnected(&s.hotConnected)
 		log.Printf("Hot player connected: %s", name)
 	}()
 
@@ -102,6 +180,7 @@ func (s *Server) Start(ctx context.Context) error {
 		}
 		s.CoolConn = conn
 		s.Board.Cool.Name = name
+		s.setConnected(&s.coolConnected)
 		log.Printf("Cool player connected: %s", name)
 	}()
 
@@ -123,9 +202,10 @@ func (s *Server) Start(ctx context.Context) error {
 
 	select {
 	case err := <-errChan:
-		cancel() // stop the other goroutine
+		cancel(nil) // stop the other goroutine
 		<-doneChan
 		closeConnections()
+		err = s.abortCause(ctx, err)
 		s.publishSnapshot(KindError, TurnStepFirst, PhaseError, "", err.Error())
 		return err
 	case <-doneChan:
@@ -134,6 +214,7 @@ func (s *Server) Start(ctx context.Context) error {
 		select {
 		case err := <-errChan:
 			closeConnections()
+			err = s.abortCause(ctx, err)
 			s.publishSnapshot(KindError, TurnStepFirst, PhaseError, "", err.Error())
 			return err
 		default:
@@ -150,6 +231,43 @@ func (s *Server) Start(ctx context.Context) error {
 	return s.runGame(ctx)
 }
 
+// defaultHost はホスト部が省略されたアドレスに "127.0.0.1" を補う
+func defaultHost(addr string) string {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil || host != "" {
+		return addr
+	}
+	return net.JoinHostPort("127.0.0.1", port)
+}
+
+// serveHTTP は addr で handler を提供し、実際に待ち受けるアドレスと停止用の関数を返す
+func serveHTTP(addr string, handler http.Handler) (bound string, stop func(), err error) {
+	listener, err := net.Listen("tcp", defaultHost(addr))
+	if err != nil {
+		return "", nil, err
+	}
+	srv := &http.Server{Handler: handler}
+	go func() {
+		if err := srv.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("Warning: HTTP server on %s stopped: %v", addr, err)
+		}
+	}()
+	return listener.Addr().String(), func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		if err := srv.Shutdown(ctx); err != nil {
+			_ = srv.Close()
+		}
+	}, nil
+}
+
+// setConnected は接続状態フラグを制御API向けに更新する
+func (s *Server) setConnected(flag *bool) {
+	s.mu.Lock()
+	*flag = true
+	s.mu.Unlock()
+}
+
 // acceptConnectionWithContext accepts a connection on the specified port with context support
 func (s *Server) acceptConnectionWithContext(ctx context.Context, port int, playerType string) (*Connection, string, error) {
 	bindAddr := s.config.BindAddr
@@ -240,9 +358,24 @@ func (s *Server) runGame(ctx context.Context) error {
 	for s.Board.Turn < s.Board.MaxTurns && !s.Board.GameOver {
 		actors := s.actorsForTurn(s.Board.Turn)
 		for _, a := range actors {
-			err := s.processTurn(ctx, a.conn, a.self, a.opponent)
+			if s.config.Pacer != nil {
+				if err := s.config.Pacer.Wait(ctx); err != nil {
+					if cause := context.Cause(ctx); errors.Is(cause, ErrAborted) {
+						return s.abortGame(cause)
+					}
+					return err
+				}
+			}
+
+			rec := s.newActionRecord(a)
+			before := s.Board.cloneCells()
+			from := a.self.Position
+			err := s.processTurn(ctx, a.conn, a.self, a.opponent, rec)
 			if err != nil {
 				if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+					if cause := context.Cause(ctx); errors.Is(cause, ErrAborted) {
+						return s.abortGame(cause)
+					}
 					return err
 				}
 				log.Printf("%s turn error: %v", a.self.Name, err)
@@ -250,6 +383,16 @@ func (s *Server) runGame(ctx context.Context) error {
 				s.Board.GameOver = true
 			}
 
+			rec.finish(s.Board, before, err)
+			if a.self == s.Board.Hot {
+				s.hotAction = NewPlayerAction(rec, from)
+			} else {
+				s.coolAction = NewPlayerAction(rec, from)
+			}
+			if err := s.DumpSystem.HalfTurn(rec); err != nil {
+				log.Printf("Warning: failed to write half-turn to dump: %v", err)
+			}
+
 			// ActionEnd: GameOver経路を含め毎回発火
 			s.publishSnapshot(KindActionEnd, a.step, PhaseRunning, "", "")
 			if s.Board.GameOver {
@@ -269,8 +412,27 @@ func (s *Server) runGame(ctx context.Context) error {
 	return s.endGame(ctx)
 }
 
+// viewOf は char が知り得た盤面を返す
+func (s *Server) viewOf(char *Character) *PlayerView {
+	if char == s.Board.Hot {
+		return s.hotView
+	}
+	return s.coolView
+}
+
+// newActionRecord は a の半ターン分の記録を行動前の情報で初期化する
+func (s *Server) newActionRecord(a turnActor) *ActionRecord {
+	player := "cool"
+	if a.self == s.Board.Hot {
+		player = "hot"
+	}
+	return &ActionRecord{Turn: s.Board.Turn, Step: a.step, Player: player, Name: a.self.Name}
+}
+
 // processTurn processes one player's turn
-func (s *Server) processTurn(ctx context.Context, conn *Connection, char *Character, opponent *Character) error {
+// 送受信した内容と所要時間は rec に記録する（盤面差分は呼び出し側で埋める）
+func (s *Server) processTurn(ctx context.Context, conn *Connection, char *Character, opponent *Character, rec *ActionRecord) error {
+	start := time.Now()
 	if err := conn.SendContext(ctx, "Ready\n"); err != nil {
 		return fmt.Errorf("failed to send ready: %w", err)
 	}
@@ -278,6 +440,7 @@ func (s *Server) processTurn(ctx context.Context, conn *Connection, char *Charac
 	if err := conn.WaitForReadyContext(ctx); err != nil {
 		return fmt.Errorf("failed to receive ready: %w", err)
 	}
+	rec.ReadyWait = time.Since(start)
 
 	// Ready レスポンス（周辺9マス）生成・送信
 	var readyResponse [10]int
@@ -303,9 +466,13 @@ func (s *Server) processTurn(ctx context.Context, conn *Connection, char *Charac
 		}
 	}
 
+	rec.Ready = readyResponse
 	if err := conn.SendResponseContext(ctx, readyResponse); err != nil {
 		return fmt.Errorf("failed to send ready response: %w", err)
 	}
+	view := s.viewOf(char)
+	view.RevealAround(char.Position, readyResponse, s.Board.Turn)
+	start = time.Now()
 
 	if s.Board.GameOver {
 		return nil
@@ -316,11 +483,14 @@ func (s *Server) processTurn(ctx context.Context, conn *Connection, char *Charac
 	if err != nil {
 		return fmt.Errorf("failed to receive action: %w", err)
 	}
+	rec.ThinkTime = time.Since(start)
+	rec.Command = actionStr
 
 	action, direction, err := ParseAction(actionStr)
 	if err != nil {
 		return fmt.Errorf("failed to parse action: %w", err)
 	}
+	rec.Action, rec.Direction = action, direction
 
 	log.Printf("%s: %s %d (Turn %d)", char.Name, action, direction, s.Board.Turn)
 
@@ -340,15 +510,19 @@ func (s *Server) processTurn(ctx context.Context, conn *Connection, char *Charac
 		response = BuildPutResponse(char, opponent, s.Board)
 	}
 
+	rec.Response = response
 	if err := conn.SendResponseContext(ctx, response); err != nil {
 		return fmt.Errorf("failed to send response: %w", err)
 	}
+	view.RevealAction(action, char.Position, direction, response, s.Board.Turn)
+	start = time.Now()
 
 	// '#' 確認応答受信
 	ack, err := conn.ReceiveContext(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to receive acknowledgment: %w", err)
 	}
+	rec.AckWait = time.Since(start)
 	if ack != "#" {
 		log.Printf("Warning: expected '#' acknowledgment, got '%s'", ack)
 	}
@@ -378,6 +552,9 @@ func (s *Server) endGame(ctx context.Context) error {
 			log.Printf("Warning: failed to write result to dump: %v", err)
 		}
 	}
+	if err := s.DumpSystem.Alive(s.Board.Hot.IsAlive, s.Board.Cool.IsAlive); err != nil {
+		log.Printf("Warning: failed to write result to dump: %v", err)
+	}
 
 	s.publishSnapshot(KindGameOver, TurnStepFirst, PhaseGameOver, winnerName, reason)
 
@@ -391,14 +568,48 @@ func (s *Server) endGame(ctx context.Context) error {
 	return nil
 }
 
-// publishSnapshot はスナップショットを snapshotCh に non-blocking で送信する
-// snapshotCh が nil の場合は no-op
+// abortGame は制御APIによる中断を引き分けとして記録する
+func (s *Server) abortGame(cause error) error {
+	log.Printf("Game aborted: %v", cause)
+	reason := cause.Error()
+	if err := s.DumpSystem.Result(nil, nil, reason); err != nil {
+		log.Printf("Warning: failed to write result to dump: %v", err)
+	}
+	s.publishSnapshot(KindGameOver, TurnStepFirst, PhaseGameOver, "", reason)
+	return cause
+}
+
+// abortCause は ctx が Abort で中断されていればその理由を、そうでなければ err を返す
+func (s *Server) abortCause(ctx context.Context, err error) error {
+	if cause := context.Cause(ctx); errors.Is(cause, ErrAborted) {
+		return cause
+	}
+	return err
+}
+
+// publishSnapshot はスナップショットを保存して Recorder に渡し、snapshotCh に non-blocking で送信する
+// snapshotCh が nil の場合は送信しない
 func (s *Server) publishSnapshot(kind SnapshotKind, step TurnStep, phase SnapshotPublicPhase, winner, reason string) {
+	s.revision++
+	snap := SnapshotFromBoard(s.Board, kind, step, phase, s.revision, winner, reason)
+	snap.HotAction, snap.CoolAction = s.hotAction, s.coolAction
+	snap.HotView, snap.CoolView = s.hotView.Clone(), s.coolView.Clone()
+
+	s.mu.Lock()
+	s.latest = snap
+	if s.config.KeepHistory {
+		s.history = append(s.history, snap)
+	}
+	s.notifySubscribers(snap)
+	s.mu.Unlock()
+
+	if s.config.Recorder != nil {
+		s.config.Recorder.Record(snap)
+	}
+
 	if s.snapshotCh == nil {
 		return
 	}
-	s.revision++
-	snap := SnapshotFromBoard(s.Board, kind, step, phase, s.revision, winner, reason)
 	select {
 	case s.snapshotCh <- snap:
 	default:
@@ -0,0 +1,102 @@
+package server
+
+// 応答の値（0: 床, 2: ブロック, 3: アイテム）のほかに PlayerView.Cells で使う値
+const (
+	// ViewUnknown はまだ応答で知らされていないマス
+	ViewUnknown = -1
+	// ViewEnemy は相手がいると知らされたマス（応答の値 1）
+	ViewEnemy = 1
+)
+
+// PlayerView はプレイヤーが実際に受け取った応答（Ready・行動への応答）から知り得た盤面
+// 盤面外のマスは記録しない
+type PlayerView struct {
+	Width  int `json:"width"`
+	Height int `json:"height"`
+	// Cells はマスごとに最後に知らされた応答の値（未知なら ViewUnknown）
+	// Cells[y*Width+x]
+	Cells []int `json:"cells"`
+	// SeenTurn はマスごとに最後に知らされたターン（未知なら -1）
+	SeenTurn []int `json:"seenTurn"`
+}
+
+// NewPlayerView は何も知らない状態の PlayerView を作成する
+func NewPlayerView(width, height int) *PlayerView {
+	v := &PlayerView{
+		Width:    width,
+		Height:   height,
+		Cells:    make([]int, width*height),
+		SeenTurn: make([]int, width*height),
+	}
+	for i := range v.Cells {
+		v.Cells[i] = ViewUnknown
+		v.SeenTurn[i] = -1
+	}
+	return v
+}
+
+// Clone は v のコピーを返す（スナップショット用）
+func (v *PlayerView) Clone() *PlayerView {
+	if v == nil {
+		return nil
+	}
+	c := *v
+	c.Cells = append([]int(nil), v.Cells...)
+	c.SeenTurn = append([]int(nil), v.SeenTurn...)
+	return &c
+}
+
+// At は (x, y) について最後に知らされた値とそのターンを返す（盤面外や未知なら ViewUnknown, -1）
+func (v *PlayerView) At(x, y int) (value, turn int) {
+	if x < 0 || x >= v.Width || y < 0 || y >= v.Height {
+		return ViewUnknown, -1
+	}
+	i := y*v.Width + x
+	return v.Cells[i], v.SeenTurn[i]
+}
+
+func (v *PlayerView) set(pos Position, value, turn int) {
+	if pos.X < 0 || pos.X >= v.Width || pos.Y < 0 || pos.Y >= v.Height {
+		return
+	}
+	i := pos.Y*v.Width + pos.X
+	v.Cells[i] = value
+	v.SeenTurn[i] = turn
+}
+
+// RevealAround は pos を中心とする周辺9マスの応答（Ready・walk・put）を記録する
+func (v *PlayerView) RevealAround(pos Position, response [10]int, turn int) {
+	for i := 1; i <= 9; i++ {
+		p := Position{X: pos.X + (i-1)%3 - 1, Y: pos.Y + (i-1)/3 - 1}
+		v.set(p, response[i], turn)
+	}
+}
+
+// RevealAction は pos にいるプレイヤーが行った行動 action への応答を記録する
+func (v *PlayerView) RevealAction(action string, pos Position, dir Direction, response [10]int, turn int) {
+	switch action {
+	case "wk", "pt":
+		v.RevealAround(pos, response, turn)
+	case "lk":
+		// lk は2マス先の1マスだけを返す
+		v.set(movePosition(movePosition(pos, dir), dir), response[2], turn)
+	case "sc":
+		p := pos
+		for i := 1; i <= 9; i++ {
+			p = movePosition(p, dir)
+			v.set(p, response[i], turn)
+		}
+	}
+}
+
+// Observe は半ターンの記録 rec から、Ready と行動への応答で知らされた内容を記録する
+// from は行動前、to は行動後のプレイヤーの位置。
+// 行動を受信できなかった記録は Ready の応答が届いたか分からないため何も記録しない。
+// サーバーは送信の成否が分かるため RevealAround と RevealAction を直接使う。
+func (v *PlayerView) Observe(rec *ActionRecord, from, to Position) {
+	if rec.Action == "" {
+		return
+	}
+	v.RevealAround(from, rec.Ready, rec.Turn)
+	v.RevealAction(rec.Action, to, rec.Direction, rec.Response, rec.Turn)
+}
@@ -0,0 +1,62 @@
+package server
+
+import "testing"
+
+func TestPlayerViewReveal(t *testing.T) {
+	v := NewPlayerView(5, 4)
+	if c, turn := v.At(0, 0); c != ViewUnknown || turn != -1 {
+		t.Fatalf("At(0,0) = %d, %d, want unknown", c, turn)
+	}
+
+	// 左上の角では盤面外の5マスを記録しない
+	v.RevealAround(Position{X: 0, Y: 0}, [10]int{1, 2, 2, 2, 2, 0, 3, 2, 1, 0}, 1)
+	for _, tt := range []struct{ x, y, want int }{{0, 0, 0}, {1, 0, 3}, {0, 1, ViewEnemy}, {1, 1, 0}, {2, 2, ViewUnknown}} {
+		if c, _ := v.At(tt.x, tt.y); c != tt.want {
+			t.Errorf("At(%d,%d) = %d, want %d", tt.x, tt.y, c, tt.want)
+		}
+	}
+
+	// lk は2マス先だけ、sc は直線上を記録する
+	v.RevealAction("lk", Position{X: 1, Y: 1}, Right, [10]int{1, 0, 3}, 2)
+	if c, turn := v.At(3, 1); c != int(Item) || turn != 2 {
+		t.Errorf("look: At(3,1) = %d, %d, want item at turn 2", c, turn)
+	}
+	if c, _ := v.At(2, 1); c != ViewUnknown {
+		t.Errorf("look revealed the cell in between: %d", c)
+	}
+	v.RevealAction("sc", Position{X: 4, Y: 0}, Down, [10]int{1, 0, 2, 3, 2, 2, 2, 2, 2, 2}, 3)
+	for y, want := range []int{ViewUnknown, 0, 2, 3} {
+		if c, _ := v.At(4, y); c != want {
+			t.Errorf("search: At(4,%d) = %d, want %d", y, c, want)
+		}
+	}
+
+	// Clone は独立したコピー
+	c := v.Clone()
+	v.RevealAround(Position{X: 2, Y: 2}, [10]int{1, 2, 2, 2, 2, 2, 2, 2, 2, 2}, 4)
+	if cell, _ := c.At(2, 2); cell != ViewUnknown {
+		t.Errorf("clone changed with the original: %d", cell)
+	}
+}
+
+func TestPlayerViewObserve(t *testing.T) {
+	v := NewPlayerView(5, 4)
+	rec := &ActionRecord{Turn: 5, Action: "wk", Direction: Right,
+		Ready:    [10]int{1, 2, 2, 2, 0, 0, 0, 0, 0, 0},
+		Response: [10]int{1, 2, 2, 2, 0, 0, 3, 0, 0, 0}}
+	v.Observe(rec, Position{X: 1, Y: 1}, Position{X: 2, Y: 1})
+	// Ready は移動前、行動への応答は移動後の位置を中心とする
+	if c, turn := v.At(0, 0); c != int(Wall) || turn != 5 {
+		t.Errorf("At(0,0) = %d, %d, want wall at turn 5", c, turn)
+	}
+	if c, _ := v.At(3, 1); c != int(Item) {
+		t.Errorf("At(3,1) = %d, want item", c)
+	}
+
+	// 行動のない記録は何も記録しない
+	w := NewPlayerView(5, 4)
+	w.Observe(&ActionRecord{Ready: [10]int{1, 2, 2, 2, 2, 2, 2, 2, 2, 2}}, Position{X: 1, Y: 1}, Position{X: 1, Y: 1})
+	if c, _ := w.At(1, 1); c != ViewUnknown {
+		t.Errorf("Observe without action revealed %d", c)
+	}
+}
@@ -0,0 +1,128 @@
+package server
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"net/http"
+
+	"github.com/kqnade/CHaserGo/theme"
+)
+
+//go:embed viewer/index.html
+var viewerHTML []byte
+
+// ViewerHandler はブラウザ向けの観戦ページを返す
+//
+//	GET /               盤面を描画する HTML/JS
+//	GET /themes         利用可能なテーマ名
+//	GET /tiles/...      テーマのタイル画像（theme パッケージの組み込みテーマ）
+//	GET /snapshot       最新の BoardSnapshot（JSON）
+//	GET /events         BoardSnapshot の Server-Sent Events
+func (s *Server) ViewerHandler() http.Handler {
+	tiles, err := fs.Sub(theme.Images, "images")
+	if err != nil {
+		panic("viewer: " + err.Error()) // embed のパスが存在する限り発生しない
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write(viewerHTML)
+	})
+	mux.HandleFunc("GET /themes", func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(w, http.StatusOK, theme.BuiltinNames)
+	})
+	mux.Handle("GET /tiles/", http.StripPrefix("/tiles/", http.FileServerFS(tiles)))
+	mux.HandleFunc("GET /snapshot", func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(w, http.StatusOK, s.Snapshot())
+	})
+	mux.HandleFunc("GET /events", s.handleEvents)
+	return mux
+}
+
+// handleEvents はスナップショットを Server-Sent Events として配信する
+// 接続直後に最新のスナップショットを送り、以降は発行のたびに送る
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	ch, unsubscribe := s.subscribe()
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case snap, ok := <-ch:
+			if !ok {
+				return
+			}
+			data, err := json.Marshal(snap)
+			if err != nil {
+				return
+			}
+			if _, err := fmt.Fprintf(w, "id: %d\ndata: %s\n\n", snap.Revision, data); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+// subscribe はスナップショット購読用の channel を登録する
+// channel には常に最新の1件だけが残る（publishSnapshot と同じ上書きセマンティクス）
+func (s *Server) subscribe() (<-chan BoardSnapshot, func()) {
+	ch := make(chan BoardSnapshot, 1)
+
+	s.mu.Lock()
+	if s.subscribers == nil {
+		s.subscribers = make(map[chan BoardSnapshot]struct{})
+	}
+	s.subscribers[ch] = struct{}{}
+	ch <- s.latest
+	s.mu.Unlock()
+
+	return ch, func() {
+		s.mu.Lock()
+		if _, ok := s.subscribers[ch]; ok {
+			delete(s.subscribers, ch)
+			close(ch)
+		}
+		s.mu.Unlock()
+	}
+}
+
+// closeSubscribers は全購読者の channel を閉じ、SSE ハンドラを終了させる
+// 既に送信済みの最新スナップショットは閉じる前に配信される
+func (s *Server) closeSubscribers() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for ch := range s.subscribers {
+		delete(s.subscribers, ch)
+		close(ch)
+	}
+}
+
+// notifySubscribers は購読者にスナップショットを送る（s.mu を保持して呼ぶこと）
+func (s *Server) notifySubscribers(snap BoardSnapshot) {
+	for ch := range s.subscribers {
+		select {
+		case ch <- snap:
+		default:
+			select {
+			case <-ch:
+			default:
+			}
+			ch <- snap
+		}
+	}
+}
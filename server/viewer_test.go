@@ -0,0 +1,106 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestViewerIndexAndTiles(t *testing.T) {
+	s := newTestServer(t)
+	h := s.ViewerHandler()
+
+	tests := []struct {
+		path        string
+		contentType string
+	}{
+		{"/", "text/html"},
+		{"/themes", "application/json"},
+		{"/tiles/Light/Floor.png", "image/png"},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+		if rec.Code != http.StatusOK {
+			t.Errorf("GET %s: status code = %d, want 200", tt.path, rec.Code)
+			continue
+		}
+		if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, tt.contentType) {
+			t.Errorf("GET %s: Content-Type = %q, want %q", tt.path, ct, tt.contentType)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/tiles/Unknown/Floor.png", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("unknown theme: status code = %d, want 404", rec.Code)
+	}
+}
+
+func TestViewerEvents(t *testing.T) {
+	s := newTestServer(t)
+	ts := httptest.NewServer(s.ViewerHandler())
+	defer ts.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, ts.URL+"/events", nil)
+	if err != nil {
+		t.Fatalf("NewRequest: %v", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("GET /events: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("Content-Type = %q, want text/event-stream", ct)
+	}
+
+	type event struct {
+		Revision uint64 `json:"revision"`
+		Turn     int    `json:"turn"`
+	}
+	reader := bufio.NewReader(resp.Body)
+	readEvent := func() event {
+		t.Helper()
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				t.Fatalf("read event: %v", err)
+			}
+			if data, ok := strings.CutPrefix(line, "data: "); ok {
+				var ev event
+				if err := json.Unmarshal([]byte(data), &ev); err != nil {
+					t.Fatalf("unmarshal: %v", err)
+				}
+				return ev
+			}
+		}
+	}
+
+	// 接続直後に最新のスナップショットが届く
+	if first := readEvent(); first.Revision != 1 {
+		t.Errorf("first event revision = %d, want 1", first.Revision)
+	}
+
+	// 以降は発行のたびに届く
+	s.Board.Turn = 7
+	s.publishSnapshot(KindTurnEnd, TurnStepSecond, PhaseRunning, "", "")
+	if next := readEvent(); next.Revision != 2 || next.Turn != 7 {
+		t.Errorf("next event = rev %d turn %d, want rev 2 turn 7", next.Revision, next.Turn)
+	}
+
+	// closeSubscribers でストリームが終了する
+	s.closeSubscribers()
+	if _, err := io.ReadAll(reader); err != nil {
+		t.Errorf("stream did not end cleanly: %v", err)
+	}
+}
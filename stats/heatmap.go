@@ -0,0 +1,118 @@
+package stats
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/kqnade/CHaserGo/server"
+)
+
+// Layer はヒートマップの1層（先攻・後攻とプレイヤー名の組）
+// 同じボット同士の対戦でも Hot と Cool の訪問回数を分けて数える
+type Layer struct {
+	Hot  bool // true なら Hot（先攻）、false なら Cool（後攻）
+	Name string
+}
+
+// Heatmap は先攻・後攻とプレイヤー名の組ごとの、マスを訪れた回数
+// 同じマップの複数の対戦を Add で積み上げ、ボットがどこで時間を使っているかを調べるのに使う
+type Heatmap struct {
+	Width  int
+	Height int
+	// Games は Add した対戦の数
+	Games int
+	// Visits は層ごとの訪問回数（Visits[layer][y*Width+x]）
+	// 初期位置と、スナップショット間で位置が変わるたびの到着を1回と数える
+	Visits map[Layer][]int
+}
+
+// NewHeatmap は width×height の盤面の空の Heatmap を作成する
+func NewHeatmap(width, height int) *Heatmap {
+	return &Heatmap{Width: width, Height: height, Visits: make(map[Layer][]int)}
+}
+
+// Add は1対戦分のスナップショット列 snaps の訪問回数を加える
+// 盤面の大きさが違う対戦はエラーにする
+func (h *Heatmap) Add(snaps []server.BoardSnapshot) error {
+	if len(snaps) == 0 {
+		return nil
+	}
+	if s := &snaps[0]; s.Width != h.Width || s.Height != h.Height {
+		return fmt.Errorf("board is %dx%d, want %dx%d", s.Width, s.Height, h.Width, h.Height)
+	}
+	hot, cool := h.layer(Layer{Hot: true, Name: snaps[0].HotName}), h.layer(Layer{Name: snaps[0].CoolName})
+	for i := range snaps {
+		s := &snaps[i]
+		if i == 0 || s.HotX != snaps[i-1].HotX || s.HotY != snaps[i-1].HotY {
+			h.visit(hot, s.HotX, s.HotY)
+		}
+		if i == 0 || s.CoolX != snaps[i-1].CoolX || s.CoolY != snaps[i-1].CoolY {
+			h.visit(cool, s.CoolX, s.CoolY)
+		}
+	}
+	h.Games++
+	return nil
+}
+
+func (h *Heatmap) layer(p Layer) []int {
+	v, ok := h.Visits[p]
+	if !ok {
+		v = make([]int, h.Width*h.Height)
+		h.Visits[p] = v
+	}
+	return v
+}
+
+func (h *Heatmap) visit(v []int, x, y int) {
+	if x >= 0 && x < h.Width && y >= 0 && y < h.Height {
+		v[y*h.Width+x]++
+	}
+}
+
+// Layers は訪問回数のある層を名前順（同じ名前では Hot が先）に返す
+func (h *Heatmap) Layers() []Layer {
+	layers := make([]Layer, 0, len(h.Visits))
+	for p := range h.Visits {
+		layers = append(layers, p)
+	}
+	slices.SortFunc(layers, func(a, b Layer) int {
+		if c := strings.Compare(a.Name, b.Name); c != 0 {
+			return c
+		}
+		if a.Hot == b.Hot {
+			return 0
+		}
+		if a.Hot {
+			return -1
+		}
+		return 1
+	})
+	return layers
+}
+
+// Max は層 p の訪問回数の最大値を返す（記録がなければ 0）
+func (h *Heatmap) Max(p Layer) int {
+	m := 0
+	for _, n := range h.Visits[p] {
+		m = max(m, n)
+	}
+	return m
+}
+
+// Trail は snaps（Revision の昇順）の最後から数えて、プレイヤーが通った位置を最大 n 個返す
+// hot が true なら Hot、false なら Cool の位置で、古い順に並ぶ（同じ位置が続く場合は1つにまとめる）
+func Trail(snaps []server.BoardSnapshot, hot bool, n int) []server.Position {
+	var trail []server.Position
+	for i := len(snaps) - 1; i >= 0 && len(trail) < n; i-- {
+		p := server.Position{X: snaps[i].CoolX, Y: snaps[i].CoolY}
+		if hot {
+			p = server.Position{X: snaps[i].HotX, Y: snaps[i].HotY}
+		}
+		if len(trail) == 0 || trail[len(trail)-1] != p {
+			trail = append(trail, p)
+		}
+	}
+	slices.Reverse(trail)
+	return trail
+}
@@ -0,0 +1,60 @@
+package stats
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/kqnade/CHaserGo/server"
+)
+
+func TestHeatmap(t *testing.T) {
+	snaps := game(t)
+	h := NewHeatmap(5, 5)
+	if err := h.Add(snaps); err != nil {
+		t.Fatal(err)
+	}
+	// Hot は (1,1) から右へ2歩、Cool は (3,3) から動かない
+	hotLayer := Layer{Hot: true, Name: snaps[0].HotName}
+	hot := h.Visits[hotLayer]
+	if hot == nil || hot[1*5+1] != 1 || hot[1*5+2] != 1 || hot[1*5+3] != 1 {
+		t.Errorf("hot visits = %v", hot)
+	}
+
+	// 同じマップの対戦を積み上げる
+	if err := h.Add(snaps); err != nil {
+		t.Fatal(err)
+	}
+	if h.Games != 2 || h.Max(hotLayer) != 2 {
+		t.Errorf("Games = %d, Max = %d, want 2, 2", h.Games, h.Max(hotLayer))
+	}
+	if h.Max(Layer{Name: "nobody"}) != 0 {
+		t.Error("Max of an unknown player should be 0")
+	}
+
+	// Hot と Cool の名前が同じ（自己対戦）でも別々に数える
+	cool := Layer{Name: snaps[0].CoolName}
+	if layers := h.Layers(); !slices.Equal(layers, []Layer{hotLayer, cool}) {
+		t.Errorf("Layers = %v, want %v", layers, []Layer{hotLayer, cool})
+	}
+	if v := h.Visits[cool]; v[1*5+2] != 0 || v[3*5+3] != 2 {
+		t.Errorf("cool visits = %v, want only its start (3,3) twice", v)
+	}
+
+	if err := NewHeatmap(7, 5).Add(snaps); err == nil {
+		t.Error("Add with a different board size expected error")
+	}
+}
+
+func TestTrail(t *testing.T) {
+	snaps := game(t)
+	want := []server.Position{{X: 1, Y: 1}, {X: 2, Y: 1}, {X: 3, Y: 1}}
+	if got := Trail(snaps, true, 10); !slices.Equal(got, want) {
+		t.Errorf("Trail(hot) = %v, want %v", got, want)
+	}
+	if got := Trail(snaps, true, 2); !slices.Equal(got, want[1:]) {
+		t.Errorf("Trail(hot, 2) = %v, want %v", got, want[1:])
+	}
+	if got := Trail(snaps, false, 10); len(got) != 1 {
+		t.Errorf("Trail(cool) = %v, want one position", got)
+	}
+}
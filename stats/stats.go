@@ -0,0 +1,113 @@
+// Package stats はスナップショットの履歴から対戦の統計を集計する
+//
+// GUI の統計パネルのように、サーバーから受け取った BoardSnapshot の列
+// （ライブの履歴やダンプのリプレイ）だけを手がかりに集計する。行動の回数は
+// HotAction / CoolAction のターンが変わった回数、移動距離は隣り合うスナップショット間の
+// 位置の差から求める。そのため snaps は対戦の最初から取りこぼしのない列
+// （server.ServerConfig.Recorder や Server.History、dump.Game.Snapshots）を渡す。
+// Revision が 1 から始まっていないか途中で飛んでいる場合は Stats.Partial を立てる。
+package stats
+
+import "github.com/kqnade/CHaserGo/server"
+
+// Actions は集計する行動の種類（表示順）
+var Actions = []string{"wk", "lk", "sc", "pt"}
+
+// Point はあるターンの両プレイヤーのアイテム数
+type Point struct {
+	Turn int
+	Hot  int
+	Cool int
+}
+
+// Player は1プレイヤー分の統計
+type Player struct {
+	// Actions は行動の種類（"wk"/"lk"/"sc"/"pt"）ごとの回数
+	Actions map[string]int
+	// Distance は移動したマス数
+	Distance int
+	// BlocksPlaced は put で実際にブロックになったマス数
+	BlocksPlaced int
+}
+
+// Stats は対戦の統計
+type Stats struct {
+	MaxTurns int
+	// Partial は snaps の Revision が 1 から連続していないこと（履歴の先頭が捨てられた、
+	// 途中のスナップショットが欠けたなど）を表す。このとき行動の回数・移動距離・ブロック数は
+	// 実際より少ないことがある
+	Partial bool
+	// Items はターンごとのアイテム数（ターンの昇順。同じターンでは最後の値）
+	Items     []Point
+	Hot, Cool Player
+}
+
+// FromSnapshots は snaps（Revision の昇順）から統計を集計する
+func FromSnapshots(snaps []server.BoardSnapshot) Stats {
+	st := Stats{
+		Hot:  Player{Actions: make(map[string]int)},
+		Cool: Player{Actions: make(map[string]int)},
+	}
+	for i := range snaps {
+		s := &snaps[i]
+		st.MaxTurns = max(st.MaxTurns, s.MaxTurns)
+		p := Point{Turn: s.Turn, Hot: s.HotItems, Cool: s.CoolItems}
+		if n := len(st.Items); n > 0 && st.Items[n-1].Turn == s.Turn {
+			st.Items[n-1] = p
+		} else {
+			st.Items = append(st.Items, p)
+		}
+
+		if i == 0 {
+			st.Partial = s.Revision != 1
+			continue
+		}
+		prev := &snaps[i-1]
+		if s.Revision != prev.Revision+1 {
+			st.Partial = true
+		}
+		st.Hot.Distance += distance(prev.HotX, prev.HotY, s.HotX, s.HotY)
+		st.Cool.Distance += distance(prev.CoolX, prev.CoolY, s.CoolX, s.CoolY)
+		if newAction(prev.HotAction, s.HotAction) {
+			st.Hot.count(s.HotAction, prev, s)
+		}
+		if newAction(prev.CoolAction, s.CoolAction) {
+			st.Cool.count(s.CoolAction, prev, s)
+		}
+	}
+	return st
+}
+
+// newAction は直前のスナップショットの行動 prev から cur に変わったかを返す
+// 各プレイヤーは1ターンに1回だけ行動するため、ターンで見分ける
+// （JSON から読み直したスナップショットのようにポインタが変わっても同じ行動を二重に数えない）
+func newAction(prev, cur *server.PlayerAction) bool {
+	return cur != nil && (prev == nil || cur.Turn != prev.Turn)
+}
+
+// count は prev から s の間に現れた行動 a を数える
+func (p *Player) count(a *server.PlayerAction, prev, s *server.BoardSnapshot) {
+	p.Actions[a.Action]++
+	if a.Action != "pt" || len(a.Cells) == 0 {
+		return
+	}
+	c := a.Cells[0]
+	if c.X < 0 || c.X >= s.Width || c.Y < 0 || c.Y >= s.Height || len(prev.MapFlat) != len(s.MapFlat) {
+		return
+	}
+	i := c.Y*s.Width + c.X
+	if prev.MapFlat[i] != int(server.Wall) && s.MapFlat[i] == int(server.Wall) {
+		p.BlocksPlaced++
+	}
+}
+
+func distance(x0, y0, x1, y1 int) int {
+	return abs(x1-x0) + abs(y1-y0)
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
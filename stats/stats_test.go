@@ -0,0 +1,120 @@
+package stats
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kqnade/CHaserGo/chasermap"
+	"github.com/kqnade/CHaserGo/server"
+)
+
+// game は次の盤面で Hot が右へ2歩（2歩目でアイテムを取得）、Cool が上にブロックを置き、
+// 続けて同じ場所に put（失敗）と search を行った場合のスナップショット列を返す
+//
+//	#####
+//	#H.*#
+//	#...#
+//	#..C#
+//	#####
+func game(t *testing.T) []server.BoardSnapshot {
+	t.Helper()
+	m, err := chasermap.Parse(strings.NewReader("turns: 10\n#####\n#H.*#\n#...#\n#..C#\n#####"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := server.NewBoardFromMap(m)
+	var snaps []server.BoardSnapshot
+	var hotAction, coolAction *server.PlayerAction
+	snap := func() {
+		s := server.SnapshotFromBoard(b, server.KindActionEnd, server.TurnStepFirst, server.PhaseRunning, uint64(len(snaps)+1), "", "")
+		s.HotAction, s.CoolAction = hotAction, coolAction
+		snaps = append(snaps, s)
+	}
+	act := func(c *server.Character, action string, dir server.Direction) *server.PlayerAction {
+		from := c.Position
+		switch action {
+		case "wk":
+			if err := b.Walk(c, dir); err != nil {
+				t.Fatal(err)
+			}
+		case "pt":
+			b.Put(c.Position, dir)
+		}
+		return server.NewPlayerAction(&server.ActionRecord{Turn: b.Turn, Action: action, Direction: dir}, from)
+	}
+
+	snap()
+	hotAction = act(b.Hot, "wk", server.Right)
+	snap()
+	coolAction = act(b.Cool, "pt", server.Up)
+	snap()
+	b.IncrementTurn()
+	snap() // ターン終了（行動は変わらない）
+	hotAction = act(b.Hot, "wk", server.Right)
+	snap()
+	coolAction = act(b.Cool, "pt", server.Up)
+	snap()
+	b.IncrementTurn()
+	coolAction = act(b.Cool, "sc", server.Left)
+	snap()
+	return snaps
+}
+
+func TestFromSnapshots(t *testing.T) {
+	st := FromSnapshots(game(t))
+	if st.MaxTurns != 10 {
+		t.Errorf("MaxTurns = %d, want 10", st.MaxTurns)
+	}
+	want := []Point{{0, 0, 0}, {1, 1, 0}, {2, 1, 0}}
+	if len(st.Items) != len(want) {
+		t.Fatalf("Items = %v, want %v", st.Items, want)
+	}
+	for i := range want {
+		if st.Items[i] != want[i] {
+			t.Errorf("Items[%d] = %v, want %v", i, st.Items[i], want[i])
+		}
+	}
+
+	if st.Hot.Actions["wk"] != 2 || st.Hot.Distance != 2 || st.Hot.BlocksPlaced != 0 {
+		t.Errorf("Hot = %+v, want 2 walks over 2 cells", st.Hot)
+	}
+	if st.Cool.Actions["pt"] != 2 || st.Cool.Actions["sc"] != 1 || st.Cool.Distance != 0 {
+		t.Errorf("Cool = %+v, want 2 puts and 1 search without moving", st.Cool)
+	}
+	// 2回目の put は既にブロックのマスなので数えない
+	if st.Cool.BlocksPlaced != 1 {
+		t.Errorf("Cool.BlocksPlaced = %d, want 1", st.Cool.BlocksPlaced)
+	}
+}
+
+func TestFromSnapshotsPartial(t *testing.T) {
+	snaps := game(t)
+	if FromSnapshots(snaps).Partial {
+		t.Error("complete history is marked partial")
+	}
+
+	// 行動をコピーしても（JSON から読み直した場合など）同じ行動は1回と数える
+	copied := append([]server.BoardSnapshot(nil), snaps...)
+	for i := range copied {
+		copied[i] = copied[i].Clone()
+	}
+	if st := FromSnapshots(copied); st.Hot.Actions["wk"] != 2 || st.Cool.Actions["pt"] != 2 {
+		t.Errorf("copied actions counted as Hot %v, Cool %v", st.Hot.Actions, st.Cool.Actions)
+	}
+
+	// 途中が欠けた履歴・先頭が捨てられた履歴
+	gap := append(append([]server.BoardSnapshot(nil), snaps[:2]...), snaps[3:]...)
+	if !FromSnapshots(gap).Partial {
+		t.Error("history with a gap is not marked partial")
+	}
+	if !FromSnapshots(snaps[1:]).Partial {
+		t.Error("history without the first snapshot is not marked partial")
+	}
+}
+
+func TestFromSnapshotsEmpty(t *testing.T) {
+	st := FromSnapshots(nil)
+	if len(st.Items) != 0 || st.Hot.Actions == nil || st.Cool.Distance != 0 {
+		t.Errorf("FromSnapshots(nil) = %+v", st)
+	}
+}
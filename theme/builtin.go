@@ -0,0 +1,20 @@
+package theme
+
+import (
+	"embed"
+	"path"
+)
+
+// Images は組み込みテーマの images/<テーマ名>/<タイル名>.png を収めたファイルシステム
+// GUI・ブラウザビューア・画像の描画で共有する
+//
+//go:embed images
+var Images embed.FS
+
+// BuiltinNames は組み込みテーマ名（images/ 以下のディレクトリ名）
+var BuiltinNames = []string{"Light", "Heavy", "Jewel"}
+
+// Tile は組み込みテーマのタイル PNG を返す（name は "Floor" など拡張子なし）
+func Tile(theme, name string) ([]byte, error) {
+	return Images.ReadFile(path.Join("images", theme, name+".png"))
+}
@@ -0,0 +1,264 @@
+// Package theme はタイルテーマ（床・ブロック・アイテム・Hot・Cool の5枚の画像）を読み込む
+//
+// 組み込みテーマ（Light・Heavy・Jewel）に加えて、ディレクトリに置いた
+// 独自のテーマを実行時に読み込める。テーマのディレクトリには、タイルの画像ファイル名を
+// 書いたマニフェスト（theme.json）を置く。
+//
+//	{
+//	  "name": "School",
+//	  "floor": "floor.png",
+//	  "block": "wall.png",
+//	  "item": "star.png",
+//	  "hot": "red.png",
+//	  "cool": "blue.png"
+//	}
+//
+// name を省略するとディレクトリ名、タイルを省略すると "<タイル名>.png"（floor.png など）を使う。
+// Ebitengine には依存しないため、画像の検証はウィンドウのない環境でも行える。
+package theme
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ManifestName はテーマのディレクトリに置くマニフェストのファイル名
+const ManifestName = "theme.json"
+
+// DefaultDir は独自テーマを探す既定のディレクトリ（カレントディレクトリからの相対パス）
+// All と Named は、このディレクトリがなければ組み込みテーマだけを使う
+const DefaultDir = "themes"
+
+// タイル画像の一辺の大きさの範囲（ピクセル）
+const (
+	MinTileSize = 8
+	MaxTileSize = 1024
+)
+
+// Tiles はテーマのタイル名（マニフェストのキー）
+var Tiles = []string{"floor", "block", "item", "hot", "cool"}
+
+// Theme は1つのタイルテーマ
+type Theme struct {
+	Name string
+	// Dir はテーマのディレクトリ（組み込みテーマは ""）
+	Dir string
+
+	Floor, Block, Item, Hot, Cool image.Image
+}
+
+// Manifest は theme.json の内容
+type Manifest struct {
+	Name  string `json:"name"`
+	Floor string `json:"floor"`
+	Block string `json:"block"`
+	Item  string `json:"item"`
+	Hot   string `json:"hot"`
+	Cool  string `json:"cool"`
+}
+
+// tile は name のタイル画像を格納する先を返す
+func (t *Theme) tile(name string) *image.Image {
+	switch name {
+	case "floor":
+		return &t.Floor
+	case "block":
+		return &t.Block
+	case "item":
+		return &t.Item
+	case "hot":
+		return &t.Hot
+	default:
+		return &t.Cool
+	}
+}
+
+// file は name のタイルのファイル名を返す（省略時は "<name>.png"）
+func (m *Manifest) file(name string) string {
+	f := map[string]string{"floor": m.Floor, "block": m.Block, "item": m.Item, "hot": m.Hot, "cool": m.Cool}[name]
+	if f == "" {
+		return name + ".png"
+	}
+	return f
+}
+
+// Builtin は組み込みテーマを BuiltinNames の順に読み込む
+// 読み込めないテーマは飛ばし、その理由をまとめたエラーを返す
+func Builtin() ([]*Theme, error) {
+	var themes []*Theme
+	var errs []error
+	for _, name := range BuiltinNames {
+		t, err := builtin(name)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		themes = append(themes, t)
+	}
+	return themes, errors.Join(errs...)
+}
+
+func builtin(name string) (*Theme, error) {
+	t := &Theme{Name: name}
+	for _, tile := range Tiles {
+		// 組み込みテーマのファイル名は先頭が大文字（Floor.png など）
+		file := strings.ToUpper(tile[:1]) + tile[1:]
+		data, err := Tile(name, file)
+		if err != nil {
+			return nil, fmt.Errorf("theme %s: %w", name, err)
+		}
+		img, err := decode(data, name+"/"+file+".png", tile)
+		if err != nil {
+			return nil, fmt.Errorf("theme %s: %w", name, err)
+		}
+		*t.tile(tile) = img
+	}
+	return t, nil
+}
+
+// Load は dir のマニフェストに従ってテーマを読み込む
+func Load(dir string) (*Theme, error) {
+	data, err := os.ReadFile(filepath.Join(dir, ManifestName))
+	if err != nil {
+		return nil, fmt.Errorf("theme %s: %w", dir, err)
+	}
+	var m Manifest
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&m); err != nil {
+		return nil, fmt.Errorf("theme %s: invalid %s: %w", dir, ManifestName, err)
+	}
+
+	t := &Theme{Name: m.Name, Dir: dir}
+	if t.Name == "" {
+		t.Name = filepath.Base(dir)
+	}
+	for _, tile := range Tiles {
+		file := m.file(tile)
+		if !filepath.IsLocal(file) {
+			return nil, fmt.Errorf("theme %s: %s tile %q must be a relative path inside the theme directory", t.Name, tile, file)
+		}
+		path := filepath.Join(dir, file)
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("theme %s: %s tile: %w", t.Name, tile, err)
+		}
+		img, err := decode(data, path, tile)
+		if err != nil {
+			return nil, fmt.Errorf("theme %s: %w", t.Name, err)
+		}
+		*t.tile(tile) = img
+	}
+	return t, nil
+}
+
+// decode は tile のタイル画像 data（path から読んだもの）を検証してデコードする
+// 画像は正方形で、一辺が MinTileSize 以上 MaxTileSize 以下でなければならない
+func decode(data []byte, path, tile string) (image.Image, error) {
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%s tile %s: %w", tile, path, err)
+	}
+	if cfg.Width != cfg.Height {
+		return nil, fmt.Errorf("%s tile %s is %dx%d, want a square image", tile, path, cfg.Width, cfg.Height)
+	}
+	if cfg.Width < MinTileSize || cfg.Width > MaxTileSize {
+		return nil, fmt.Errorf("%s tile %s is %dx%d, want %d to %d pixels per side", tile, path, cfg.Width, cfg.Height, MinTileSize, MaxTileSize)
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%s tile %s: %w", tile, path, err)
+	}
+	return img, nil
+}
+
+// Discover は root 直下のマニフェストのあるディレクトリ（root 自身を含む）からテーマを名前順に読み込む
+// 読み込めないテーマは飛ばし、その理由をまとめたエラーを返す。root 自体を読めない場合は nil とエラーを返す
+func Discover(root string) ([]*Theme, error) {
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return nil, fmt.Errorf("theme directory: %w", err)
+	}
+	var dirs []string
+	if hasManifest(root) {
+		dirs = append(dirs, root)
+	}
+	for _, e := range entries {
+		if dir := filepath.Join(root, e.Name()); e.IsDir() && hasManifest(dir) {
+			dirs = append(dirs, dir)
+		}
+	}
+
+	var themes []*Theme
+	var errs []error
+	for _, dir := range dirs {
+		t, err := Load(dir)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		themes = append(themes, t)
+	}
+	return themes, errors.Join(errs...)
+}
+
+func hasManifest(dir string) bool {
+	info, err := os.Stat(filepath.Join(dir, ManifestName))
+	return err == nil && !info.IsDir()
+}
+
+// All は組み込みテーマに続けて root のテーマを返す（root が "" なら組み込みテーマだけ）
+// 読み込めないテーマや、名前（大文字小文字を区別しない）が先のテーマと重なるテーマは飛ばし、
+// その理由をまとめたエラーを返す
+func All(root string) ([]*Theme, error) {
+	themes, err := Builtin()
+	errs := []error{err}
+	if _, err := os.Stat(root); root == DefaultDir && errors.Is(err, fs.ErrNotExist) {
+		root = ""
+	}
+	if root != "" {
+		found, err := Discover(root)
+		errs = append(errs, err)
+		for _, t := range found {
+			if Find(themes, t.Name) != nil {
+				errs = append(errs, fmt.Errorf("theme %s (%s): name is already used", t.Name, t.Dir))
+				continue
+			}
+			themes = append(themes, t)
+		}
+	}
+	return themes, errors.Join(errs...)
+}
+
+// Named は組み込みテーマと root のテーマ（All と同じ）から、名前が name のテーマを返す
+// 見つからない場合は、使えるテーマ名と読み込めなかったテーマの理由を含むエラーを返す
+func Named(root, name string) (*Theme, error) {
+	themes, err := All(root)
+	if t := Find(themes, name); t != nil {
+		return t, nil
+	}
+	names := make([]string, len(themes))
+	for i, t := range themes {
+		names[i] = t.Name
+	}
+	return nil, errors.Join(fmt.Errorf("unknown theme %q (want one of %s)", name, strings.Join(names, ", ")), err)
+}
+
+// Find は themes から名前（大文字小文字を区別しない）が name のテーマを返す（なければ nil）
+func Find(themes []*Theme, name string) *Theme {
+	for _, t := range themes {
+		if strings.EqualFold(t.Name, name) {
+			return t
+		}
+	}
+	return nil
+}
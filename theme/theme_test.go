@@ -0,0 +1,201 @@
+package theme
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writePNG は dir/name に w×h の単色の PNG を書き出す
+func writePNG(t *testing.T, dir, name string, w, h int) {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for i := range img.Pix {
+		img.Pix[i] = 200
+	}
+	f, err := os.Create(filepath.Join(dir, name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if err := png.Encode(f, img); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// writeTheme は root/dir に manifest と5枚の 16×16 のタイル（floor.png など）を置く
+func writeTheme(t *testing.T, root, dir, manifest string) string {
+	t.Helper()
+	d := filepath.Join(root, dir)
+	if err := os.MkdirAll(d, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for _, tile := range Tiles {
+		writePNG(t, d, tile+".png", 16, 16)
+	}
+	if err := os.WriteFile(filepath.Join(d, ManifestName), []byte(manifest), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+func TestBuiltin(t *testing.T) {
+	themes, err := Builtin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(themes) != len(BuiltinNames) {
+		t.Fatalf("got %d themes, want %d", len(themes), len(BuiltinNames))
+	}
+	for i, th := range themes {
+		if th.Name != BuiltinNames[i] || th.Dir != "" {
+			t.Errorf("theme %d = %q (dir %q), want %q", i, th.Name, th.Dir, BuiltinNames[i])
+		}
+		for _, tile := range Tiles {
+			if *th.tile(tile) == nil {
+				t.Errorf("%s: %s tile is nil", th.Name, tile)
+			}
+		}
+	}
+}
+
+func TestLoad(t *testing.T) {
+	root := t.TempDir()
+	dir := writeTheme(t, root, "school", `{"name": "School", "block": "wall.png"}`)
+	writePNG(t, dir, "wall.png", 32, 32)
+
+	th, err := Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if th.Name != "School" || th.Dir != dir {
+		t.Errorf("got %q in %q, want School in %q", th.Name, th.Dir, dir)
+	}
+	if got := th.Block.Bounds().Dx(); got != 32 {
+		t.Errorf("block tile is %d pixels, want wall.png (32)", got)
+	}
+	if got := th.Floor.Bounds().Dx(); got != 16 {
+		t.Errorf("floor tile is %d pixels, want floor.png (16)", got)
+	}
+	if c := color.RGBAModel.Convert(th.Hot.At(0, 0)).(color.RGBA); c.R != 200 {
+		t.Errorf("hot tile pixel = %v", c)
+	}
+
+	// name を省略するとディレクトリ名
+	th, err = Load(writeTheme(t, root, "Plain", `{}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if th.Name != "Plain" {
+		t.Errorf("name = %q, want Plain", th.Name)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		manifest string
+		setup    func(dir string)
+		want     string
+	}{
+		{"no manifest", "", func(dir string) { os.Remove(filepath.Join(dir, ManifestName)) }, ManifestName},
+		{"bad json", `{"name": `, nil, "invalid theme.json"},
+		{"unknown key", `{"wall": "wall.png"}`, nil, `unknown field "wall"`},
+		{"missing tile", `{"item": "star.png"}`, nil, "item tile"},
+		{"outside dir", `{"hot": "../hot.png"}`, nil, "inside the theme directory"},
+		{"not square", `{}`, func(dir string) { writePNG(t, dir, "cool.png", 16, 12) }, "cool.png is 16x12, want a square image"},
+		{"too small", `{}`, func(dir string) { writePNG(t, dir, "floor.png", 4, 4) }, "is 4x4, want 8 to 1024 pixels per side"},
+		{"not an image", `{}`, func(dir string) { os.WriteFile(filepath.Join(dir, "block.png"), []byte("text"), 0o644) }, "unknown format"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writeTheme(t, t.TempDir(), "broken", tt.manifest)
+			if tt.setup != nil {
+				tt.setup(dir)
+			}
+			_, err := Load(dir)
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error %q does not contain %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiscover(t *testing.T) {
+	root := t.TempDir()
+	writeTheme(t, root, "b", `{"name": "Bravo"}`)
+	writeTheme(t, root, "a", `{"name": "Alpha"}`)
+	broken := writeTheme(t, root, "c", `{}`)
+	writePNG(t, broken, "hot.png", 10, 20)
+	if err := os.Mkdir(filepath.Join(root, "notes"), 0o755); err != nil { // マニフェストのないディレクトリは無視する
+		t.Fatal(err)
+	}
+
+	themes, err := Discover(root)
+	if err == nil || !strings.Contains(err.Error(), "is 10x20") {
+		t.Errorf("error = %v, want the broken theme's size", err)
+	}
+	var names []string
+	for _, th := range themes {
+		names = append(names, th.Name)
+	}
+	if got := strings.Join(names, ","); got != "Alpha,Bravo" {
+		t.Errorf("themes = %s, want Alpha,Bravo", got)
+	}
+
+	if _, err := Discover(filepath.Join(root, "missing")); err == nil {
+		t.Error("Discover of a missing directory expected error")
+	}
+}
+
+func TestAll(t *testing.T) {
+	root := t.TempDir()
+	writeTheme(t, root, "school", `{"name": "School"}`)
+	writeTheme(t, root, "light", `{"name": "light"}`) // 組み込みテーマと同じ名前
+
+	themes, err := All(root)
+	if err == nil || !strings.Contains(err.Error(), "name is already used") {
+		t.Errorf("error = %v, want duplicate name", err)
+	}
+	if len(themes) != len(BuiltinNames)+1 || themes[len(themes)-1].Name != "School" {
+		t.Fatalf("got %d themes, want the built-in themes and School", len(themes))
+	}
+	if th := Find(themes, "school"); th == nil || th.Name != "School" {
+		t.Errorf("Find(school) = %v", th)
+	}
+	if Find(themes, "Neon") != nil {
+		t.Error("Find(Neon) expected nil")
+	}
+
+	themes, err = All("")
+	if err != nil || len(themes) != len(BuiltinNames) {
+		t.Errorf("All(\"\") = %d themes, %v", len(themes), err)
+	}
+	if _, err := All(filepath.Join(root, "missing")); err == nil {
+		t.Error("All of a missing directory expected error")
+	}
+}
+
+func TestNamed(t *testing.T) {
+	root := t.TempDir()
+	writeTheme(t, root, "school", `{"name": "School"}`)
+	writeTheme(t, root, "broken", `{"floor": "none.png"}`)
+
+	if th, err := Named(root, "school"); err != nil || th.Name != "School" {
+		t.Errorf("Named(school) = %v, %v", th, err)
+	}
+	if th, err := Named(root, "jewel"); err != nil || th.Name != "Jewel" {
+		t.Errorf("Named(jewel) = %v, %v", th, err)
+	}
+	_, err := Named(root, "Neon")
+	if err == nil || !strings.Contains(err.Error(), "want one of Light, Heavy, Jewel, School") || !strings.Contains(err.Error(), "none.png") {
+		t.Errorf("Named(Neon) error = %v, want the available themes and the broken one", err)
+	}
+}